@@ -0,0 +1,366 @@
+// Command eval measures retrieval quality against a golden dataset, or
+// answer quality with an LLM judge, and writes a JSON report for comparing
+// runs and configurations.
+//
+// Usage:
+//
+//	go run ./cmd/eval -dataset golden.jsonl [-k 1,3,5,10] [-top-k 10] [-mmr] [-out report.json]
+//	go run ./cmd/eval -mode answer -dataset questions.jsonl [-judge-model gemini-2.5-pro]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	ragagent "github.com/mfmezger/agentic_rag_go/internal/agent"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/eval"
+	"github.com/mfmezger/agentic_rag_go/internal/logging"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/gemini"
+	"google.golang.org/genai"
+)
+
+// Evaluation modes.
+const (
+	modeRetrieval = "retrieval"
+	modeAnswer    = "answer"
+)
+
+// options holds the command line flags.
+type options struct {
+	mode       string
+	configPath string
+	dataset    string
+	out        string
+	tenant     string
+	cutoffs    []int
+	retrieve   ragagent.RetrieveOptions
+	judgeModel string
+}
+
+// report is the JSON document written by a run.
+type report struct {
+	Dataset   string                `json:"dataset"`
+	StartedAt time.Time             `json:"started_at"`
+	Config    runConfig             `json:"config"`
+	Retrieval *eval.RetrievalReport `json:"retrieval,omitempty"`
+	Answers   *eval.AnswerReport    `json:"answers,omitempty"`
+}
+
+// runConfig records the settings that shape a run, so reports from
+// different configurations can be told apart.
+type runConfig struct {
+	Collection     string `json:"collection"`
+	EmbeddingModel string `json:"embedding_model"`
+	Model          string `json:"model"`
+	TopK           int    `json:"top_k"`
+	ChunkSize      int    `json:"chunk_size"`
+	ChunkOverlap   int    `json:"chunk_overlap"`
+	ChunkStrategy  string `json:"chunk_strategy"`
+	// Rerank is the rerank provider, empty when reranking is off.
+	Rerank         string `json:"rerank,omitempty"`
+	Expand         bool   `json:"expand"`
+	HyDE           bool   `json:"hyde"`
+	MMR            bool   `json:"mmr"`
+	ContextWindow  bool   `json:"context_window"`
+	ParentDocument bool   `json:"parent_document"`
+	// JudgeModel, WebSearch and KnowledgeBaseTool are set in answer mode.
+	// The agent's tools are off so the judge sees all of its context.
+	JudgeModel        string `json:"judge_model,omitempty"`
+	WebSearch         string `json:"web_search,omitempty"`
+	KnowledgeBaseTool *bool  `json:"knowledge_base_tool,omitempty"`
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	envErr := godotenv.Load()
+
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		fatal("Failed to load config", err)
+	}
+	if err := logging.Setup(logging.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	}, os.Stderr); err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	if envErr != nil {
+		slog.Info("No .env file found, using environment variables")
+	}
+
+	cases, err := eval.LoadDataset(opts.dataset)
+	if err != nil {
+		fatal("Failed to load dataset", err)
+	}
+
+	qdrantClient, err := qdrant.New(ctx, qdrant.Config{
+		Host:       cfg.VectorStore.URL,
+		GRPCPort:   cfg.VectorStore.GRPCPort,
+		Collection: cfg.VectorStore.Collection,
+		VectorSize: cfg.VectorStore.VectorSize,
+	})
+	if err != nil {
+		fatal("Failed to create qdrant client", err)
+	}
+	defer qdrantClient.Close()
+
+	factory, err := ragagent.NewFactory(ctx, cfg, qdrantClient)
+	if err != nil {
+		fatal("Failed to create agent factory", err)
+	}
+
+	tenant := opts.tenant
+	if tenant == "" {
+		tenant = cfg.Server.DefaultTenant
+	}
+
+	rep := report{
+		Dataset:   opts.dataset,
+		StartedAt: time.Now().UTC(),
+		Config:    newRunConfig(cfg, opts),
+	}
+	switch opts.mode {
+	case modeAnswer:
+		rep.Answers, err = evaluateAnswers(ctx, cfg, factory, tenant, cases, opts, &rep.Config)
+	default:
+		rep.Retrieval, err = evaluateRetrieval(ctx, factory, tenant, cases, opts, &rep.Config)
+	}
+	if err != nil {
+		fatal("Evaluation failed", err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if opts.out != "" {
+		f, err := os.Create(opts.out)
+		if err != nil {
+			fatal("Failed to create report", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := writeReport(out, rep); err != nil {
+		fatal("Failed to write report", err)
+	}
+}
+
+// evaluateRetrieval scores retrieval rankings and logs the aggregates.
+func evaluateRetrieval(ctx context.Context, factory *ragagent.Factory, tenant string, cases []eval.Case, opts options, rc *runConfig) (*eval.RetrievalReport, error) {
+	slog.Info("Evaluating retrieval", "dataset", opts.dataset, "queries", len(cases), "tenant", tenant)
+	retrieval, err := eval.EvaluateRetrieval(ctx, factory, cases, eval.RetrievalOptions{
+		Tenant:   tenant,
+		Cutoffs:  opts.cutoffs,
+		Retrieve: opts.retrieve,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rc.TopK <= 0 && len(retrieval.Metrics.Cutoffs) > 0 {
+		rc.TopK = retrieval.Metrics.Cutoffs[len(retrieval.Metrics.Cutoffs)-1].K
+	}
+
+	for _, c := range retrieval.Metrics.Cutoffs {
+		slog.Info("Retrieval metrics",
+			"k", c.K,
+			"recall", c.Recall,
+			"precision", c.Precision,
+			"ndcg", c.NDCG,
+		)
+	}
+	slog.Info("Retrieval MRR", "mrr", retrieval.Metrics.MRR, "mean_latency_ms", retrieval.MeanLatencyMS)
+	return retrieval, nil
+}
+
+// evaluateAnswers replays the questions through chat, grades the answers
+// with the judge model and logs the aggregates.
+func evaluateAnswers(ctx context.Context, cfg *config.Config, factory *ragagent.Factory, tenant string, cases []eval.Case, opts options, rc *runConfig) (*eval.AnswerReport, error) {
+	judgeName := opts.judgeModel
+	if judgeName == "" {
+		judgeName = cfg.Eval.JudgeModel
+	}
+	judgeModel, err := newJudgeModel(ctx, cfg, factory, judgeName)
+	if err != nil {
+		return nil, err
+	}
+	rc.JudgeModel = judgeModel.Name()
+	noTools := false
+	rc.WebSearch = string(websearch.ModeDisabled)
+	rc.KnowledgeBaseTool = &noTools
+	if rc.TopK <= 0 {
+		rc.TopK = cfg.Retriever.TopK
+	}
+
+	slog.Info("Evaluating answers",
+		"dataset", opts.dataset,
+		"questions", len(cases),
+		"tenant", tenant,
+		"judge", rc.JudgeModel,
+	)
+	answers, err := eval.EvaluateAnswers(ctx, &eval.Chat{
+		Factory:  factory,
+		Tenant:   tenant,
+		Retrieve: opts.retrieve,
+	}, eval.NewJudge(judgeModel), cases)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Answer grades",
+		"judged", answers.Judged,
+		"questions", answers.Questions,
+		"faithfulness", answers.Grades.Faithfulness,
+		"answer_relevance", answers.Grades.AnswerRelevance,
+		"context_relevance", answers.Grades.ContextRelevance,
+		"mean_latency_ms", answers.MeanLatencyMS,
+	)
+	return answers, nil
+}
+
+// newJudgeModel returns the judge model: the chat model when name is empty
+// or the same, otherwise a separate Gemini model.
+func newJudgeModel(ctx context.Context, cfg *config.Config, factory *ragagent.Factory, name string) (model.LLM, error) {
+	if name == "" || name == cfg.Model.Name {
+		return factory.Model(), nil
+	}
+	apiKey := cfg.Model.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+	m, err := gemini.NewModel(ctx, name, &genai.ClientConfig{APIKey: apiKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create judge model: %w", err)
+	}
+	return m, nil
+}
+
+// parseFlags parses the command line.
+func parseFlags(args []string) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+
+	defaultConfig := os.Getenv("CONFIG_PATH")
+	if defaultConfig == "" {
+		defaultConfig = "configs/config.yaml"
+	}
+	fs.StringVar(&opts.mode, "mode", modeRetrieval, "evaluation mode: retrieval (ranking metrics) or answer (LLM-judged chat answers)")
+	fs.StringVar(&opts.configPath, "config", defaultConfig, "configuration file")
+	fs.StringVar(&opts.dataset, "dataset", "", "golden dataset in JSON Lines format (required)")
+	fs.StringVar(&opts.out, "out", "", "report file; stdout when empty")
+	fs.StringVar(&opts.tenant, "tenant", "", "tenant whose documents are searched; server.default_tenant when empty")
+	cutoffs := fs.String("k", "", "comma-separated ranks to report metrics at (default 1,3,5,10)")
+	fs.IntVar(&opts.retrieve.TopK, "top-k", 0, "documents retrieved per query; the largest k when 0")
+	fs.Var(optionalBool{&opts.retrieve.Rerank}, "rerank", "enable or disable reranking")
+	fs.Var(optionalBool{&opts.retrieve.Expand}, "expand", "enable or disable multi-query expansion")
+	fs.Var(optionalBool{&opts.retrieve.HyDE}, "hyde", "enable or disable hypothetical document embeddings")
+	fs.Var(optionalBool{&opts.retrieve.MMR}, "mmr", "enable or disable MMR re-selection")
+	fs.Var(optionalBool{&opts.retrieve.ContextWindow}, "context-window", "enable or disable neighbouring chunk expansion")
+	fs.StringVar(&opts.judgeModel, "judge-model", "", "answer mode: model grading answers; eval.judge_model, then model.name when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+	if opts.mode != modeRetrieval && opts.mode != modeAnswer {
+		return options{}, fmt.Errorf("invalid -mode %q", opts.mode)
+	}
+	if opts.dataset == "" {
+		return options{}, fmt.Errorf("-dataset is required")
+	}
+	if *cutoffs != "" {
+		for _, s := range strings.Split(*cutoffs, ",") {
+			k, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || k <= 0 {
+				return options{}, fmt.Errorf("invalid -k value %q", s)
+			}
+			opts.cutoffs = append(opts.cutoffs, k)
+		}
+	}
+	return opts, nil
+}
+
+// optionalBool is a boolean flag that stays nil unless set, so unset flags
+// keep the configured default.
+type optionalBool struct {
+	value **bool
+}
+
+func (b optionalBool) String() string {
+	if b.value == nil || *b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(**b.value)
+}
+
+func (b optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.value = &v
+	return nil
+}
+
+func (b optionalBool) IsBoolFlag() bool { return true }
+
+// newRunConfig resolves the retrieval settings of a run from the
+// configuration and the flag overrides.
+func newRunConfig(cfg *config.Config, o options) runConfig {
+	opts := o.retrieve
+	rc := runConfig{
+		Collection:     cfg.VectorStore.Collection,
+		EmbeddingModel: cfg.Model.EmbeddingModel,
+		Model:          cfg.Model.Name,
+		TopK:           opts.TopK,
+		ChunkSize:      cfg.Retriever.ChunkSize,
+		ChunkOverlap:   cfg.Retriever.ChunkOverlap,
+		ChunkStrategy:  cfg.Retriever.Chunking.Strategy,
+		Expand:         resolve(opts.Expand, cfg.Retriever.Expansion.Enabled),
+		HyDE:           resolve(opts.HyDE, cfg.Retriever.HyDE.Enabled),
+		MMR:            resolve(opts.MMR, cfg.Retriever.MMR.Enabled),
+		ContextWindow:  resolve(opts.ContextWindow, cfg.Retriever.ContextWindow.Enabled),
+		ParentDocument: cfg.Retriever.ParentDocument.Enabled,
+	}
+	if cfg.Retriever.Rerank.Enabled && resolve(opts.Rerank, true) {
+		rc.Rerank = cfg.Retriever.Rerank.Provider
+	}
+	return rc
+}
+
+// resolve returns the override if set, else the default.
+func resolve(override *bool, def bool) bool {
+	if override != nil {
+		return *override
+	}
+	return def
+}
+
+// writeReport writes r as indented JSON.
+func writeReport(w io.Writer, r report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
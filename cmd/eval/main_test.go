@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlags(t *testing.T) {
+	opts, err := parseFlags([]string{"-dataset", "golden.jsonl", "-k", "1, 5", "-top-k", "8", "-mmr", "-rerank=false"})
+	require.NoError(t, err)
+
+	assert.Equal(t, modeRetrieval, opts.mode)
+	assert.Equal(t, "golden.jsonl", opts.dataset)
+	assert.Equal(t, []int{1, 5}, opts.cutoffs)
+	assert.Equal(t, 8, opts.retrieve.TopK)
+	require.NotNil(t, opts.retrieve.MMR)
+	assert.True(t, *opts.retrieve.MMR)
+	require.NotNil(t, opts.retrieve.Rerank)
+	assert.False(t, *opts.retrieve.Rerank)
+	assert.Nil(t, opts.retrieve.HyDE, "unset flags keep the configured default")
+}
+
+func TestParseFlags_Invalid(t *testing.T) {
+	_, err := parseFlags(nil)
+	assert.Error(t, err)
+
+	_, err = parseFlags([]string{"-dataset", "golden.jsonl", "-k", "0"})
+	assert.Error(t, err)
+
+	_, err = parseFlags([]string{"-dataset", "golden.jsonl", "-mmr=maybe"})
+	assert.Error(t, err)
+
+	_, err = parseFlags([]string{"-dataset", "golden.jsonl", "-mode", "latency"})
+	assert.Error(t, err)
+}
+
+func TestParseFlags_AnswerMode(t *testing.T) {
+	opts, err := parseFlags([]string{"-mode", "answer", "-dataset", "questions.jsonl", "-judge-model", "gemini-2.5-pro"})
+	require.NoError(t, err)
+
+	assert.Equal(t, modeAnswer, opts.mode)
+	assert.Equal(t, "gemini-2.5-pro", opts.judgeModel)
+
+	// The agent's tools are always off in answer mode
+	_, err = parseFlags([]string{"-mode", "answer", "-dataset", "questions.jsonl", "-web-search", "always"})
+	assert.Error(t, err)
+}
+
+func TestNewRunConfig(t *testing.T) {
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	cfg.Retriever.Rerank.Enabled = true
+	disabled := false
+	opts, err := parseFlags([]string{"-dataset", "golden.jsonl", "-mmr"})
+	require.NoError(t, err)
+	opts.retrieve.Rerank = &disabled
+	opts.retrieve.TopK = 10
+
+	rc := newRunConfig(cfg, opts)
+	assert.Equal(t, 10, rc.TopK)
+	assert.True(t, rc.MMR)
+	assert.Empty(t, rc.Rerank)
+	assert.Equal(t, cfg.VectorStore.Collection, rc.Collection)
+}
@@ -2,13 +2,15 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/mfmezger/agentic_rag_go/internal/api"
 	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/logging"
 
 	_ "github.com/mfmezger/agentic_rag_go/docs" // Import generated swagger docs
 
@@ -26,9 +28,7 @@ func main() {
 	defer cancel()
 
 	// Load .env file (optional)
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
-	}
+	envErr := godotenv.Load()
 
 	// Load configuration
 	configPath := os.Getenv("CONFIG_PATH")
@@ -38,18 +38,33 @@ func main() {
 
 	cfg, err := config.Load(configPath)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", err)
+	}
+
+	// Configure structured logging
+	if err := logging.Setup(logging.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	}, os.Stderr); err != nil {
+		fatal("Invalid logging configuration", err)
 	}
 
-	log.Printf("Configuration loaded:")
-	log.Printf("  Server: %s:%d", cfg.Server.Host, cfg.Server.Port)
-	log.Printf("  VectorStore: %s:%d (collection: %s)", cfg.VectorStore.URL, cfg.VectorStore.GRPCPort, cfg.VectorStore.Collection)
-	log.Printf("  Chunking: size=%d, overlap=%d", cfg.Retriever.ChunkSize, cfg.Retriever.ChunkOverlap)
+	if envErr != nil {
+		slog.Info("No .env file found, using environment variables")
+	}
+
+	slog.Info("Configuration loaded",
+		"server", fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
+		"vectorstore", fmt.Sprintf("%s:%d", cfg.VectorStore.URL, cfg.VectorStore.GRPCPort),
+		"collection", cfg.VectorStore.Collection,
+		"chunk_size", cfg.Retriever.ChunkSize,
+		"chunk_overlap", cfg.Retriever.ChunkOverlap,
+	)
 
 	// Create API server
 	server, err := api.NewServer(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+		fatal("Failed to create server", err)
 	}
 	defer server.Close()
 
@@ -58,13 +73,19 @@ func main() {
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
-		log.Println("Shutting down...")
+		slog.Info("Shutting down...")
 		cancel()
 		os.Exit(0)
 	}()
 
 	// Start server
 	if err := server.Start(); err != nil {
-		log.Fatalf("Server error: %v", err)
+		fatal("Server error", err)
 	}
-}
\ No newline at end of file
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -15,9 +15,194 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/feedback": {
+            "get": {
+                "description": "Exports rated answers as JSON Lines, oldest feedback first: the query, retrieved chunk IDs and sources, the answer and the feedback. Retrieved sources record what was returned, not what was relevant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only this tenant; defaults to the caller's, operators export every tenant without it",
+                        "name": "tenant",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "up",
+                            "down"
+                        ],
+                        "type": "string",
+                        "description": "Only this rating",
+                        "name": "rating",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.FeedbackRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/keys": {
+            "get": {
+                "description": "Lists the API keys of the caller's tenant, or of every tenant for operators, including revoked and expired ones. Secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListKeysResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Mints a new scoped API key. The secret is only returned in this response. Admins mint keys for their own tenant with at most their own scopes; operators may mint keys for any tenant.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "description": "Key definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/keys/{id}": {
+            "delete": {
+                "description": "Revokes a minted API key of the caller's tenant, or of any tenant for operators. Keys defined in configuration cannot be revoked.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/chat": {
             "post": {
-                "description": "Send a message to the RAG agent which searches internal docs first, then web",
+                "description": "Send a message to the RAG agent which searches internal docs first, then web. With the answer cache enabled, the first question of a session may be answered from an earlier, similar question.",
                 "consumes": [
                     "application/json"
                 ],
@@ -52,6 +237,128 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/documents/preview": {
+            "post": {
+                "description": "Chunks text like an upload would and returns the chunks with their sizes and metadata, without embedding or storing them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Preview chunking",
+                "parameters": [
+                    {
+                        "description": "Text to chunk",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PreviewChunksRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PreviewChunksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feedback": {
+            "post": {
+                "description": "Records a rating, comment and optional corrected answer for a chat response, identified by its session and message ID. Submitting again replaces earlier feedback.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Rate a chat answer",
+                "parameters": [
+                    {
+                        "description": "Feedback",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FeedbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.FeedbackResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -86,7 +393,7 @@ const docTemplate = `{
         },
         "/search": {
             "post": {
-                "description": "Search for documents using hybrid vector search",
+                "description": "Search for documents using hybrid vector search, optionally reranked",
                 "consumes": [
                     "application/json"
                 ],
@@ -121,6 +428,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -167,6 +480,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -175,16 +494,97 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/usage": {
+            "get": {
+                "description": "Returns the caller's LLM and embedding token usage and remaining quota for the current day and month (UTC)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Token usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.UsageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
-        "api.ChatRequest": {
+        "api.APIKeyInfo": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2a9c1d5e7b8a60"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "support-frontend"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "static": {
+                    "type": "boolean"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "team-a"
+                }
+            }
+        },
+        "api.ChatRequest": {
+            "type": "object",
+            "properties": {
+                "context_window": {
+                    "description": "ContextWindow overrides whether each retrieved document is expanded\nwith its neighbouring chunks.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "expand": {
+                    "description": "Expand overrides whether the query is expanded into several\nsearches fused with reciprocal rank fusion.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string",
                     "example": "What is machine learning?"
                 },
+                "mmr": {
+                    "description": "MMR overrides whether results are re-selected by maximal marginal\nrelevance to reduce near-duplicates.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "rerank": {
+                    "description": "Rerank overrides whether the configured reranker runs.",
+                    "type": "boolean",
+                    "example": true
+                },
                 "session_id": {
                     "type": "string",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
@@ -192,17 +592,122 @@ const docTemplate = `{
                 "user_id": {
                     "type": "string",
                     "example": "user123"
+                },
+                "web_search": {
+                    "description": "WebSearch overrides the web search mode. Relaxing the configured\nmode requires an override role; tightening it is always allowed.",
+                    "type": "string",
+                    "enum": [
+                        "disabled",
+                        "fallback",
+                        "always"
+                    ],
+                    "example": "disabled"
                 }
             }
         },
         "api.ChatResponse": {
             "type": "object",
             "properties": {
+                "cached": {
+                    "description": "Cached reports whether the response was reused from an earlier,\nsimilar question.",
+                    "type": "boolean"
+                },
+                "citations": {
+                    "description": "Citations lists the retrieved chunks the response was given with.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Citation"
+                    }
+                },
+                "message_id": {
+                    "description": "MessageID identifies this response for feedback.",
+                    "type": "string",
+                    "example": "9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"
+                },
                 "response": {
                     "type": "string"
                 },
+                "retrieval_query": {
+                    "description": "RetrievalQuery is the standalone query used for retrieval when the\nmessage was rewritten using the conversation history.",
+                    "type": "string",
+                    "example": "What are the pricing tiers of Qdrant Cloud?"
+                },
                 "session_id": {
                     "type": "string"
+                },
+                "web_search": {
+                    "description": "WebSearch is the web search mode applied to this request.",
+                    "type": "string",
+                    "example": "fallback"
+                },
+                "web_search_used": {
+                    "description": "WebSearchUsed reports whether any part of the response is derived\nfrom web search.",
+                    "type": "boolean"
+                },
+                "web_sources": {
+                    "description": "WebSources lists the web pages the response drew on.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebSource"
+                    }
+                }
+            }
+        },
+        "api.Citation": {
+            "type": "object",
+            "properties": {
+                "chunk_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "document_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "upgrade.md"
+                }
+            }
+        },
+        "api.CreateKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "ExpiresIn is the key lifetime in seconds; zero means no expiry.",
+                    "type": "integer",
+                    "example": 2592000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "support-frontend"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "search",
+                        "chat"
+                    ]
+                },
+                "tenant": {
+                    "description": "Tenant defaults to the caller's tenant. Only operators may name\nanother tenant.",
+                    "type": "string",
+                    "example": "team-a"
+                }
+            }
+        },
+        "api.CreateKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/api.APIKeyInfo"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "rag_3f2a9c1d5e7b8a60_..."
                 }
             }
         },
@@ -215,13 +720,292 @@ const docTemplate = `{
                 }
             }
         },
+        "api.FeedbackRecord": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "key:3f2a9c1d5e7b8a60"
+                },
+                "answer": {
+                    "type": "string"
+                },
+                "chunk_ids": {
+                    "description": "ChunkIDs lists the retrieved chunks in rank order.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "corrected_answer": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "Do I need to restart after upgrading?"
+                },
+                "rating": {
+                    "type": "string",
+                    "example": "down"
+                },
+                "retrieval_query": {
+                    "type": "string"
+                },
+                "retrieved_sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "upgrade.md"
+                    ]
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "team-a"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "api.FeedbackRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "This describes the old version."
+                },
+                "corrected_answer": {
+                    "description": "CorrectedAnswer is the answer the user expected.",
+                    "type": "string",
+                    "example": "Restart the server after upgrading to 2.0."
+                },
+                "message_id": {
+                    "description": "MessageID is the message_id of the chat response.",
+                    "type": "string",
+                    "example": "9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"
+                },
+                "rating": {
+                    "type": "string",
+                    "enum": [
+                        "up",
+                        "down"
+                    ],
+                    "example": "down"
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "api.FeedbackResponse": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string",
+                    "example": "9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"
+                },
+                "rating": {
+                    "type": "string",
+                    "example": "down"
+                },
+                "submitted_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ListKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.APIKeyInfo"
+                    }
+                }
+            }
+        },
+        "api.PreviewChunk": {
+            "type": "object",
+            "properties": {
+                "characters": {
+                    "description": "Characters is the chunk length in characters.",
+                    "type": "integer",
+                    "example": 480
+                },
+                "embedded_text": {
+                    "description": "EmbeddedText is the text embedded for the chunk when enrichment\nprepends a context header.",
+                    "type": "string",
+                    "example": "Document: Upgrade Guide\n\nYour document text goes here..."
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Your document text goes here..."
+                },
+                "tokens": {
+                    "description": "Tokens is the estimated number of embedding model tokens.",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "api.PreviewChunksRequest": {
+            "type": "object",
+            "properties": {
+                "chunk_overlap": {
+                    "description": "ChunkOverlap overrides the configured chunk overlap, in the same\nunit as ChunkSize.",
+                    "type": "integer",
+                    "example": 32
+                },
+                "chunk_size": {
+                    "description": "ChunkSize overrides the configured chunk size, in estimated tokens\nfor the token_estimate strategy and in characters otherwise. With\nparent documents it sizes the child chunks.",
+                    "type": "integer",
+                    "example": 256
+                },
+                "chunk_strategy": {
+                    "description": "ChunkStrategy overrides the configured chunking strategy.",
+                    "type": "string",
+                    "enum": [
+                        "recursive",
+                        "markdown",
+                        "token_estimate",
+                        "sentence",
+                        "code",
+                        "semantic"
+                    ],
+                    "example": "markdown"
+                },
+                "enrichment": {
+                    "description": "Enrichment overrides the configured contextual enrichment mode.",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "template",
+                        "llm"
+                    ],
+                    "example": "template"
+                },
+                "language": {
+                    "description": "Language is the programming language for the code strategy.",
+                    "type": "string",
+                    "example": "go"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "author": "John Doe"
+                    }
+                },
+                "parent_document": {
+                    "description": "ParentDocument overrides whether small child chunks are indexed\nand linked to larger parent sections returned by retrieval.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "source": {
+                    "type": "string",
+                    "example": "document.pdf"
+                },
+                "summary": {
+                    "description": "Summary is a one-line document summary for chunk context headers.\nIn llm mode the model writes it when empty.",
+                    "type": "string",
+                    "example": "How to upgrade the server to version 2."
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Your document text goes here..."
+                },
+                "title": {
+                    "description": "Title is the document title used in chunk context headers; it\ndefaults to the \"title\" metadata, then the source.",
+                    "type": "string",
+                    "example": "Upgrade Guide"
+                }
+            }
+        },
+        "api.PreviewChunksResponse": {
+            "type": "object",
+            "properties": {
+                "chunk_count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "chunks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.PreviewChunk"
+                    }
+                },
+                "parent_count": {
+                    "description": "ParentCount is the number of parent sections when indexing parent\ndocuments.",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
         "api.SearchRequest": {
             "type": "object",
             "properties": {
+                "context_window": {
+                    "description": "ContextWindow overrides whether each result is expanded with its\nneighbouring chunks.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "expand": {
+                    "description": "Expand overrides whether the query is expanded into several\nsearches fused with reciprocal rank fusion.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "hyde": {
+                    "description": "HyDE overrides whether a hypothetical answer passage is embedded\nin place of the query.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "mmr": {
+                    "description": "MMR overrides whether results are re-selected by maximal marginal\nrelevance to reduce near-duplicates.",
+                    "type": "boolean",
+                    "example": true
+                },
                 "query": {
                     "type": "string",
                     "example": "What is machine learning?"
                 },
+                "rerank": {
+                    "description": "Rerank overrides whether the configured reranker runs.",
+                    "type": "boolean",
+                    "example": true
+                },
                 "top_k": {
                     "type": "integer",
                     "example": 5
@@ -231,6 +1015,17 @@ const docTemplate = `{
         "api.SearchResponse": {
             "type": "object",
             "properties": {
+                "queries": {
+                    "description": "Queries lists the generated alternative queries when expansion ran.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reranked": {
+                    "description": "Reranked reports whether scores come from the reranker rather than\nhybrid search fusion.",
+                    "type": "boolean"
+                },
                 "results": {
                     "type": "array",
                     "items": {
@@ -256,6 +1051,11 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "passage": {
+                    "description": "Passage is the result stitched together with its neighbouring\nchunks, set when context window expansion ran.",
+                    "type": "string",
+                    "example": "Deep learning... Machine learning is..."
+                },
                 "score": {
                     "type": "number",
                     "example": 0.95
@@ -265,6 +1065,34 @@ const docTemplate = `{
         "api.UploadTextRequest": {
             "type": "object",
             "properties": {
+                "chunk_strategy": {
+                    "description": "ChunkStrategy overrides the configured chunking strategy.",
+                    "type": "string",
+                    "enum": [
+                        "recursive",
+                        "markdown",
+                        "token_estimate",
+                        "sentence",
+                        "code",
+                        "semantic"
+                    ],
+                    "example": "markdown"
+                },
+                "enrichment": {
+                    "description": "Enrichment overrides the configured contextual enrichment mode.",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "template",
+                        "llm"
+                    ],
+                    "example": "template"
+                },
+                "language": {
+                    "description": "Language is the programming language for the code strategy.",
+                    "type": "string",
+                    "example": "go"
+                },
                 "metadata": {
                     "type": "object",
                     "additionalProperties": {
@@ -274,13 +1102,28 @@ const docTemplate = `{
                         "author": "John Doe"
                     }
                 },
+                "parent_document": {
+                    "description": "ParentDocument overrides whether small child chunks are indexed\nand linked to larger parent sections returned by retrieval.",
+                    "type": "boolean",
+                    "example": true
+                },
                 "source": {
                     "type": "string",
                     "example": "document.pdf"
                 },
+                "summary": {
+                    "description": "Summary is a one-line document summary for chunk context headers.\nIn llm mode the model writes it when empty.",
+                    "type": "string",
+                    "example": "How to upgrade the server to version 2."
+                },
                 "text": {
                     "type": "string",
                     "example": "Your document text goes here..."
+                },
+                "title": {
+                    "description": "Title is the document title used in chunk context headers; it\ndefaults to the \"title\" metadata, then the source.",
+                    "type": "string",
+                    "example": "Upgrade Guide"
                 }
             }
         },
@@ -297,9 +1140,87 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "document_id": {
+                    "description": "DocumentID is shared by all chunks of the upload.",
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
                 "message": {
                     "type": "string",
                     "example": "Text uploaded and chunked successfully"
+                },
+                "parent_count": {
+                    "description": "ParentCount is the number of parent sections, set when the upload\nwas indexed with parent documents.",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "api.UsagePeriod": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "embedding_tokens": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "limit": {
+                    "description": "Limit and Remaining are omitted when the period is unlimited.",
+                    "type": "integer",
+                    "example": 100000
+                },
+                "prompt_tokens": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 98420
+                },
+                "reset": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "total_tokens": {
+                    "type": "integer",
+                    "example": 1580
+                }
+            }
+        },
+        "api.UsageResponse": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "key:3f2a9c1d5e7b8a60"
+                },
+                "daily": {
+                    "$ref": "#/definitions/api.UsagePeriod"
+                },
+                "monthly": {
+                    "$ref": "#/definitions/api.UsagePeriod"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "team-a"
+                }
+            }
+        },
+        "api.WebSource": {
+            "type": "object",
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "example": "docs.python.org"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://docs.python.org/3/library/asyncio.html"
                 }
             }
         }
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mfmezger/agentic_rag_go/internal/llm"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// condenseInstruction asks for a standalone rewrite of a follow-up.
+const condenseInstruction = `Rewrite the user's latest message as a standalone search query for a document retrieval system. Resolve pronouns and references such as "it", "that" or "the second one" using the conversation. Keep the user's language and key terms. If the message is already standalone, return it unchanged. Reply with the query only.`
+
+// maxTurnRunes bounds how much of each prior turn goes into the prompt.
+const maxTurnRunes = 1000
+
+// Turn is one message of a prior conversation.
+type Turn struct {
+	// Role is genai.RoleUser or genai.RoleModel.
+	Role string
+	Text string
+}
+
+// HistoryFromEvents extracts the conversation turns from session events.
+// Partial, thought and tool-only events are skipped, and consecutive
+// events of the same role are merged into one turn.
+func HistoryFromEvents(events session.Events) []Turn {
+	var turns []Turn
+	for event := range events.All() {
+		if event.Partial || event.Content == nil {
+			continue
+		}
+
+		var text strings.Builder
+		for _, p := range event.Content.Parts {
+			if p.Text != "" && !p.Thought {
+				text.WriteString(p.Text)
+			}
+		}
+		if strings.TrimSpace(text.String()) == "" {
+			continue
+		}
+
+		role := genai.RoleModel
+		if event.Content.Role == genai.RoleUser {
+			role = genai.RoleUser
+		}
+		if n := len(turns); n > 0 && turns[n-1].Role == role {
+			turns[n-1].Text += "\n" + text.String()
+			continue
+		}
+		turns = append(turns, Turn{Role: role, Text: text.String()})
+	}
+	return turns
+}
+
+// condenseQuery rewrites message into a standalone query given the
+// preceding turns. Without history the message is returned unchanged.
+func condenseQuery(ctx context.Context, m model.LLM, history []Turn, message string) (string, error) {
+	if len(history) == 0 {
+		return message, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Conversation:\n")
+	for _, t := range history {
+		speaker := "Assistant"
+		if t.Role == genai.RoleUser {
+			speaker = "User"
+		}
+		fmt.Fprintf(&prompt, "%s: %s\n", speaker, llm.Truncate(strings.TrimSpace(t.Text), maxTurnRunes))
+	}
+	fmt.Fprintf(&prompt, "\nLatest message: %s\n\nStandalone query:", message)
+
+	return llm.Generate(ctx, m, llm.Request{
+		Instruction: condenseInstruction,
+		Prompt:      prompt.String(),
+		Temperature: llm.Float32(0),
+	})
+}
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// eventList adapts a slice to session.Events.
+type eventList []*session.Event
+
+func (e eventList) All() iter.Seq[*session.Event] { return slices.Values(e) }
+func (e eventList) Len() int                      { return len(e) }
+func (e eventList) At(i int) *session.Event       { return e[i] }
+
+func textEvent(role, text string) *session.Event {
+	return &session.Event{LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, genai.Role(role))}}
+}
+
+func TestHistoryFromEvents(t *testing.T) {
+	partial := textEvent(genai.RoleModel, "Qdr")
+	partial.Partial = true
+	thought := &session.Event{LLMResponse: model.LLMResponse{Content: &genai.Content{
+		Role:  genai.RoleModel,
+		Parts: []*genai.Part{{Text: "thinking...", Thought: true}},
+	}}}
+	toolCall := &session.Event{LLMResponse: model.LLMResponse{Content: &genai.Content{
+		Role:  genai.RoleModel,
+		Parts: []*genai.Part{genai.NewPartFromFunctionCall("search", nil)},
+	}}}
+
+	turns := HistoryFromEvents(eventList{
+		textEvent(genai.RoleUser, "Which vector databases do you support?"),
+		partial,
+		thought,
+		toolCall,
+		textEvent(genai.RoleModel, "Qdrant and"),
+		textEvent(genai.RoleModel, "pgvector."),
+		{},
+		textEvent(genai.RoleUser, "What about the second one?"),
+	})
+
+	assert.Equal(t, []Turn{
+		{Role: genai.RoleUser, Text: "Which vector databases do you support?"},
+		{Role: genai.RoleModel, Text: "Qdrant and\npgvector."},
+		{Role: genai.RoleUser, Text: "What about the second one?"},
+	}, turns)
+}
+
+func TestCondenseQuery(t *testing.T) {
+	var prompt string
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		prompt = mocks.PromptText(req)
+		return "Does pgvector support hybrid search?", nil
+	})
+
+	history := []Turn{
+		{Role: genai.RoleUser, Text: "Which vector databases do you support?"},
+		{Role: genai.RoleModel, Text: "Qdrant and pgvector. " + strings.Repeat("x", 2*maxTurnRunes)},
+	}
+	query, err := condenseQuery(context.Background(), m, history, "Does the second one do hybrid search?")
+	require.NoError(t, err)
+	assert.Equal(t, "Does pgvector support hybrid search?", query)
+
+	assert.Contains(t, prompt, "User: Which vector databases do you support?")
+	assert.Contains(t, prompt, "Assistant: Qdrant and pgvector.")
+	assert.Contains(t, prompt, "Latest message: Does the second one do hybrid search?")
+	assert.Less(t, len(prompt), 2*maxTurnRunes)
+}
+
+func TestCondenseQuery_NoHistory(t *testing.T) {
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		t.Fatal("model should not be called without history")
+		return "", nil
+	})
+
+	query, err := condenseQuery(context.Background(), m, nil, "What is RAG?")
+	require.NoError(t, err)
+	assert.Equal(t, "What is RAG?", query)
+}
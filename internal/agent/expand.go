@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mfmezger/agentic_rag_go/internal/llm"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+
+	"google.golang.org/adk/model"
+)
+
+// expansionInstruction asks for alternative search queries as JSON.
+const expansionInstruction = `You rewrite search queries for a document retrieval system. Given a user query, write alternative queries that together cover what the user is looking for: paraphrases using different vocabulary, and sub-questions for each part of a compound question. Keep each query self-contained and short. Respond with JSON: {"queries": ["..."]}.`
+
+// expandQuery asks m for up to n alternative formulations of query.
+// The original query is not included in the result.
+func expandQuery(ctx context.Context, m model.LLM, query string, n int) ([]string, error) {
+	if n <= 0 {
+		n = 3
+	}
+
+	text, err := llm.Generate(ctx, m, llm.Request{
+		Instruction: expansionInstruction,
+		Prompt:      fmt.Sprintf("Write %d alternative queries for:\n%s", n, query),
+		Temperature: llm.Float32(0.7),
+		JSON:        true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse expanded queries: %w", err)
+	}
+
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	queries := make([]string, 0, n)
+	for _, q := range out.Queries {
+		q = strings.TrimSpace(q)
+		key := strings.ToLower(q)
+		if q == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		queries = append(queries, q)
+		if len(queries) == n {
+			break
+		}
+	}
+	return queries, nil
+}
+
+// fuseResults merges ranked result lists with reciprocal rank fusion:
+// each document scores the sum of 1/(k+rank) over the lists it appears in.
+// Scores are replaced by the fused score.
+func fuseResults(lists [][]qdrant.SearchResult, k int) []qdrant.SearchResult {
+	if k <= 0 {
+		k = 60
+	}
+
+	var fused []qdrant.SearchResult
+	scores := make(map[string]float64)
+	index := make(map[string]int)
+	for _, list := range lists {
+		for rank, res := range list {
+			if _, ok := index[res.ID]; !ok {
+				index[res.ID] = len(fused)
+				fused = append(fused, res)
+			}
+			scores[res.ID] += 1 / float64(k+rank+1)
+		}
+	}
+
+	// Stable so ties keep first-seen order, which favours the original query
+	sort.SliceStable(fused, func(a, b int) bool {
+		return scores[fused[a].ID] > scores[fused[b].ID]
+	})
+	for i := range fused {
+		fused[i].Score = float32(scores[fused[i].ID])
+	}
+	return fused
+}
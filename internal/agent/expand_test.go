@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestExpandQuery(t *testing.T) {
+	var cfg *model.LLMRequest
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		cfg = req
+		return `{"queries": ["How do I reset my password?", " password reset steps ", "", "Reset password", "account recovery", "forgot login"]}`, nil
+	})
+
+	queries, err := expandQuery(context.Background(), m, "reset password", 3)
+	require.NoError(t, err)
+	// Blanks, duplicates and the original query are dropped; the rest capped at n
+	assert.Equal(t, []string{"How do I reset my password?", "password reset steps", "account recovery"}, queries)
+	assert.Contains(t, mocks.PromptText(cfg), "reset password")
+	assert.Equal(t, "application/json", cfg.Config.ResponseMIMEType)
+}
+
+func TestExpandQuery_InvalidJSON(t *testing.T) {
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		return "here are some queries", nil
+	})
+
+	_, err := expandQuery(context.Background(), m, "query", 3)
+	assert.Error(t, err)
+}
+
+func TestFuseResults(t *testing.T) {
+	lists := [][]qdrant.SearchResult{
+		{{ID: "a", Content: "alpha"}, {ID: "b"}, {ID: "c"}},
+		{{ID: "c"}, {ID: "b"}, {ID: "d"}},
+		{{ID: "b"}, {ID: "e"}},
+	}
+
+	fused := fuseResults(lists, 60)
+	ids := make([]string, len(fused))
+	for i, r := range fused {
+		ids[i] = r.ID
+	}
+
+	// b appears in every list, c in two
+	assert.Equal(t, []string{"b", "c", "a", "e", "d"}, ids)
+	assert.InDelta(t, 1.0/62+1.0/62+1.0/61, fused[0].Score, 1e-6)
+	assert.Equal(t, "alpha", fused[2].Content)
+}
+
+func TestFuseResults_SingleList(t *testing.T) {
+	fused := fuseResults([][]qdrant.SearchResult{{{ID: "a"}, {ID: "b"}}}, 0)
+	require.Len(t, fused, 2)
+	assert.Equal(t, "a", fused[0].ID)
+	assert.Greater(t, fused[0].Score, fused[1].Score)
+}
+
+func TestEnabled(t *testing.T) {
+	yes, no := true, false
+	assert.True(t, enabled(nil, true))
+	assert.False(t, enabled(nil, false))
+	assert.True(t, enabled(&yes, false))
+	assert.False(t, enabled(&no, true))
+}
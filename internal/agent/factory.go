@@ -9,7 +9,9 @@ import (
 
 	"github.com/mfmezger/agentic_rag_go/internal/config"
 	"github.com/mfmezger/agentic_rag_go/internal/embedding"
+	"github.com/mfmezger/agentic_rag_go/internal/rerank"
 	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
 
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
@@ -17,7 +19,6 @@ import (
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
-	"google.golang.org/adk/tool/geminitool"
 	"google.golang.org/genai"
 )
 
@@ -27,6 +28,9 @@ type Factory struct {
 	qdrant         *qdrant.Client
 	embedding      *embedding.Service
 	model          model.LLM
+	reranker       rerank.Reranker
+	webPolicy      *websearch.Policy
+	webGatherer    *websearch.Gatherer
 	sessionService session.Service
 }
 
@@ -55,83 +59,134 @@ func NewFactory(ctx context.Context, cfg *config.Config, qdrantClient *qdrant.Cl
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	// Initialize optional reranker
+	reranker, err := newReranker(cfg.Retriever.Rerank, llmModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reranker: %w", err)
+	}
+
+	// Validate web search policy
+	webPolicy, err := websearch.NewPolicy(websearch.Config{
+		Mode:           cfg.WebSearch.Mode,
+		MinScore:       cfg.WebSearch.MinScore,
+		AllowedDomains: cfg.WebSearch.AllowedDomains,
+		BlockedDomains: cfg.WebSearch.BlockedDomains,
+		TenantModes:    cfg.WebSearch.TenantModes,
+		OverrideRoles:  cfg.WebSearch.OverrideRoles,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid web search policy: %w", err)
+	}
+
+	// Initialize web search provider (nil for Gemini's native search)
+	webGatherer, err := newWebGatherer(cfg.WebSearch, webPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web search provider: %w", err)
+	}
+
 	return &Factory{
 		cfg:            cfg,
 		qdrant:         qdrantClient,
 		embedding:      embeddingService,
 		model:          llmModel,
+		reranker:       reranker,
+		webPolicy:      webPolicy,
+		webGatherer:    webGatherer,
 		sessionService: session.InMemoryService(),
 	}, nil
 }
 
-// RetrievedContext holds the pre-fetched documents for a query.
-type RetrievedContext struct {
-	Documents []qdrant.SearchResult
-	Query     string
+// RunnerOptions configures a runner for one chat request.
+type RunnerOptions struct {
+	// Tenant scopes the agent's own knowledge base searches.
+	Tenant string
+	// WebSearch is the web search mode resolved for the request.
+	WebSearch websearch.Mode
+	// KnowledgeBase enables or disables the knowledge base sub-agent for
+	// this runner; nil uses agent.tools.knowledge_base.
+	KnowledgeBase *bool
 }
 
-// Retrieve performs upfront document retrieval for a query.
-// This should be called before NewRunner to pre-fetch relevant context.
-func (f *Factory) Retrieve(ctx context.Context, query string) (*RetrievedContext, error) {
-	topK := f.cfg.Retriever.TopK
-	if topK <= 0 {
-		topK = 10
+// strategy tells the agent how to use the tools it was given.
+func strategy(knowledgeBase, web bool, webTool, domains string) string {
+	steps := []string{"First, analyze the retrieved documents above to answer the user's question."}
+	if knowledgeBase {
+		steps = append(steps, "If they are insufficient, or the question has several parts or needs facts that depend on each other, call "+knowledgeBaseAgentName+" with focused requests. You may call it several times, using what you learned to ask the next question.")
+	} else {
+		steps = append(steps, "If the retrieved documents contain sufficient information, use them to formulate your answer.")
 	}
-
-	// Generate query embedding using Gemini
-	queryVector, err := f.embedding.EmbedQuery(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("embedding query failed: %w", err)
+	if web {
+		steps = append(steps,
+			"If the internal documents are insufficient or the topic requires current/real-time information, use "+webTool+".",
+			"Clearly mark every part of your answer that comes from web search, e.g. with \"(web)\", and always indicate whether your answer comes from internal documents or web search.",
+		)
+	} else {
+		steps = append(steps, "Web search is not available. If the internal documents are insufficient, say so clearly instead of guessing.")
 	}
+	steps = append(steps, "Cite sources when possible.")
 
-	results, err := f.qdrant.HybridSearch(ctx, f.cfg.VectorStore.Collection, queryVector, nil, uint64(topK))
-	if err != nil {
-		return nil, fmt.Errorf("retrieval failed: %w", err)
+	var b strings.Builder
+	b.WriteString("STRATEGY:\n")
+	for i, step := range steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
 	}
-
-	return &RetrievedContext{
-		Documents: results,
-		Query:     query,
-	}, nil
+	if web && domains != "" {
+		b.WriteString("\n" + domains)
+	}
+	return b.String()
 }
 
 // NewRunner creates a new runner for the RAG agent.
-// The retrieved context is injected into the agent's instruction.
-// The agent only has GoogleSearch for web fallback (no function tool mixing).
-func (f *Factory) NewRunner(ctx context.Context, appName string, retrieved *RetrievedContext) (*runner.Runner, error) {
+// The retrieved context is injected into the agent's instruction. With the
+// knowledge base tool enabled, the agent can also search the tenant's
+// documents and the web through sub-agents; otherwise it only has the web
+// search tool. Web search is only offered when the policy allows it.
+func (f *Factory) NewRunner(ctx context.Context, appName string, retrieved *RetrievedContext, opts RunnerOptions) (*runner.Runner, error) {
 	// Build context from retrieved documents
 	var contextBuilder strings.Builder
 	if retrieved != nil && len(retrieved.Documents) > 0 {
 		contextBuilder.WriteString("\n\n## Retrieved Knowledge Base Documents\n\n")
 		for i, doc := range retrieved.Documents {
 			contextBuilder.WriteString(fmt.Sprintf("### Document %d (Score: %.2f)\n", i+1, doc.Score))
-			contextBuilder.WriteString(doc.Content)
+			contextBuilder.WriteString(documentText(doc))
 			contextBuilder.WriteString("\n\n")
 		}
 	}
 
-	// Build instruction with injected context
-	instruction := fmt.Sprintf(`%s
+	best, found := bestScore(retrieved)
+	web := f.webPolicy.Offer(opts.WebSearch, best, found)
 
-%s
+	var tools []tool.Tool
+	var webTool string
+	knowledgeBase := enabled(opts.KnowledgeBase, f.cfg.Agent.Tools.KnowledgeBase)
+	if knowledgeBase {
+		var err error
+		if tools, err = f.agentTools(opts.Tenant, web); err != nil {
+			return nil, err
+		}
+		webTool = webSearchAgentName
+	} else if web {
+		t, name, err := f.webSearchTool()
+		if err != nil {
+			return nil, err
+		}
+		tools, webTool = []tool.Tool{t}, name
+	}
 
-STRATEGY:
-1. First, analyze the retrieved documents above to answer the user's question.
-2. If the retrieved documents contain sufficient information, use them to formulate your answer.
-3. If the retrieved documents are insufficient or the topic requires current/real-time information, use google_search.
-4. Always indicate whether your answer comes from internal documents or web search.
-5. Cite sources when possible.
-`, f.cfg.Agent.Instruction, contextBuilder.String())
+	// Build instruction with injected context
+	instruction := fmt.Sprintf("%s\n\n%s\n\n%s", f.cfg.Agent.Instruction, contextBuilder.String(),
+		strategy(knowledgeBase, web, webTool, f.webPolicy.Instruction()))
 
-	// Create agent with only GoogleSearch (native Gemini tool)
-	// This avoids the function tool + native tool mixing issue
+	// Native tools and function tools are never mixed in one agent:
+	// retrieval tools are wrapped in sub-agents
 	ragAgent, err := llmagent.New(llmagent.Config{
 		Name:        f.cfg.Agent.Name,
 		Model:       f.model,
 		Description: f.cfg.Agent.Description,
 		Instruction: instruction,
-		Tools: []tool.Tool{
-			geminitool.GoogleSearch{},
+		Tools:       tools,
+		AfterModelCallbacks: []llmagent.AfterModelCallback{
+			f.recordWebSources,
 		},
 	})
 	if err != nil {
@@ -156,6 +211,16 @@ func (f *Factory) EmbeddingService() *embedding.Service {
 	return f.embedding
 }
 
+// Model returns the language model used by the agents.
+func (f *Factory) Model() model.LLM {
+	return f.model
+}
+
+// WebSearchPolicy returns the web search policy.
+func (f *Factory) WebSearchPolicy() *websearch.Policy {
+	return f.webPolicy
+}
+
 // SessionService returns the session service.
 func (f *Factory) SessionService() session.Service {
 	return f.sessionService
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/mfmezger/agentic_rag_go/internal/llm"
+
+	"google.golang.org/adk/model"
+)
+
+// hydeInstruction asks for a passage that reads like an indexed document.
+const hydeInstruction = `Write a short passage (at most 150 words) that directly answers the question, in the style of a reference document. State facts plainly; do not mention the question or that the passage is hypothetical. If unsure, write the most plausible answer.`
+
+// generateHypothetical asks m for a passage that would answer query.
+// The passage may be wrong; it only needs to resemble relevant documents.
+func generateHypothetical(ctx context.Context, m model.LLM, query string) (string, error) {
+	return llm.Generate(ctx, m, llm.Request{
+		Instruction: hydeInstruction,
+		Prompt:      query,
+		Temperature: llm.Float32(0.3),
+	})
+}
+
+// embedHyDE embeds a hypothetical answer to query with the document task
+// type, like the chunks it is compared against, optionally averaged with
+// the raw query vector. It falls back to the raw query vector when the
+// passage can't be generated.
+func (f *Factory) embedHyDE(ctx context.Context, query string) ([]float32, error) {
+	passage, err := generateHypothetical(ctx, f.model, query)
+	if err != nil {
+		slog.WarnContext(ctx, "HyDE generation failed, using query embedding", "error", err)
+		return f.embedding.EmbedQuery(ctx, query)
+	}
+
+	vectors, err := f.embedding.EmbedDocuments(ctx, []string{passage})
+	if err != nil {
+		return nil, err
+	}
+	if !f.cfg.Retriever.HyDE.IncludeQuery {
+		return vectors[0], nil
+	}
+
+	queryVector, err := f.embedding.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return combineVectors(vectors[0], queryVector)
+}
+
+// combineVectors averages unit-normalised vectors so each contributes
+// equally under cosine similarity regardless of magnitude.
+func combineVectors(vectors ...[]float32) ([]float32, error) {
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("no vectors to combine")
+	}
+
+	out := make([]float32, len(vectors[0]))
+	for _, v := range vectors {
+		if len(v) != len(out) {
+			return nil, fmt.Errorf("vector dimension mismatch: %d vs %d", len(v), len(out))
+		}
+		var norm float64
+		for _, x := range v {
+			norm += float64(x) * float64(x)
+		}
+		if norm == 0 {
+			continue
+		}
+		scale := 1 / math.Sqrt(norm) / float64(len(vectors))
+		for i, x := range v {
+			out[i] += float32(float64(x) * scale)
+		}
+	}
+	return out, nil
+}
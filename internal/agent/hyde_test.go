@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestGenerateHypothetical(t *testing.T) {
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		assert.Equal(t, "What port does Qdrant use for gRPC?", mocks.PromptText(req))
+		return "  Qdrant serves gRPC on port 6334.\n", nil
+	})
+
+	passage, err := generateHypothetical(context.Background(), m, "What port does Qdrant use for gRPC?")
+	require.NoError(t, err)
+	assert.Equal(t, "Qdrant serves gRPC on port 6334.", passage)
+}
+
+func TestCombineVectors(t *testing.T) {
+	// Magnitude doesn't matter, only direction
+	combined, err := combineVectors([]float32{10, 0}, []float32{0, 0.5})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, combined[0], 1e-6)
+	assert.InDelta(t, 0.5, combined[1], 1e-6)
+
+	// Zero vectors contribute nothing
+	combined, err = combineVectors([]float32{3, 4}, []float32{0, 0})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3, combined[0], 1e-6)
+	assert.InDelta(t, 0.4, combined[1], 1e-6)
+}
+
+func TestCombineVectors_Errors(t *testing.T) {
+	_, err := combineVectors()
+	assert.Error(t, err)
+
+	_, err = combineVectors([]float32{1, 2}, []float32{1})
+	assert.Error(t, err)
+}
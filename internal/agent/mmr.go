@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"math"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+)
+
+// mmrSelect picks k results by maximal marginal relevance: each pick
+// maximises lambda*relevance - (1-lambda)*max similarity to the documents
+// already picked. Relevance is the result score min-max normalised over
+// the candidates, so it works on fused and reranked scores alike;
+// similarity is the cosine of the dense vectors. Results without a vector
+// are treated as dissimilar to everything.
+func mmrSelect(results []qdrant.SearchResult, k int, lambda float64) []qdrant.SearchResult {
+	if k <= 0 || len(results) <= 1 {
+		return results
+	}
+	k = min(k, len(results))
+
+	relevance := normalizeScores(results)
+	norms := make([]float64, len(results))
+	for i, r := range results {
+		norms[i] = vectorNorm(r.Vector)
+	}
+
+	// maxSim[i] is candidate i's highest similarity to a selected document
+	maxSim := make([]float64, len(results))
+	selected := make([]bool, len(results))
+	picked := make([]qdrant.SearchResult, 0, k)
+
+	for len(picked) < k {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range results {
+			if selected[i] {
+				continue
+			}
+			score := lambda*relevance[i] - (1-lambda)*maxSim[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		selected[best] = true
+		picked = append(picked, results[best])
+		for i := range results {
+			if !selected[i] {
+				maxSim[i] = max(maxSim[i], cosine(results[i].Vector, results[best].Vector, norms[i], norms[best]))
+			}
+		}
+	}
+
+	return picked
+}
+
+// normalizeScores scales result scores to [0, 1]. Equal scores all map to 1.
+func normalizeScores(results []qdrant.SearchResult) []float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, r := range results {
+		lo = min(lo, float64(r.Score))
+		hi = max(hi, float64(r.Score))
+	}
+
+	out := make([]float64, len(results))
+	for i, r := range results {
+		if hi == lo {
+			out[i] = 1
+			continue
+		}
+		out[i] = (float64(r.Score) - lo) / (hi - lo)
+	}
+	return out
+}
+
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+func cosine(a, b []float32, normA, normB float64) float64 {
+	if len(a) == 0 || len(a) != len(b) || normA == 0 || normB == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot / (normA * normB)
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+)
+
+func mmrIDs(results []qdrant.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestMMRSelect(t *testing.T) {
+	// a and a-dup are near-identical; b is less relevant but different
+	results := []qdrant.SearchResult{
+		{ID: "a", Score: 0.9, Vector: []float32{1, 0}},
+		{ID: "a-dup", Score: 0.89, Vector: []float32{0.99, 0.01}},
+		{ID: "b", Score: 0.7, Vector: []float32{0, 1}},
+	}
+
+	tests := []struct {
+		name   string
+		lambda float64
+		k      int
+		want   []string
+	}{
+		{name: "diversity skips the duplicate", lambda: 0.5, k: 2, want: []string{"a", "b"}},
+		{name: "pure relevance keeps score order", lambda: 1, k: 2, want: []string{"a", "a-dup"}},
+		{name: "k larger than candidates", lambda: 0.5, k: 5, want: []string{"a", "b", "a-dup"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mmrIDs(mmrSelect(results, tt.k, tt.lambda)))
+		})
+	}
+}
+
+func TestMMRSelect_MissingVectors(t *testing.T) {
+	// Without vectors nothing is similar, so MMR falls back to score order
+	results := []qdrant.SearchResult{
+		{ID: "a", Score: 0.5},
+		{ID: "b", Score: 0.9},
+		{ID: "c", Score: 0.7},
+	}
+
+	assert.Equal(t, []string{"b", "c"}, mmrIDs(mmrSelect(results, 2, 0.7)))
+}
+
+func TestCosine(t *testing.T) {
+	a, b := []float32{1, 0}, []float32{1, 1}
+	assert.InDelta(t, 0.7071, cosine(a, b, vectorNorm(a), vectorNorm(b)), 1e-4)
+	assert.Zero(t, cosine(a, []float32{1, 0, 0}, 1, 1), "mismatched dimensions")
+	assert.Zero(t, cosine(nil, b, 0, vectorNorm(b)))
+}
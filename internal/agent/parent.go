@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+)
+
+// loadParents fills in the parent section of child chunks that don't carry
+// it. Each section is stored once, on its first child, and fetched from
+// there.
+func (f *Factory) loadParents(ctx context.Context, tenant string, results []qdrant.SearchResult) error {
+	condition, limit := parentCondition(results)
+	if limit == 0 {
+		return nil
+	}
+
+	carriers, err := f.qdrant.Scroll(ctx, f.cfg.VectorStore.Collection, tenant, qdrant.ScrollOptions{
+		Any:   []map[string][]string{condition},
+		Limit: uint32(limit),
+	})
+	if err != nil {
+		return err
+	}
+
+	fillParents(results, carriers)
+	return nil
+}
+
+// parentCondition builds the Scroll condition matching the first child of
+// every parent missing from results, and the number of parents.
+func parentCondition(results []qdrant.SearchResult) (map[string][]string, int) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, r := range results {
+		id := r.Payload[qdrant.ParentField]
+		if id == "" || r.Parent != "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, 0
+	}
+	return map[string][]string{
+		qdrant.ParentField:      ids,
+		qdrant.ParentChunkField: {"0"},
+	}, len(ids)
+}
+
+// fillParents sets the parent section of results from the children that
+// carry it.
+func fillParents(results, carriers []qdrant.SearchResult) {
+	sections := make(map[string]string, len(carriers))
+	for _, c := range carriers {
+		if id := c.Payload[qdrant.ParentField]; id != "" && c.Parent != "" {
+			sections[id] = c.Parent
+		}
+	}
+	for i := range results {
+		if results[i].Parent == "" {
+			results[i].Parent = sections[results[i].Payload[qdrant.ParentField]]
+		}
+	}
+}
+
+// collapseParents replaces child chunks by their parent section. Each
+// parent is kept once, at the position of its best-ranked child; results
+// without a parent pass through unchanged.
+func collapseParents(results []qdrant.SearchResult) []qdrant.SearchResult {
+	seen := make(map[string]bool)
+	collapsed := make([]qdrant.SearchResult, 0, len(results))
+	for _, r := range results {
+		id := r.Payload[qdrant.ParentField]
+		if id == "" || r.Parent == "" {
+			collapsed = append(collapsed, r)
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		r.Content = r.Parent
+		collapsed = append(collapsed, r)
+	}
+	return collapsed
+}
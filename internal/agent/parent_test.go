@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseParents(t *testing.T) {
+	child := func(id, parent, content, parentText string) qdrant.SearchResult {
+		return qdrant.SearchResult{
+			ID:      id,
+			Content: content,
+			Parent:  parentText,
+			Payload: map[string]string{qdrant.ParentField: parent},
+		}
+	}
+	results := []qdrant.SearchResult{
+		child("c1", "p1", "small one", "parent one"),
+		{ID: "plain", Content: "regular chunk", Payload: map[string]string{}},
+		child("c2", "p2", "small two", "parent two"),
+		child("c3", "p1", "small three", "parent one"),
+	}
+
+	collapsed := collapseParents(results)
+
+	var ids, contents []string
+	for _, r := range collapsed {
+		ids = append(ids, r.ID)
+		contents = append(contents, r.Content)
+	}
+	assert.Equal(t, []string{"c1", "plain", "c2"}, ids, "each parent kept at its best child's rank")
+	assert.Equal(t, []string{"parent one", "regular chunk", "parent two"}, contents)
+	assert.Equal(t, "small one", results[0].Content, "input is not modified")
+}
+
+func TestParentCondition(t *testing.T) {
+	results := []qdrant.SearchResult{
+		{ID: "c1", Payload: map[string]string{qdrant.ParentField: "p1"}},
+		{ID: "c2", Parent: "parent two", Payload: map[string]string{qdrant.ParentField: "p2"}},
+		{ID: "c3", Payload: map[string]string{qdrant.ParentField: "p1"}},
+		{ID: "c4", Payload: map[string]string{qdrant.ParentField: "p3"}},
+		{ID: "plain", Payload: map[string]string{}},
+	}
+
+	condition, limit := parentCondition(results)
+	assert.Equal(t, 2, limit)
+	assert.Equal(t, map[string][]string{
+		qdrant.ParentField:      {"p1", "p3"},
+		qdrant.ParentChunkField: {"0"},
+	}, condition)
+
+	_, limit = parentCondition(results[1:2])
+	assert.Zero(t, limit, "sections already carried")
+}
+
+func TestFillParents(t *testing.T) {
+	results := []qdrant.SearchResult{
+		{ID: "c1", Payload: map[string]string{qdrant.ParentField: "p1"}},
+		{ID: "c2", Parent: "parent two", Payload: map[string]string{qdrant.ParentField: "p2"}},
+		{ID: "c3", Payload: map[string]string{qdrant.ParentField: "p3"}},
+	}
+	fillParents(results, []qdrant.SearchResult{
+		{ID: "c0", Parent: "parent one", Payload: map[string]string{qdrant.ParentField: "p1"}},
+	})
+
+	assert.Equal(t, "parent one", results[0].Parent)
+	assert.Equal(t, "parent two", results[1].Parent)
+	assert.Empty(t, results[2].Parent, "missing sections leave the child as is")
+}
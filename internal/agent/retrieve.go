@@ -0,0 +1,328 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/rerank"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/adk/model"
+)
+
+// RetrievedContext holds the pre-fetched documents for a query.
+type RetrievedContext struct {
+	Documents []qdrant.SearchResult
+	// Query is the query retrieval ran with; for follow-ups this is the
+	// standalone rewrite rather than the raw message.
+	Query string
+	// Condensed reports whether Query was rewritten from history.
+	Condensed bool
+	// Queries lists the generated alternative queries when expansion ran.
+	Queries []string
+	// Reranked reports whether document scores come from the reranker.
+	Reranked bool
+	// Relevance is the highest cosine similarity between the query's
+	// embedding and a candidate chunk, taken before reranking and MMR.
+	// Unlike document scores, its scale doesn't depend on which fusion or
+	// reranking ran, so it can be compared against a fixed threshold.
+	Relevance float64
+}
+
+// RetrieveOptions adjusts a single retrieval. Zero values use the
+// configured defaults.
+type RetrieveOptions struct {
+	TopK int
+	// Rerank enables or disables the rerank stage for this request.
+	// It has no effect when no reranker is configured.
+	Rerank *bool
+	// Expand enables or disables multi-query expansion for this request.
+	Expand *bool
+	// HyDE enables or disables hypothetical document embeddings for this
+	// request.
+	HyDE *bool
+	// MMR enables or disables maximal marginal relevance re-selection for
+	// this request.
+	MMR *bool
+	// ContextWindow enables or disables stitching neighbouring chunks
+	// around each result for this request.
+	ContextWindow *bool
+	// Filter restricts results to documents whose metadata fields equal
+	// the given values.
+	Filter map[string]string
+	// History holds the preceding conversation turns, oldest first. When
+	// set, the query is rewritten into a standalone query before search.
+	History []Turn
+	// QueryVector is the query's embedding when the caller already has
+	// it. It is reused when the query is searched as is.
+	QueryVector []float32
+}
+
+// Retrieve performs upfront document retrieval for a query.
+// Only documents owned by tenant are considered.
+// This should be called before NewRunner to pre-fetch relevant context.
+func (f *Factory) Retrieve(ctx context.Context, tenant, query string, opts RetrieveOptions) (*RetrievedContext, error) {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = f.cfg.Retriever.TopK
+	}
+	if topK <= 0 {
+		topK = 10
+	}
+
+	useRerank := f.reranker != nil && enabled(opts.Rerank, true)
+	useMMR := enabled(opts.MMR, f.cfg.Retriever.MMR.Enabled)
+	fetch := topK
+	if useRerank {
+		fetch = max(fetch, f.cfg.Retriever.Rerank.Candidates)
+	}
+	if useMMR {
+		fetch = max(fetch, f.cfg.Retriever.MMR.Candidates)
+	}
+	if f.cfg.Retriever.ParentDocument.Enabled {
+		fetch = max(fetch, f.cfg.Retriever.ParentDocument.Candidates)
+	}
+
+	retrieved := &RetrievedContext{Query: query}
+	queryVector := opts.QueryVector
+	if history := opts.History; len(history) > 0 && f.cfg.Retriever.Condense.Enabled {
+		if n := f.cfg.Retriever.Condense.MaxTurns; n > 0 && len(history) > n {
+			history = history[len(history)-n:]
+		}
+		condensed, err := condenseQuery(ctx, f.model, history, query)
+		if err == nil {
+			retrieved.Query = condensed
+			retrieved.Condensed = condensed != query
+			if retrieved.Condensed {
+				queryVector = nil
+			}
+			query = condensed
+		} else {
+			slog.WarnContext(ctx, "Query condensation failed, using raw message", "error", err)
+		}
+	}
+
+	queries := []string{query}
+	var expanded []string
+	if enabled(opts.Expand, f.cfg.Retriever.Expansion.Enabled) {
+		var err error
+		expanded, err = expandQuery(ctx, f.model, query, f.cfg.Retriever.Expansion.Queries)
+		if err != nil {
+			// The original query alone still retrieves something useful
+			slog.WarnContext(ctx, "Query expansion failed, using original query", "error", err)
+		}
+		queries = append(queries, expanded...)
+	}
+
+	hyde := enabled(opts.HyDE, f.cfg.Retriever.HyDE.Enabled)
+	searchVector := queryVector
+	if hyde {
+		searchVector = nil
+	}
+	// Vectors are always fetched to measure relevance
+	lists, plainVector, err := f.searchAll(ctx, tenant, queries, searchVector, uint64(fetch), hyde, qdrant.SearchOptions{
+		Match:       opts.Filter,
+		WithVectors: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := lists[0]
+	if len(lists) > 1 {
+		results = fuseResults(lists, f.cfg.Retriever.Expansion.RRFK)
+	}
+	// Child chunks matched; the model gets their parent sections
+	if err := f.loadParents(ctx, tenant, results); err != nil {
+		// Children without their section are still usable
+		slog.WarnContext(ctx, "Loading parent sections failed, using child chunks", "error", err)
+	}
+	results = collapseParents(results)
+
+	// Relevance is measured against the query itself, not a HyDE passage
+	if plainVector == nil {
+		plainVector = queryVector
+	}
+	if plainVector == nil && len(results) > 0 {
+		if plainVector, err = f.embedding.EmbedQuery(ctx, query); err != nil {
+			slog.WarnContext(ctx, "Embedding query for relevance failed", "error", err)
+		}
+	}
+	retrieved.Relevance = maxRelevance(plainVector, results)
+
+	retrieved.Queries = expanded
+	if useRerank && len(results) > 0 {
+		// MMR selects top_k afterwards, so keep every candidate for it
+		keep := topK
+		if useMMR {
+			keep = len(results)
+		}
+		reranked, err := rerankResults(ctx, f.reranker, query, results, keep)
+		if err == nil {
+			results = reranked
+			retrieved.Reranked = true
+		} else {
+			// Fused ranking is still a usable answer
+			slog.WarnContext(ctx, "Rerank failed, using fused ranking", "error", err)
+		}
+	}
+	if useMMR {
+		results = mmrSelect(results, topK, f.cfg.Retriever.MMR.Lambda)
+	}
+	// Vectors are only needed for relevance and selection
+	for i := range results {
+		results[i].Vector = nil
+	}
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	useWindow := enabled(opts.ContextWindow, f.cfg.Retriever.ContextWindow.Enabled)
+	if useWindow && len(results) > 0 {
+		if err := f.expandWindow(ctx, tenant, results, f.cfg.Retriever.ContextWindow.Chunks); err != nil {
+			// The chunks on their own are still usable
+			slog.WarnContext(ctx, "Context window expansion failed, using chunks alone", "error", err)
+		}
+	}
+	retrieved.Documents = results
+
+	slog.DebugContext(ctx, "Retrieved context",
+		"tenant", tenant,
+		"condensed", retrieved.Condensed,
+		"queries", len(queries),
+		"hyde", hyde,
+		"documents", len(results),
+		"reranked", retrieved.Reranked,
+		"relevance", retrieved.Relevance,
+		"mmr", useMMR,
+		"context_window", useWindow,
+	)
+
+	return retrieved, nil
+}
+
+// searchAll runs a hybrid search for each query in parallel and returns
+// the result lists in query order, with the embedding of the first query.
+// With hyde, each query is searched by a hypothetical answer passage
+// instead of its own embedding, and no query embedding is returned. A
+// non-nil vector is the search vector of the first query.
+func (f *Factory) searchAll(ctx context.Context, tenant string, queries []string, vector []float32, limit uint64, hyde bool, searchOpts qdrant.SearchOptions) ([][]qdrant.SearchResult, []float32, error) {
+	lists := make([][]qdrant.SearchResult, len(queries))
+	var first []float32
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, q := range queries {
+		g.Go(func() error {
+			// Generate query embedding using Gemini
+			embed := f.embedding.EmbedQuery
+			if hyde {
+				embed = f.embedHyDE
+			}
+			queryVector := vector
+			if i > 0 || queryVector == nil {
+				var err error
+				queryVector, err = embed(gctx, q)
+				if err != nil {
+					return fmt.Errorf("embedding query failed: %w", err)
+				}
+			}
+			if i == 0 && !hyde {
+				first = queryVector
+			}
+
+			results, err := f.qdrant.HybridSearch(gctx, f.cfg.VectorStore.Collection, tenant, queryVector, nil, limit, searchOpts)
+			if err != nil {
+				return fmt.Errorf("retrieval failed: %w", err)
+			}
+			lists[i] = results
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return lists, first, nil
+}
+
+// maxRelevance returns the highest cosine similarity between query and
+// the results' dense vectors.
+func maxRelevance(query []float32, results []qdrant.SearchResult) float64 {
+	norm := vectorNorm(query)
+	var best float64
+	for _, r := range results {
+		best = max(best, cosine(query, r.Vector, norm, vectorNorm(r.Vector)))
+	}
+	return best
+}
+
+// enabled resolves a per-request override against the configured default.
+func enabled(override *bool, def bool) bool {
+	if override != nil {
+		return *override
+	}
+	return def
+}
+
+// rerankResults rescores results against query and keeps the best topK.
+// Scores are replaced by the reranker's scores.
+func rerankResults(ctx context.Context, r rerank.Reranker, query string, results []qdrant.SearchResult, topK int) ([]qdrant.SearchResult, error) {
+	contents := make([]string, len(results))
+	for i, res := range results {
+		contents[i] = res.Content
+	}
+
+	start := time.Now()
+	scores, err := r.Score(ctx, query, contents)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != len(results) {
+		return nil, fmt.Errorf("%w: got %d, want %d", rerank.ErrScoreCount, len(scores), len(results))
+	}
+
+	order := rerank.Order(scores)
+	if len(order) > topK {
+		order = order[:topK]
+	}
+
+	reranked := make([]qdrant.SearchResult, len(order))
+	for i, idx := range order {
+		reranked[i] = results[idx]
+		reranked[i].Score = float32(scores[idx])
+	}
+
+	slog.DebugContext(ctx, "Reranked results",
+		"candidates", len(results),
+		"kept", len(reranked),
+		"duration", time.Since(start),
+	)
+
+	return reranked, nil
+}
+
+// newReranker creates the configured reranker, or nil when reranking is
+// disabled.
+func newReranker(cfg config.RerankConfig, llmModel model.LLM) (rerank.Reranker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Provider {
+	case "", "lexical":
+		return rerank.NewLexical(), nil
+	case "http":
+		return rerank.NewHTTP(rerank.HTTPConfig{
+			URL:     cfg.URL,
+			APIKey:  cfg.APIKey,
+			Model:   cfg.Model,
+			Timeout: time.Duration(cfg.Timeout) * time.Second,
+		})
+	case "llm":
+		return rerank.NewLLM(llmModel, cfg.Concurrency), nil
+	default:
+		return nil, fmt.Errorf("unknown rerank provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/rerank"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scoreFunc adapts a function to rerank.Reranker.
+type scoreFunc func(query string, documents []string) ([]float64, error)
+
+func (f scoreFunc) Score(ctx context.Context, query string, documents []string) ([]float64, error) {
+	return f(query, documents)
+}
+
+func TestRerankResults(t *testing.T) {
+	results := []qdrant.SearchResult{
+		{ID: "a", Content: "alpha", Score: 0.9},
+		{ID: "b", Content: "beta", Score: 0.8},
+		{ID: "c", Content: "gamma", Score: 0.7},
+	}
+	reranker := scoreFunc(func(query string, documents []string) ([]float64, error) {
+		assert.Equal(t, "q", query)
+		assert.Equal(t, []string{"alpha", "beta", "gamma"}, documents)
+		return []float64{0.1, 0.3, 0.9}, nil
+	})
+
+	reranked, err := rerankResults(context.Background(), reranker, "q", results, 2)
+	require.NoError(t, err)
+	require.Len(t, reranked, 2)
+	assert.Equal(t, "c", reranked[0].ID)
+	assert.Equal(t, float32(0.9), reranked[0].Score)
+	assert.Equal(t, "b", reranked[1].ID)
+
+	// The input slice is left untouched
+	assert.Equal(t, "a", results[0].ID)
+	assert.Equal(t, float32(0.9), results[0].Score)
+}
+
+func TestRerankResults_Errors(t *testing.T) {
+	results := []qdrant.SearchResult{{ID: "a"}, {ID: "b"}}
+
+	failing := scoreFunc(func(string, []string) ([]float64, error) {
+		return nil, errors.New("service down")
+	})
+	_, err := rerankResults(context.Background(), failing, "q", results, 1)
+	assert.Error(t, err)
+
+	short := scoreFunc(func(string, []string) ([]float64, error) {
+		return []float64{1}, nil
+	})
+	_, err = rerankResults(context.Background(), short, "q", results, 1)
+	assert.ErrorIs(t, err, rerank.ErrScoreCount)
+}
+
+func TestNewReranker(t *testing.T) {
+	r, err := newReranker(config.RerankConfig{Provider: "lexical"}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, r, "disabled reranking returns no reranker")
+
+	r, err = newReranker(config.RerankConfig{Enabled: true}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &rerank.Lexical{}, r)
+
+	r, err = newReranker(config.RerankConfig{Enabled: true, Provider: "llm"}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &rerank.LLM{}, r)
+
+	r, err = newReranker(config.RerankConfig{Enabled: true, Provider: "http", URL: "http://reranker:8080/rerank"}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &rerank.HTTP{}, r)
+
+	_, err = newReranker(config.RerankConfig{Enabled: true, Provider: "http"}, nil)
+	assert.Error(t, err)
+
+	_, err = newReranker(config.RerankConfig{Enabled: true, Provider: "magic"}, nil)
+	assert.Error(t, err)
+}
+
+func TestMaxRelevance(t *testing.T) {
+	results := []qdrant.SearchResult{
+		{ID: "a", Score: 9.1, Vector: []float32{0, 1}},
+		{ID: "b", Score: 0.01, Vector: []float32{0.6, 0.8}},
+		{ID: "c"},
+	}
+	assert.InDelta(t, 0.6, maxRelevance([]float32{1, 0}, results), 1e-6)
+	assert.Zero(t, maxRelevance(nil, results))
+	assert.Zero(t, maxRelevance([]float32{1, 0}, nil))
+}
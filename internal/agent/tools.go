@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/geminitool"
+)
+
+// Sub-agent names, which are also the tool names the root agent sees.
+const (
+	knowledgeBaseAgentName = "knowledge_base_agent"
+	webSearchAgentName     = "web_search_agent"
+)
+
+const knowledgeBaseInstruction = `You research questions against the internal knowledge base using the knowledge_base_search tool.
+Break the request into focused queries and search repeatedly, refining your queries and narrowing with metadata filters (such as source) when helpful, until you have enough evidence or run out of searches.
+Reply with the relevant findings, quoting key passages and naming the source of each document. Say clearly if nothing relevant was found.`
+
+const webSearchInstruction = `You answer requests by searching the web with %s. Reply with the relevant findings and the URL of each source.`
+
+// webSearchToolName is the function tool backed by a configured web
+// search provider.
+const webSearchToolName = "web_search"
+
+// searchToolArgs are the arguments of the knowledge_base_search tool.
+type searchToolArgs struct {
+	Query  string            `json:"query" jsonschema:"focused search query"`
+	TopK   int               `json:"top_k,omitempty" jsonschema:"number of documents to return"`
+	Filter map[string]string `json:"filter,omitempty" jsonschema:"only return documents whose metadata fields equal these values, e.g. {\"source\": \"handbook.pdf\"}"`
+}
+
+// searchToolResult is the result of the knowledge_base_search tool.
+type searchToolResult struct {
+	Results []searchToolDocument `json:"results"`
+	// RemainingSearches tells the model how many more searches it may run.
+	RemainingSearches int `json:"remaining_searches"`
+}
+
+type searchToolDocument struct {
+	ID       string            `json:"id"`
+	Content  string            `json:"content"`
+	Score    float32           `json:"score"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// searchFunc runs one knowledge base retrieval.
+type searchFunc func(ctx context.Context, query string, opts RetrieveOptions) (*RetrievedContext, error)
+
+// knowledgeBaseSearch backs the knowledge_base_search tool for a single
+// chat request, enforcing a budget on the number of searches.
+type knowledgeBaseSearch struct {
+	search      searchFunc
+	topK        int
+	maxSearches int32
+	used        atomic.Int32
+}
+
+func (k *knowledgeBaseSearch) run(ctx context.Context, args searchToolArgs) (searchToolResult, error) {
+	if args.Query == "" {
+		return searchToolResult{}, fmt.Errorf("query is required")
+	}
+	n := k.used.Add(1)
+	if k.maxSearches > 0 && n > k.maxSearches {
+		return searchToolResult{}, fmt.Errorf("search budget of %d exhausted, answer with the evidence gathered so far", k.maxSearches)
+	}
+
+	topK := args.TopK
+	if topK <= 0 || topK > k.topK {
+		topK = k.topK
+	}
+
+	// The agent writes its own queries, so query rewriting is skipped
+	off := false
+	retrieved, err := k.search(ctx, args.Query, RetrieveOptions{
+		TopK:   topK,
+		Filter: args.Filter,
+		Expand: &off,
+		HyDE:   &off,
+	})
+	if err != nil {
+		return searchToolResult{}, err
+	}
+
+	result := searchToolResult{
+		Results:           make([]searchToolDocument, len(retrieved.Documents)),
+		RemainingSearches: -1,
+	}
+	if k.maxSearches > 0 {
+		result.RemainingSearches = int(k.maxSearches - n)
+	}
+	for i, doc := range retrieved.Documents {
+		result.Results[i] = searchToolDocument{
+			ID:       doc.ID,
+			Content:  documentText(doc),
+			Score:    doc.Score,
+			Metadata: doc.Payload,
+		}
+	}
+	return result, nil
+}
+
+// newKnowledgeBaseAgent creates a sub-agent that searches tenant's
+// documents with its own queries. Function tools and Gemini's native tools
+// can't be mixed in one agent, so each kind lives in its own sub-agent.
+func (f *Factory) newKnowledgeBaseAgent(tenant string) (adkagent.Agent, error) {
+	kb := &knowledgeBaseSearch{
+		search: func(ctx context.Context, query string, opts RetrieveOptions) (*RetrievedContext, error) {
+			return f.Retrieve(ctx, tenant, query, opts)
+		},
+		topK:        f.cfg.Agent.Tools.TopK,
+		maxSearches: int32(f.cfg.Agent.Tools.MaxSearches),
+	}
+	if kb.topK <= 0 {
+		kb.topK = 5
+	}
+
+	searchTool, err := functiontool.New(functiontool.Config{
+		Name:        "knowledge_base_search",
+		Description: "Searches the internal knowledge base and returns the most relevant document chunks.",
+	}, func(tc tool.Context, args searchToolArgs) (searchToolResult, error) {
+		return kb.run(tc, args)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search tool: %w", err)
+	}
+
+	return llmagent.New(llmagent.Config{
+		Name:                knowledgeBaseAgentName,
+		Model:               f.model,
+		Description:         "Searches the internal knowledge base, possibly several times, and reports the findings with sources.",
+		Instruction:         knowledgeBaseInstruction,
+		Tools:               []tool.Tool{searchTool},
+		AfterModelCallbacks: []llmagent.AfterModelCallback{chargeUsage},
+	})
+}
+
+// newWebSearchAgent creates a sub-agent that searches the web.
+func (f *Factory) newWebSearchAgent() (adkagent.Agent, error) {
+	webTool, name, err := f.webSearchTool()
+	if err != nil {
+		return nil, err
+	}
+	return llmagent.New(llmagent.Config{
+		Name:                webSearchAgentName,
+		Model:               f.model,
+		Description:         "Searches the web for current or external information and reports the findings with URLs.",
+		Instruction:         fmt.Sprintf(webSearchInstruction, name) + "\n" + f.webPolicy.Instruction(),
+		Tools:               []tool.Tool{webTool},
+		AfterModelCallbacks: []llmagent.AfterModelCallback{chargeUsage, f.recordWebSources},
+	})
+}
+
+// webSearchArgs are the arguments of the web_search tool.
+type webSearchArgs struct {
+	Query string `json:"query" jsonschema:"web search query"`
+}
+
+// webSearchResult is the result of the web_search tool.
+type webSearchResult struct {
+	Passages []websearch.Passage `json:"passages"`
+}
+
+// webSearchTool returns the web search tool and its name: Gemini's native
+// google_search, or a function tool over the configured provider.
+func (f *Factory) webSearchTool() (tool.Tool, string, error) {
+	if f.webGatherer == nil {
+		return geminitool.GoogleSearch{}, "google_search", nil
+	}
+
+	t, err := functiontool.New(functiontool.Config{
+		Name:        webSearchToolName,
+		Description: "Searches the web and returns relevant passages from the result pages, each with its URL for citation.",
+	}, func(tc tool.Context, args webSearchArgs) (webSearchResult, error) {
+		return searchWeb(tc, f.webGatherer, args)
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create web search tool: %w", err)
+	}
+	return t, webSearchToolName, nil
+}
+
+// searchWeb runs the web_search tool and records the cited pages.
+func searchWeb(ctx context.Context, g *websearch.Gatherer, args webSearchArgs) (webSearchResult, error) {
+	if args.Query == "" {
+		return webSearchResult{}, fmt.Errorf("query is required")
+	}
+	passages, err := g.Gather(ctx, args.Query)
+	if err != nil {
+		return webSearchResult{}, err
+	}
+
+	if len(passages) > 0 {
+		sources := make([]websearch.Source, len(passages))
+		for i, p := range passages {
+			sources[i] = websearch.Source{Title: p.Title, URL: p.URL}
+		}
+		websearch.RecorderFrom(ctx).Record(sources...)
+	}
+	return webSearchResult{Passages: passages}, nil
+}
+
+// agentTools wraps the retrieval sub-agents as tools for the root agent.
+// The web search agent is only included when web is set.
+func (f *Factory) agentTools(tenant string, web bool) ([]tool.Tool, error) {
+	kbAgent, err := f.newKnowledgeBaseAgent(tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create knowledge base agent: %w", err)
+	}
+	tools := []tool.Tool{agenttool.New(kbAgent, nil)}
+	if !web {
+		return tools, nil
+	}
+
+	webAgent, err := f.newWebSearchAgent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web search agent: %w", err)
+	}
+	return append(tools, agenttool.New(webAgent, nil)), nil
+}
+
+// chargeUsage charges sub-agent model calls to the usage meter. Sub-agents
+// run in their own runner, so their events never reach the chat handler.
+func chargeUsage(ctx adkagent.CallbackContext, resp *model.LLMResponse, err error) (*model.LLMResponse, error) {
+	if resp != nil && !resp.Partial {
+		usage.Charge(ctx, usage.FromMetadata(resp.UsageMetadata))
+	}
+	return nil, nil
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestKnowledgeBaseSearch(t *testing.T) {
+	var got RetrieveOptions
+	kb := &knowledgeBaseSearch{
+		search: func(ctx context.Context, query string, opts RetrieveOptions) (*RetrievedContext, error) {
+			assert.Equal(t, "refund policy", query)
+			got = opts
+			return &RetrievedContext{Documents: []qdrant.SearchResult{
+				{ID: "a", Content: "Refunds within 30 days.", Score: 0.8, Payload: map[string]string{"source": "policy.pdf"}},
+			}}, nil
+		},
+		topK:        5,
+		maxSearches: 2,
+	}
+
+	result, err := kb.run(context.Background(), searchToolArgs{
+		Query:  "refund policy",
+		TopK:   50,
+		Filter: map[string]string{"source": "policy.pdf"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, "Refunds within 30 days.", result.Results[0].Content)
+	assert.Equal(t, "policy.pdf", result.Results[0].Metadata["source"])
+	assert.Equal(t, 1, result.RemainingSearches)
+
+	// top_k is capped and the agent's own query is used verbatim
+	assert.Equal(t, 5, got.TopK)
+	assert.Equal(t, map[string]string{"source": "policy.pdf"}, got.Filter)
+	assert.False(t, *got.Expand)
+	assert.False(t, *got.HyDE)
+}
+
+func TestKnowledgeBaseSearch_Budget(t *testing.T) {
+	calls := 0
+	kb := &knowledgeBaseSearch{
+		search: func(ctx context.Context, query string, opts RetrieveOptions) (*RetrievedContext, error) {
+			calls++
+			return &RetrievedContext{}, nil
+		},
+		topK:        5,
+		maxSearches: 1,
+	}
+
+	_, err := kb.run(context.Background(), searchToolArgs{Query: "first"})
+	require.NoError(t, err)
+	_, err = kb.run(context.Background(), searchToolArgs{Query: "second"})
+	assert.ErrorContains(t, err, "budget")
+	assert.Equal(t, 1, calls)
+}
+
+func TestKnowledgeBaseSearch_Errors(t *testing.T) {
+	kb := &knowledgeBaseSearch{
+		search: func(ctx context.Context, query string, opts RetrieveOptions) (*RetrievedContext, error) {
+			return nil, errors.New("qdrant unavailable")
+		},
+		topK: 5,
+	}
+
+	_, err := kb.run(context.Background(), searchToolArgs{})
+	assert.ErrorContains(t, err, "query is required")
+
+	_, err = kb.run(context.Background(), searchToolArgs{Query: "q"})
+	assert.ErrorContains(t, err, "qdrant unavailable")
+}
+
+func newToolsTestFactory(t *testing.T) *Factory {
+	t.Helper()
+	policy, err := websearch.NewPolicy(websearch.Config{})
+	require.NoError(t, err)
+
+	return &Factory{
+		cfg: &config.Config{},
+		model: mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+			return "", nil
+		}),
+		webPolicy: policy,
+	}
+}
+
+func TestAgentTools(t *testing.T) {
+	f := newToolsTestFactory(t)
+
+	tools, err := f.agentTools("team-a", true)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, knowledgeBaseAgentName, tools[0].Name())
+	assert.Equal(t, webSearchAgentName, tools[1].Name())
+
+	tools, err = f.agentTools("team-a", false)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, knowledgeBaseAgentName, tools[0].Name())
+}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// recordWebSources records the web pages a model response was grounded on
+// with the request's websearch.Recorder. Sources outside the domain policy
+// are dropped and logged. This only trims the citations: the model has
+// already read the pages, which is why newWebGatherer rejects domain lists
+// for Gemini's native search.
+func (f *Factory) recordWebSources(ctx adkagent.CallbackContext, resp *model.LLMResponse, err error) (*model.LLMResponse, error) {
+	if resp != nil {
+		f.recordGrounding(ctx, resp.GroundingMetadata)
+	}
+	return nil, nil
+}
+
+func (f *Factory) recordGrounding(ctx context.Context, gm *genai.GroundingMetadata) {
+	if gm == nil || (len(gm.GroundingChunks) == 0 && len(gm.WebSearchQueries) == 0) {
+		return
+	}
+
+	var sources []websearch.Source
+	for _, chunk := range gm.GroundingChunks {
+		if chunk.Web == nil {
+			continue
+		}
+		if !f.webPolicy.Allowed(webSourceHost(chunk.Web)) {
+			slog.WarnContext(ctx, "Dropped web source blocked by policy", "title", chunk.Web.Title, "domain", chunk.Web.Domain)
+			continue
+		}
+		sources = append(sources, websearch.Source{Title: chunk.Web.Title, URL: chunk.Web.URI})
+	}
+
+	websearch.RecorderFrom(ctx).Record(sources...)
+}
+
+// webSourceHost picks the best host name for policy checks. The Gemini API
+// returns redirect URIs and puts the site's domain in the title; Vertex AI
+// also fills in the domain.
+func webSourceHost(web *genai.GroundingChunkWeb) string {
+	if web.Domain != "" {
+		return web.Domain
+	}
+	if title := strings.TrimSpace(web.Title); title != "" && !strings.ContainsAny(title, " /") && strings.Contains(title, ".") {
+		return title
+	}
+	return web.URI
+}
+
+// newWebGatherer creates the configured web search provider, or nil when
+// the model's native google_search is used.
+func newWebGatherer(cfg config.WebSearchConfig, policy *websearch.Policy) (*websearch.Gatherer, error) {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+
+	var searcher websearch.Searcher
+	switch cfg.Provider {
+	case "", "gemini":
+		// google_search can't be restricted to domains, and filtering the
+		// grounding afterwards would leave blocked content in the answer
+		if len(cfg.AllowedDomains) > 0 || len(cfg.BlockedDomains) > 0 {
+			return nil, fmt.Errorf("allowed_domains and blocked_domains need a searxng or http provider, gemini search can't be restricted")
+		}
+		return nil, nil
+	case "searxng":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("searxng url is required")
+		}
+		httpCfg := websearch.SearxNGConfig(cfg.URL)
+		httpCfg.APIKey = cfg.APIKey
+		httpCfg.Timeout = timeout
+		s, err := websearch.NewHTTP(httpCfg)
+		if err != nil {
+			return nil, err
+		}
+		searcher = s
+	case "http":
+		s, err := websearch.NewHTTP(websearch.HTTPConfig{
+			URL:          cfg.URL,
+			APIKey:       cfg.APIKey,
+			QueryParam:   cfg.HTTP.QueryParam,
+			Params:       cfg.HTTP.Params,
+			ResultsField: cfg.HTTP.ResultsField,
+			TitleField:   cfg.HTTP.TitleField,
+			URLField:     cfg.HTTP.URLField,
+			SnippetField: cfg.HTTP.SnippetField,
+			Timeout:      timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		searcher = s
+	case "stub":
+		searcher = &websearch.Stub{}
+	default:
+		return nil, fmt.Errorf("unknown web search provider %q", cfg.Provider)
+	}
+
+	var fetcher *websearch.Fetcher
+	if cfg.FetchPages > 0 {
+		internal, err := websearch.ParseNetworks(cfg.InternalNetworks)
+		if err != nil {
+			return nil, fmt.Errorf("invalid web_search.internal_networks: %w", err)
+		}
+		fetcher = websearch.NewFetcher(timeout, policy.Allowed, internal)
+	}
+
+	return websearch.NewGatherer(searcher, fetcher, policy, websearch.GatherConfig{
+		Results:    cfg.Results,
+		FetchPages: cfg.FetchPages,
+		Passages:   cfg.Passages,
+	}), nil
+}
+
+// bestScore returns the relevance of the retrieved documents for the web
+// search fallback. Document scores are not used: their scale changes with
+// fusion, expansion and reranking, which callers toggle per request.
+func bestScore(retrieved *RetrievedContext) (float64, bool) {
+	if retrieved == nil || len(retrieved.Documents) == 0 {
+		return 0, false
+	}
+	return retrieved.Relevance, true
+}
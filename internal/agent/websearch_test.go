@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/genai"
+)
+
+func TestRecordGrounding(t *testing.T) {
+	policy, err := websearch.NewPolicy(websearch.Config{BlockedDomains: []string{"example.com"}})
+	require.NoError(t, err)
+	f := &Factory{webPolicy: policy}
+
+	rec := &websearch.Recorder{}
+	ctx := websearch.WithRecorder(context.Background(), rec)
+
+	f.recordGrounding(ctx, nil)
+	f.recordGrounding(ctx, &genai.GroundingMetadata{})
+	assert.False(t, rec.Used())
+
+	f.recordGrounding(ctx, &genai.GroundingMetadata{
+		WebSearchQueries: []string{"go release"},
+		GroundingChunks: []*genai.GroundingChunk{
+			{Web: &genai.GroundingChunkWeb{Title: "go.dev", URI: "https://redirect.example/1"}},
+			{Web: &genai.GroundingChunkWeb{Title: "shop.example.com", URI: "https://redirect.example/2"}},
+			{Web: &genai.GroundingChunkWeb{Title: "Release notes", Domain: "example.com", URI: "https://example.com/notes"}},
+			{},
+		},
+	})
+
+	assert.True(t, rec.Used())
+	assert.Equal(t, []websearch.Source{{Title: "go.dev", URL: "https://redirect.example/1"}}, rec.Sources())
+}
+
+func TestWebSourceHost(t *testing.T) {
+	assert.Equal(t, "go.dev", webSourceHost(&genai.GroundingChunkWeb{Domain: "go.dev", Title: "Go"}))
+	assert.Equal(t, "go.dev", webSourceHost(&genai.GroundingChunkWeb{Title: "go.dev", URI: "https://r/1"}))
+	assert.Equal(t, "https://go.dev/doc", webSourceHost(&genai.GroundingChunkWeb{Title: "Go docs", URI: "https://go.dev/doc"}))
+}
+
+func TestBestScore(t *testing.T) {
+	_, found := bestScore(nil)
+	assert.False(t, found)
+	_, found = bestScore(&RetrievedContext{})
+	assert.False(t, found)
+
+	// Reranker scores don't shift the threshold
+	best, found := bestScore(&RetrievedContext{
+		Documents: []qdrant.SearchResult{{Score: 8.5}, {Score: 0.03}},
+		Relevance: 0.42,
+	})
+	assert.True(t, found)
+	assert.InDelta(t, 0.42, best, 1e-6)
+}
+
+func TestStrategy(t *testing.T) {
+	s := strategy(true, true, webSearchAgentName, "Only use web results from these domains: go.dev.\n")
+	assert.Contains(t, s, knowledgeBaseAgentName)
+	assert.Contains(t, s, "use "+webSearchAgentName)
+	assert.Contains(t, s, "(web)")
+	assert.Contains(t, s, "go.dev")
+
+	s = strategy(false, false, "google_search", "Only use web results from these domains: go.dev.\n")
+	assert.NotContains(t, s, knowledgeBaseAgentName)
+	assert.NotContains(t, s, "google_search")
+	assert.NotContains(t, s, "go.dev")
+	assert.Contains(t, s, "Web search is not available")
+}
+
+func TestNewWebGatherer(t *testing.T) {
+	policy, err := websearch.NewPolicy(websearch.Config{})
+	require.NoError(t, err)
+
+	g, err := newWebGatherer(config.WebSearchConfig{Provider: "gemini"}, policy)
+	require.NoError(t, err)
+	assert.Nil(t, g, "native search needs no gatherer")
+
+	_, err = newWebGatherer(config.WebSearchConfig{Provider: "gemini", BlockedDomains: []string{"example.com"}}, policy)
+	assert.Error(t, err, "native search can't enforce domain lists")
+	_, err = newWebGatherer(config.WebSearchConfig{AllowedDomains: []string{"go.dev"}}, policy)
+	assert.Error(t, err)
+	g, err = newWebGatherer(config.WebSearchConfig{Provider: "stub", AllowedDomains: []string{"go.dev"}}, policy)
+	require.NoError(t, err)
+	assert.NotNil(t, g)
+
+	for _, cfg := range []config.WebSearchConfig{
+		{Provider: "stub"},
+		{Provider: "searxng", URL: "http://searxng:8080"},
+		{Provider: "http", URL: "http://search.internal/api", FetchPages: 2},
+	} {
+		g, err := newWebGatherer(cfg, policy)
+		require.NoError(t, err, cfg.Provider)
+		assert.NotNil(t, g, cfg.Provider)
+	}
+
+	_, err = newWebGatherer(config.WebSearchConfig{Provider: "searxng"}, policy)
+	assert.Error(t, err)
+	_, err = newWebGatherer(config.WebSearchConfig{Provider: "http"}, policy)
+	assert.Error(t, err)
+	_, err = newWebGatherer(config.WebSearchConfig{Provider: "bing"}, policy)
+	assert.Error(t, err)
+}
+
+func TestSearchWeb(t *testing.T) {
+	policy, err := websearch.NewPolicy(websearch.Config{})
+	require.NoError(t, err)
+	g := websearch.NewGatherer(&websearch.Stub{Results: []websearch.Result{
+		{Title: "Go 1.25", URL: "https://go.dev/doc/go1.25", Snippet: "Release notes for Go 1.25"},
+	}}, nil, policy, websearch.GatherConfig{})
+
+	rec := &websearch.Recorder{}
+	ctx := websearch.WithRecorder(context.Background(), rec)
+
+	result, err := searchWeb(ctx, g, webSearchArgs{Query: "go release"})
+	require.NoError(t, err)
+	require.Len(t, result.Passages, 1)
+	assert.Equal(t, "Release notes for Go 1.25", result.Passages[0].Text)
+	assert.Equal(t, []websearch.Source{{Title: "Go 1.25", URL: "https://go.dev/doc/go1.25"}}, rec.Sources())
+
+	_, err = searchWeb(ctx, g, webSearchArgs{})
+	assert.Error(t, err)
+}
+
+func TestWebSearchTool(t *testing.T) {
+	f := newToolsTestFactory(t)
+
+	webTool, name, err := f.webSearchTool()
+	require.NoError(t, err)
+	assert.Equal(t, "google_search", name)
+	assert.Equal(t, "google_search", webTool.Name())
+
+	f.webGatherer = websearch.NewGatherer(&websearch.Stub{}, nil, f.webPolicy, websearch.GatherConfig{})
+	webTool, name, err = f.webSearchTool()
+	require.NoError(t, err)
+	assert.Equal(t, webSearchToolName, name)
+	assert.Equal(t, webSearchToolName, webTool.Name())
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+)
+
+// minOverlap is the shortest shared boundary treated as splitter overlap
+// rather than a coincidental match.
+const minOverlap = 8
+
+// chunkRef identifies a chunk by its document and position.
+type chunkRef struct {
+	field, doc string
+	index      int
+}
+
+// chunkOf returns where a result sits in its document. Chunks stored before
+// document IDs were introduced are grouped by source instead. Child chunks
+// are already widened to their parent section and have no window.
+func chunkOf(r qdrant.SearchResult) (chunkRef, bool) {
+	if r.Payload[qdrant.ParentField] != "" {
+		return chunkRef{}, false
+	}
+	index, err := strconv.Atoi(r.Payload[qdrant.ChunkIndexField])
+	if err != nil || index < 0 {
+		return chunkRef{}, false
+	}
+	for _, field := range []string{qdrant.DocumentField, qdrant.SourceField} {
+		if doc := r.Payload[field]; doc != "" {
+			return chunkRef{field: field, doc: doc, index: index}, true
+		}
+	}
+	return chunkRef{}, false
+}
+
+// expandWindow sets each result's Passage to the result stitched together
+// with up to n chunks before and after it from the same document.
+func (f *Factory) expandWindow(ctx context.Context, tenant string, results []qdrant.SearchResult, n int) error {
+	conditions, limit := windowConditions(results, n)
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	neighbours, err := f.qdrant.Scroll(ctx, f.cfg.VectorStore.Collection, tenant, qdrant.ScrollOptions{
+		Any:   conditions,
+		Limit: uint32(limit),
+	})
+	if err != nil {
+		return err
+	}
+
+	stitchWindows(results, neighbours, n)
+	return nil
+}
+
+// windowConditions builds one Scroll condition per document covering the
+// neighbours of every result in it, and the number of chunks requested.
+func windowConditions(results []qdrant.SearchResult, n int) ([]map[string][]string, int) {
+	if n <= 0 {
+		return nil, 0
+	}
+
+	type docKey struct{ field, doc string }
+	wanted := make(map[docKey]map[int]bool)
+	var order []docKey
+	for _, r := range results {
+		ref, ok := chunkOf(r)
+		if !ok {
+			continue
+		}
+		key := docKey{ref.field, ref.doc}
+		if wanted[key] == nil {
+			wanted[key] = make(map[int]bool)
+			order = append(order, key)
+		}
+		for i := max(ref.index-n, 0); i <= ref.index+n; i++ {
+			if i != ref.index {
+				wanted[key][i] = true
+			}
+		}
+	}
+
+	var conditions []map[string][]string
+	limit := 0
+	for _, key := range order {
+		indices := make([]string, 0, len(wanted[key]))
+		for i := range wanted[key] {
+			indices = append(indices, strconv.Itoa(i))
+		}
+		limit += len(indices)
+		conditions = append(conditions, map[string][]string{
+			key.field:              {key.doc},
+			qdrant.ChunkIndexField: indices,
+		})
+	}
+	return conditions, limit
+}
+
+// stitchWindows sets the Passage of each result that has neighbours. Chunks
+// are joined in document order with the splitter overlap removed; missing
+// chunks are skipped.
+func stitchWindows(results, neighbours []qdrant.SearchResult, n int) {
+	chunks := make(map[chunkRef]string, len(neighbours))
+	for _, nb := range neighbours {
+		if ref, ok := chunkOf(nb); ok {
+			chunks[ref] = nb.Content
+		}
+	}
+
+	for i, r := range results {
+		ref, ok := chunkOf(r)
+		if !ok {
+			continue
+		}
+
+		passage, found := "", false
+		for idx := max(ref.index-n, 0); idx <= ref.index+n; idx++ {
+			text := r.Content
+			if idx != ref.index {
+				var ok bool
+				if text, ok = chunks[chunkRef{ref.field, ref.doc, idx}]; !ok {
+					continue
+				}
+				found = true
+			}
+			passage = mergeOverlap(passage, text)
+		}
+		if found {
+			results[i].Passage = passage
+		}
+	}
+}
+
+// mergeOverlap appends b to a, dropping the longest prefix of b that a
+// already ends with.
+func mergeOverlap(a, b string) string {
+	if a == "" {
+		return b
+	}
+	for n := min(len(a), len(b)); n >= minOverlap; n-- {
+		if strings.HasSuffix(a, b[:n]) {
+			return a + b[n:]
+		}
+	}
+	return a + "\n" + b
+}
+
+// documentText returns the text shown to the model for a result: the
+// stitched passage when context was expanded, otherwise the chunk itself.
+func documentText(r qdrant.SearchResult) string {
+	if r.Passage != "" {
+		return r.Passage
+	}
+	return r.Content
+}
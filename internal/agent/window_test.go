@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chunk(doc, index, content string) qdrant.SearchResult {
+	return qdrant.SearchResult{
+		Content: content,
+		Payload: map[string]string{qdrant.DocumentField: doc, qdrant.ChunkIndexField: index},
+	}
+}
+
+func TestWindowConditions(t *testing.T) {
+	results := []qdrant.SearchResult{
+		chunk("doc-1", "0", "a"),
+		chunk("doc-1", "5", "b"),
+		{Content: "legacy", Payload: map[string]string{qdrant.SourceField: "notes.md", qdrant.ChunkIndexField: "2"}},
+		{Content: "no position", Payload: map[string]string{qdrant.DocumentField: "doc-2"}},
+	}
+
+	conditions, limit := windowConditions(results, 1)
+	require.Len(t, conditions, 2)
+	assert.Equal(t, []string{"doc-1"}, conditions[0][qdrant.DocumentField])
+	assert.ElementsMatch(t, []string{"1", "4", "6"}, conditions[0][qdrant.ChunkIndexField])
+	assert.Equal(t, []string{"notes.md"}, conditions[1][qdrant.SourceField])
+	assert.ElementsMatch(t, []string{"1", "3"}, conditions[1][qdrant.ChunkIndexField])
+	assert.Equal(t, 5, limit)
+
+	conditions, _ = windowConditions(results, 0)
+	assert.Empty(t, conditions)
+}
+
+func TestStitchWindows(t *testing.T) {
+	results := []qdrant.SearchResult{
+		chunk("doc-1", "1", "the server must be restarted after the upgrade."),
+		chunk("doc-2", "0", "standalone chunk"),
+	}
+	neighbours := []qdrant.SearchResult{
+		chunk("doc-1", "2", "after the upgrade. Check the logs afterwards."),
+		chunk("doc-1", "0", "Upgrading: first stop traffic, then"),
+		chunk("doc-3", "0", "unrelated"),
+	}
+
+	stitchWindows(results, neighbours, 1)
+
+	assert.Equal(t, "Upgrading: first stop traffic, then\nthe server must be restarted after the upgrade. Check the logs afterwards.", results[0].Passage)
+	assert.Empty(t, results[1].Passage, "no neighbours found")
+}
+
+func TestMergeOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "empty start", a: "", b: "text", want: "text"},
+		{name: "overlap removed", a: "alpha beta gamma delta", b: "gamma delta epsilon", want: "alpha beta gamma delta epsilon"},
+		{name: "short match ignored", a: "ends with a", b: "a start", want: "ends with a\na start"},
+		{name: "no overlap", a: "first", b: "second", want: "first\nsecond"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mergeOverlap(tt.a, tt.b))
+		})
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	assert.Equal(t, "chunk", documentText(qdrant.SearchResult{Content: "chunk"}))
+	assert.Equal(t, "stitched", documentText(qdrant.SearchResult{Content: "chunk", Passage: "stitched"}))
+}
@@ -0,0 +1,233 @@
+// Package answercache reuses chat answers for questions that are
+// semantically close to ones answered before.
+package answercache
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope partitions the cache: an answer is only reused for questions in
+// the same scope.
+type Scope struct {
+	Collection string
+	Tenant     string
+	// Filter restricts retrieval to documents with these metadata values.
+	Filter map[string]string
+	// Options identifies the other settings the answer depends on, such
+	// as retrieval overrides and the web search mode.
+	Options string
+}
+
+// key returns a canonical string for s.
+func (s Scope) key() string {
+	var b strings.Builder
+	b.WriteString(s.partition())
+	keys := make([]string, 0, len(s.Filter))
+	for k := range s.Filter {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		b.WriteString("\x00" + k + "=" + s.Filter[k])
+	}
+	b.WriteString("\x00" + s.Options)
+	return b.String()
+}
+
+// partition identifies the chunks answers in s are drawn from.
+func (s Scope) partition() string {
+	return s.Collection + "\x00" + s.Tenant
+}
+
+// Citation is a retrieved chunk an answer was given with.
+type Citation struct {
+	ChunkID    string
+	DocumentID string
+	Source     string
+}
+
+// Entry is a cached answer.
+type Entry struct {
+	Query     string
+	Answer    string
+	Citations []Citation
+	CreatedAt time.Time
+}
+
+// Hit is a cached answer matching a question.
+type Hit struct {
+	Entry
+	// Similarity is the cosine similarity between the questions.
+	Similarity float64
+}
+
+// Config configures a Cache.
+type Config struct {
+	// Threshold is the minimum cosine similarity for a hit.
+	Threshold float64
+	// TTL bounds how long an entry is reused; zero keeps entries until
+	// they are invalidated or evicted.
+	TTL time.Duration
+	// MaxEntries caps the number of entries; the oldest are dropped first.
+	MaxEntries int
+}
+
+type entry struct {
+	Entry
+	scope  string
+	vector []float32
+	norm   float64
+}
+
+// Cache holds answers keyed by question embeddings.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string][]*entry
+	order   []*entry
+	// generations counts invalidations per partition, so answers
+	// computed from chunks that changed meanwhile are not stored.
+	generations map[string]uint64
+	cfg         Config
+	now         func() time.Time
+}
+
+// New creates an empty cache.
+func New(cfg Config) *Cache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1000
+	}
+	return &Cache{
+		entries:     make(map[string][]*entry),
+		generations: make(map[string]uint64),
+		cfg:         cfg,
+		now:         time.Now,
+	}
+}
+
+// Generation returns the current generation of scope's partition. Pass it
+// to Store once the answer is computed.
+func (c *Cache) Generation(scope Scope) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[scope.partition()]
+}
+
+// Lookup returns the most similar answer in scope whose question is at
+// least Threshold similar to vector.
+func (c *Cache) Lookup(scope Scope, vector []float32) (Hit, bool) {
+	norm := vectorNorm(vector)
+	if norm == 0 {
+		return Hit{}, false
+	}
+	key := scope.key()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var best *entry
+	var bestSim float64
+	for _, e := range c.entries[key] {
+		if c.expired(e) {
+			continue
+		}
+		sim := cosine(vector, e.vector, norm, e.norm)
+		if sim >= c.cfg.Threshold && (best == nil || sim > bestSim) {
+			best, bestSim = e, sim
+		}
+	}
+	if best == nil {
+		return Hit{}, false
+	}
+	return Hit{Entry: best.Entry, Similarity: bestSim}, true
+}
+
+// Store caches an answer for the question embedded as vector. It is
+// dropped, returning false, when scope was invalidated after generation
+// was read.
+func (c *Cache) Store(scope Scope, generation uint64, vector []float32, e Entry) bool {
+	norm := vectorNorm(vector)
+	if norm == 0 {
+		return false
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = c.now().UTC()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[scope.partition()] != generation {
+		return false
+	}
+	stored := &entry{Entry: e, scope: scope.key(), vector: vector, norm: norm}
+	c.entries[stored.scope] = append(c.entries[stored.scope], stored)
+	c.order = append(c.order, stored)
+	for len(c.order) > c.cfg.MaxEntries {
+		c.removeLocked(c.order[0])
+		c.order = c.order[1:]
+	}
+	return true
+}
+
+// Invalidate drops every answer drawn from the chunks of tenant in
+// collection.
+func (c *Cache) Invalidate(collection, tenant string) {
+	partition := Scope{Collection: collection, Tenant: tenant}.partition()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[partition]++
+	c.order = slices.DeleteFunc(c.order, func(e *entry) bool {
+		if strings.HasPrefix(e.scope, partition+"\x00") {
+			delete(c.entries, e.scope)
+			return true
+		}
+		return false
+	})
+}
+
+// Len returns the number of cached answers, including expired ones not
+// yet evicted.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.order)
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return c.cfg.TTL > 0 && c.now().Sub(e.CreatedAt) > c.cfg.TTL
+}
+
+// removeLocked removes e from its scope. Callers must hold c.mu.
+func (c *Cache) removeLocked(e *entry) {
+	entries := slices.DeleteFunc(c.entries[e.scope], func(x *entry) bool { return x == e })
+	if len(entries) == 0 {
+		delete(c.entries, e.scope)
+		return
+	}
+	c.entries[e.scope] = entries
+}
+
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+func cosine(a, b []float32, normA, normB float64) float64 {
+	if len(a) == 0 || len(a) != len(b) || normA == 0 || normB == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot / (normA * normB)
+}
@@ -0,0 +1,115 @@
+package answercache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scope(tenant string) Scope {
+	return Scope{Collection: "documents", Tenant: tenant, Options: "web=disabled"}
+}
+
+func TestCache_Lookup(t *testing.T) {
+	c := New(Config{Threshold: 0.9})
+	s := scope("team-a")
+	require.True(t, c.Store(s, c.Generation(s), []float32{1, 0, 0}, Entry{
+		Query:     "How do I restart?",
+		Answer:    "Run make restart.",
+		Citations: []Citation{{ChunkID: "c1", DocumentID: "d1", Source: "ops.md"}},
+	}))
+	require.True(t, c.Store(s, c.Generation(s), []float32{0.8, 0.6, 0}, Entry{Answer: "other"}))
+
+	hit, ok := c.Lookup(s, []float32{0.99, 0.1, 0})
+	require.True(t, ok)
+	assert.Equal(t, "Run make restart.", hit.Answer)
+	assert.Equal(t, "ops.md", hit.Citations[0].Source)
+	assert.InDelta(t, 0.995, hit.Similarity, 0.001)
+	assert.False(t, hit.CreatedAt.IsZero())
+
+	_, ok = c.Lookup(s, []float32{0, 0, 1})
+	assert.False(t, ok, "below threshold")
+	_, ok = c.Lookup(s, []float32{0, 0, 0})
+	assert.False(t, ok, "zero vector")
+}
+
+func TestCache_Scopes(t *testing.T) {
+	c := New(Config{Threshold: 0.9})
+	s := scope("team-a")
+	s.Filter = map[string]string{"lang": "go", "team": "core"}
+	require.True(t, c.Store(s, 0, []float32{1, 0}, Entry{Answer: "a"}))
+
+	same := scope("team-a")
+	same.Filter = map[string]string{"team": "core", "lang": "go"}
+	_, ok := c.Lookup(same, []float32{1, 0})
+	assert.True(t, ok, "filter order does not matter")
+
+	tests := []struct {
+		name  string
+		scope Scope
+	}{
+		{name: "other tenant", scope: Scope{Collection: "documents", Tenant: "team-b", Filter: s.Filter, Options: s.Options}},
+		{name: "other collection", scope: Scope{Collection: "archive", Tenant: "team-a", Filter: s.Filter, Options: s.Options}},
+		{name: "no filter", scope: scope("team-a")},
+		{name: "other options", scope: Scope{Collection: "documents", Tenant: "team-a", Filter: s.Filter, Options: "web=always"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := c.Lookup(tt.scope, []float32{1, 0})
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	c := New(Config{Threshold: 0.9})
+	a, b := scope("team-a"), scope("team-b")
+	gen := c.Generation(a)
+	require.True(t, c.Store(a, gen, []float32{1, 0}, Entry{Answer: "a"}))
+	require.True(t, c.Store(b, c.Generation(b), []float32{1, 0}, Entry{Answer: "b"}))
+
+	c.Invalidate("documents", "team-a")
+
+	_, ok := c.Lookup(a, []float32{1, 0})
+	assert.False(t, ok)
+	_, ok = c.Lookup(b, []float32{1, 0})
+	assert.True(t, ok, "other tenants keep their answers")
+	assert.Equal(t, 1, c.Len())
+
+	// An answer computed before the upload is stale
+	assert.False(t, c.Store(a, gen, []float32{1, 0}, Entry{Answer: "stale"}))
+	assert.True(t, c.Store(a, c.Generation(a), []float32{1, 0}, Entry{Answer: "fresh"}))
+}
+
+func TestCache_TTL(t *testing.T) {
+	c := New(Config{Threshold: 0.9, TTL: time.Minute})
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	s := scope("team-a")
+	require.True(t, c.Store(s, 0, []float32{1, 0}, Entry{Answer: "a"}))
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Lookup(s, []float32{1, 0})
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Lookup(s, []float32{1, 0})
+	assert.False(t, ok)
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	c := New(Config{Threshold: 0.9, MaxEntries: 2})
+	s := scope("team-a")
+	require.True(t, c.Store(s, 0, []float32{1, 0, 0}, Entry{Answer: "first"}))
+	require.True(t, c.Store(s, 0, []float32{0, 1, 0}, Entry{Answer: "second"}))
+	require.True(t, c.Store(s, 0, []float32{0, 0, 1}, Entry{Answer: "third"}))
+
+	assert.Equal(t, 2, c.Len())
+	_, ok := c.Lookup(s, []float32{1, 0, 0})
+	assert.False(t, ok, "oldest evicted")
+	hit, ok := c.Lookup(s, []float32{0, 0, 1})
+	require.True(t, ok)
+	assert.Equal(t, "third", hit.Answer)
+}
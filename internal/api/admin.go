@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+)
+
+// CreateKeyRequest is the request body for minting an API key.
+type CreateKeyRequest struct {
+	Name string `json:"name" example:"support-frontend"`
+	// Tenant defaults to the caller's tenant. Only operators may name
+	// another tenant.
+	Tenant string   `json:"tenant,omitempty" example:"team-a"`
+	Scopes []string `json:"scopes" example:"search,chat"`
+	// ExpiresIn is the key lifetime in seconds; zero means no expiry.
+	ExpiresIn int64 `json:"expires_in,omitempty" example:"2592000"`
+}
+
+// APIKeyInfo describes a stored API key without its secret.
+type APIKeyInfo struct {
+	ID        string     `json:"id" example:"3f2a9c1d5e7b8a60"`
+	Name      string     `json:"name" example:"support-frontend"`
+	Tenant    string     `json:"tenant" example:"team-a"`
+	Scopes    []string   `json:"scopes"`
+	Static    bool       `json:"static"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateKeyResponse is the response for minting an API key.
+// The secret is only returned once.
+type CreateKeyResponse struct {
+	Key    string     `json:"key" example:"rag_3f2a9c1d5e7b8a60_..."`
+	APIKey APIKeyInfo `json:"api_key"`
+}
+
+// ListKeysResponse is the response for listing API keys.
+type ListKeysResponse struct {
+	Keys []APIKeyInfo `json:"keys"`
+}
+
+// requireKeyStore wraps a key management handler. Without API keys
+// configured there is no key store, so admins authenticated by bearer
+// token get 404 instead.
+func (s *Server) requireKeyStore(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.keys == nil {
+			s.writeError(w, http.StatusNotFound, "API key management is not enabled")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleCreateKey handles the POST /api/v1/admin/keys endpoint.
+//
+//	@Summary		Create API key
+//	@Description	Mints a new scoped API key. The secret is only returned in this response. Admins mint keys for their own tenant with at most their own scopes; operators may mint keys for any tenant.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateKeyRequest	true	"Key definition"
+//	@Success		201		{object}	CreateKeyResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/keys [post]
+func (s *Server) handleCreateKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Name field is required")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		s.writeError(w, http.StatusBadRequest, "At least one scope is required")
+		return
+	}
+	if req.ExpiresIn < 0 {
+		s.writeError(w, http.StatusBadRequest, "expires_in must not be negative")
+		return
+	}
+
+	scopes, err := parseScopes(req.Scopes)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	principal := s.principalFrom(r)
+	tenant := req.Tenant
+	if tenant == "" {
+		tenant = principal.Tenant
+	}
+	if !canManageTenant(principal, tenant) {
+		s.writeError(w, http.StatusForbidden, "Cannot create keys for another tenant")
+		return
+	}
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			s.writeError(w, http.StatusForbidden, "Cannot grant scope "+string(scope)+" the caller does not hold")
+			return
+		}
+	}
+
+	secret, key, err := s.keys.Mint(req.Name, tenant, scopes, time.Duration(req.ExpiresIn)*time.Second)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to create key: "+err.Error())
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Created API key", "key_id", key.ID, "name", key.Name, "tenant", key.Tenant)
+
+	s.writeJSON(w, http.StatusCreated, CreateKeyResponse{
+		Key:    secret,
+		APIKey: newAPIKeyInfo(*key),
+	})
+}
+
+// handleListKeys handles the GET /api/v1/admin/keys endpoint.
+//
+//	@Summary		List API keys
+//	@Description	Lists the API keys of the caller's tenant, or of every tenant for operators, including revoked and expired ones. Secrets are never returned.
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	ListKeysResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Router			/admin/keys [get]
+func (s *Server) handleListKeys(w http.ResponseWriter, r *http.Request) {
+	principal := s.principalFrom(r)
+
+	infos := []APIKeyInfo{}
+	for _, k := range s.keys.List() {
+		if canManageTenant(principal, k.Tenant) {
+			infos = append(infos, newAPIKeyInfo(k))
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, ListKeysResponse{Keys: infos})
+}
+
+// handleRevokeKey handles the DELETE /api/v1/admin/keys/{id} endpoint.
+//
+//	@Summary		Revoke API key
+//	@Description	Revokes a minted API key of the caller's tenant, or of any tenant for operators. Keys defined in configuration cannot be revoked.
+//	@Tags			admin
+//	@Param			id	path	string	true	"Key ID"
+//	@Success		204
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/admin/keys/{id} [delete]
+func (s *Server) handleRevokeKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	// Keys of other tenants are reported as missing rather than forbidden
+	// so their IDs can't be probed
+	key, err := s.keys.Get(id)
+	if err == nil && !canManageTenant(s.principalFrom(r), key.Tenant) {
+		err = auth.ErrKeyNotFound
+	}
+	if err == nil {
+		err = s.keys.Revoke(id)
+	}
+	switch {
+	case errors.Is(err, auth.ErrKeyNotFound):
+		s.writeError(w, http.StatusNotFound, "API key not found")
+		return
+	case errors.Is(err, auth.ErrStaticKey):
+		s.writeError(w, http.StatusConflict, "API key is defined in configuration and cannot be revoked")
+		return
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, "Failed to revoke key: "+err.Error())
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Revoked API key", "key_id", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// canManageTenant reports whether p may manage the keys and feedback of
+// tenant: admins only their own, operators every tenant.
+func canManageTenant(p *auth.Principal, tenant string) bool {
+	return p.Tenant == tenant || p.HasScope(auth.ScopeOperator)
+}
+
+func newAPIKeyInfo(k auth.APIKey) APIKeyInfo {
+	scopes := make([]string, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return APIKeyInfo{
+		ID:        k.ID,
+		Name:      k.Name,
+		Tenant:    k.Tenant,
+		Scopes:    scopes,
+		Static:    k.Static(),
+		CreatedAt: k.CreatedAt,
+		ExpiresAt: k.ExpiresAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
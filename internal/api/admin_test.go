@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminTestServer returns a server with routes registered and
+// "admin-key" configured as the static admin key.
+func newAdminTestServer(t *testing.T) *Server {
+	t.Helper()
+	keys := newTestKeys(t, "admin-key")
+	s := &Server{
+		cfg:        &config.Config{},
+		mux:        http.NewServeMux(),
+		keys:       keys,
+		middleware: newMiddleware(keys, nil, "", rateLimitConfig{}),
+		apiVersion: "v1",
+	}
+	s.registerRoutes()
+	return s
+}
+
+func doAdminRequest(t *testing.T, s *Server, method, path, key string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewKeyStore_Disabled(t *testing.T) {
+	keys, err := newKeyStore(config.ServerConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, keys)
+}
+
+func TestNewKeyStore_KeysFileOnly(t *testing.T) {
+	keys, err := newKeyStore(config.ServerConfig{KeysFile: t.TempDir() + "/keys.json"})
+	require.NoError(t, err)
+	require.NotNil(t, keys)
+	assert.Equal(t, 0, keys.Len())
+}
+
+func TestAdminKeys_Lifecycle(t *testing.T) {
+	s := newAdminTestServer(t)
+
+	w := doAdminRequest(t, s, "POST", "/api/v1/admin/keys", "admin-key", CreateKeyRequest{
+		Name:      "frontend",
+		Tenant:    "team-a",
+		Scopes:    []string{"search", "chat"},
+		ExpiresIn: 3600,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created CreateKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Key)
+	assert.Equal(t, "team-a", created.APIKey.Tenant)
+	assert.Equal(t, []string{"search", "chat"}, created.APIKey.Scopes)
+	assert.NotNil(t, created.APIKey.ExpiresAt)
+
+	// The new key works for its scopes but is not an admin
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/keys", created.Key, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/keys", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed ListKeysResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Keys, 2)
+	assert.NotContains(t, w.Body.String(), created.Key)
+
+	w = doAdminRequest(t, s, "DELETE", "/api/v1/admin/keys/"+created.APIKey.ID, "admin-key", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/keys", created.Key, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminKeys_CreateValidation(t *testing.T) {
+	s := newAdminTestServer(t)
+
+	tests := []struct {
+		name string
+		body any
+	}{
+		{name: "missing name", body: CreateKeyRequest{Scopes: []string{"chat"}}},
+		{name: "missing scopes", body: CreateKeyRequest{Name: "x"}},
+		{name: "unknown scope", body: CreateKeyRequest{Name: "x", Scopes: []string{"root"}}},
+		{name: "negative expiry", body: CreateKeyRequest{Name: "x", Scopes: []string{"chat"}, ExpiresIn: -1}},
+		{name: "invalid json", body: "not an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doAdminRequest(t, s, "POST", "/api/v1/admin/keys", "admin-key", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestAdminKeys_CreateDefaultsToCallerTenant(t *testing.T) {
+	s := newAdminTestServer(t)
+
+	w := doAdminRequest(t, s, "POST", "/api/v1/admin/keys", "admin-key", CreateKeyRequest{
+		Name:   "ingest-job",
+		Scopes: []string{"ingest"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created CreateKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, auth.DefaultTenant, created.APIKey.Tenant)
+	assert.Nil(t, created.APIKey.ExpiresAt)
+}
+
+func TestAdminKeys_RevokeErrors(t *testing.T) {
+	s := newAdminTestServer(t)
+
+	w := doAdminRequest(t, s, "DELETE", "/api/v1/admin/keys/missing", "admin-key", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	static := s.keys.List()[0]
+	w = doAdminRequest(t, s, "DELETE", "/api/v1/admin/keys/"+static.ID, "admin-key", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAdminKeys_TenantAdmin(t *testing.T) {
+	s := newAdminTestServer(t)
+	mint := func(key string, body CreateKeyRequest) *httptest.ResponseRecorder {
+		return doAdminRequest(t, s, "POST", "/api/v1/admin/keys", key, body)
+	}
+
+	// The operator key mints an admin for team-a and a key for team-b
+	w := mint("admin-key", CreateKeyRequest{Name: "team-a-admin", Tenant: "team-a", Scopes: []string{"admin"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var teamAdmin CreateKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &teamAdmin))
+	w = mint("admin-key", CreateKeyRequest{Name: "team-b-bot", Tenant: "team-b", Scopes: []string{"chat"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var teamB CreateKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &teamB))
+
+	admin := teamAdmin.Key
+	assert.Equal(t, http.StatusCreated, mint(admin, CreateKeyRequest{Name: "bot", Scopes: []string{"chat"}}).Code)
+	assert.Equal(t, http.StatusCreated, mint(admin, CreateKeyRequest{Name: "bot", Tenant: "team-a", Scopes: []string{"admin"}}).Code)
+	assert.Equal(t, http.StatusForbidden, mint(admin, CreateKeyRequest{Name: "bot", Tenant: "team-b", Scopes: []string{"chat"}}).Code)
+	assert.Equal(t, http.StatusForbidden, mint(admin, CreateKeyRequest{Name: "bot", Scopes: []string{"operator"}}).Code)
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/keys", admin, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed ListKeysResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Keys, 3)
+	for _, k := range listed.Keys {
+		assert.Equal(t, "team-a", k.Tenant)
+	}
+
+	w = doAdminRequest(t, s, "DELETE", "/api/v1/admin/keys/"+teamB.APIKey.ID, admin, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "other tenants' keys are hidden")
+	key, err := s.keys.Get(teamB.APIKey.ID)
+	require.NoError(t, err)
+	assert.Nil(t, key.RevokedAt)
+
+	w = doAdminRequest(t, s, "DELETE", "/api/v1/admin/keys/"+teamB.APIKey.ID, "admin-key", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAdminKeys_DisabledAuth(t *testing.T) {
+	s := &Server{
+		cfg:        &config.Config{},
+		mux:        http.NewServeMux(),
+		middleware: newMiddleware(nil, nil, "", rateLimitConfig{}),
+		apiVersion: "v1",
+	}
+	s.registerRoutes()
+
+	w := doAdminRequest(t, s, "GET", "/api/v1/admin/keys", "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAdminKeys_JWTOnly(t *testing.T) {
+	path, sign := newTestJWKS(t)
+	verifier, err := newJWTVerifier(config.ServerConfig{JWT: config.JWTConfig{
+		JWKSFile:    path,
+		UserClaim:   "sub",
+		TenantClaim: "tenant",
+		RolesClaim:  "roles",
+		RoleScopes:  map[string][]string{"ops": {"admin"}},
+	}})
+	require.NoError(t, err)
+	s := &Server{
+		cfg:        &config.Config{},
+		mux:        http.NewServeMux(),
+		middleware: newMiddleware(nil, verifier, "", rateLimitConfig{}),
+		apiVersion: "v1",
+	}
+	s.registerRoutes()
+
+	token := sign(map[string]any{
+		"sub":    "alice",
+		"tenant": "team-a",
+		"roles":  []string{"ops"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	})
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: "POST", path: "/api/v1/admin/keys", body: `{"name": "ci", "scopes": ["search"]}`},
+		{method: "GET", path: "/api/v1/admin/keys"},
+		{method: "DELETE", path: "/api/v1/admin/keys/abc"},
+	}
+	for _, tt := range requests {
+		t.Run(tt.method, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/answercache"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/feedback"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+
+	"github.com/google/uuid"
+)
+
+// Citation is a retrieved chunk a chat response was given with.
+type Citation struct {
+	ChunkID    string `json:"chunk_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	DocumentID string `json:"document_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Source     string `json:"source,omitempty" example:"upgrade.md"`
+}
+
+// queryEmbedder embeds chat questions for answer cache lookups.
+type queryEmbedder interface {
+	EmbedQuery(ctx context.Context, query string) ([]float32, error)
+}
+
+// answerLookup is a missed cache lookup, kept to store the answer once
+// it is computed.
+type answerLookup struct {
+	scope      answercache.Scope
+	generation uint64
+	vector     []float32
+}
+
+// queryVector returns the embedded question, or nil without a lookup.
+func (l *answerLookup) queryVector() []float32 {
+	if l == nil {
+		return nil
+	}
+	return l.vector
+}
+
+// newAnswerCache builds the answer cache from configuration, or returns
+// nil when it is disabled.
+func newAnswerCache(cfg config.AnswerCacheConfig) *answercache.Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return answercache.New(answercache.Config{
+		Threshold:  cfg.Threshold,
+		TTL:        time.Duration(cfg.TTL) * time.Second,
+		MaxEntries: cfg.MaxEntries,
+	})
+}
+
+// answerScope returns the cache scope of a chat request: the collection,
+// the tenant and every setting the answer depends on.
+func (s *Server) answerScope(tenant string, req ChatRequest, mode websearch.Mode) answercache.Scope {
+	return answercache.Scope{
+		Collection: s.cfg.VectorStore.Collection,
+		Tenant:     tenant,
+		Options: fmt.Sprintf("rerank=%s expand=%s mmr=%s context_window=%s web_search=%s",
+			override(req.Rerank), override(req.Expand), override(req.MMR), override(req.ContextWindow), mode),
+	}
+}
+
+// override formats a per-request override for a cache scope.
+func override(b *bool) string {
+	if b == nil {
+		return "default"
+	}
+	return fmt.Sprint(*b)
+}
+
+// lookupAnswer returns a cached answer to the chat request. On a miss it
+// returns the lookup to store the computed answer with; both are nil when
+// the cache is disabled or the question could not be embedded.
+func (s *Server) lookupAnswer(ctx context.Context, tenant string, req ChatRequest, mode websearch.Mode) (*answercache.Hit, *answerLookup) {
+	if s.answers == nil {
+		return nil, nil
+	}
+
+	scope := s.answerScope(tenant, req, mode)
+	// Read before retrieval so uploads during the run invalidate the answer
+	generation := s.answers.Generation(scope)
+	vector, err := s.queryEmbedder.EmbedQuery(ctx, req.Message)
+	if err != nil {
+		slog.WarnContext(ctx, "Embedding question for answer cache failed", "error", err)
+		return nil, nil
+	}
+	if hit, ok := s.answers.Lookup(scope, vector); ok {
+		return &hit, nil
+	}
+	return nil, &answerLookup{scope: scope, generation: generation, vector: vector}
+}
+
+// storeAnswer caches a computed answer for a missed lookup.
+func (s *Server) storeAnswer(ctx context.Context, l *answerLookup, query, answer string, citations []answercache.Citation) {
+	if l == nil || answer == "" {
+		return
+	}
+	stored := s.answers.Store(l.scope, l.generation, l.vector, answercache.Entry{
+		Query:     query,
+		Answer:    answer,
+		Citations: citations,
+	})
+	if !stored {
+		slog.DebugContext(ctx, "Answer not cached, documents changed meanwhile", "tenant", l.scope.Tenant)
+	}
+}
+
+// invalidateAnswers drops the cached answers of tenant after its chunks
+// changed.
+func (s *Server) invalidateAnswers(tenant string) {
+	if s.answers != nil {
+		s.answers.Invalidate(s.cfg.VectorStore.Collection, tenant)
+	}
+}
+
+// writeCachedAnswer responds to a chat question with a cached answer and
+// records the turn in the session.
+func (s *Server) writeCachedAnswer(ctx context.Context, w http.ResponseWriter, tenant, userID string, sess session.Session, question string, mode websearch.Mode, hit *answercache.Hit) {
+	if err := s.appendCachedTurn(ctx, s.agentFactory.SessionService(), sess, question, hit.Answer); err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to update session: "+err.Error())
+		return
+	}
+
+	resp := ChatResponse{
+		Response:  hit.Answer,
+		SessionID: sess.ID(),
+		MessageID: uuid.New().String(),
+		WebSearch: string(mode),
+		Citations: newAPICitations(hit.Citations),
+		Cached:    true,
+	}
+	slog.InfoContext(ctx, "Answered from cache",
+		"tenant", tenant,
+		"similarity", hit.Similarity,
+		"cached_at", hit.CreatedAt,
+	)
+
+	s.recordInteraction(ctx, feedback.Interaction{
+		ID:        resp.MessageID,
+		SessionID: resp.SessionID,
+		Tenant:    tenant,
+		UserID:    userID,
+		Query:     question,
+		Answer:    hit.Answer,
+	}, hit.Citations)
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// appendCachedTurn adds a cached answer to the session history, so
+// follow-up questions see it like an answer from the agent.
+func (s *Server) appendCachedTurn(ctx context.Context, sessions session.Service, sess session.Session, question, answer string) error {
+	invocationID := "e-" + uuid.NewString()
+
+	user := session.NewEvent(invocationID)
+	user.Author = "user"
+	user.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(question, genai.RoleUser)}
+
+	reply := session.NewEvent(invocationID)
+	reply.Author = s.cfg.Agent.Name
+	reply.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(answer, genai.RoleModel)}
+
+	for _, event := range []*session.Event{user, reply} {
+		if err := sessions.AppendEvent(ctx, sess, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCitations lists the retrieved documents an answer was given with.
+func newCitations(documents []qdrant.SearchResult) []answercache.Citation {
+	if len(documents) == 0 {
+		return nil
+	}
+	out := make([]answercache.Citation, len(documents))
+	for i, doc := range documents {
+		out[i] = answercache.Citation{
+			ChunkID:    doc.ID,
+			DocumentID: doc.Payload[qdrant.DocumentField],
+			Source:     doc.Payload[qdrant.SourceField],
+		}
+	}
+	return out
+}
+
+func newAPICitations(citations []answercache.Citation) []Citation {
+	if len(citations) == 0 {
+		return nil
+	}
+	out := make([]Citation, len(citations))
+	for i, c := range citations {
+		out[i] = Citation{ChunkID: c.ChunkID, DocumentID: c.DocumentID, Source: c.Source}
+	}
+	return out
+}
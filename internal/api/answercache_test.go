@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ragagent "github.com/mfmezger/agentic_rag_go/internal/agent"
+	"github.com/mfmezger/agentic_rag_go/internal/answercache"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/session"
+)
+
+func newAnswerCacheTestServer(embedder *mocks.MockEmbeddingService) *Server {
+	cfg := &config.Config{
+		VectorStore: config.VectorStoreConfig{Collection: "documents"},
+		Agent:       config.AgentConfig{Name: "rag_agent"},
+		AnswerCache: config.AnswerCacheConfig{Enabled: true, Threshold: 0.9, MaxEntries: 10},
+	}
+	return &Server{cfg: cfg, queryEmbedder: embedder, answers: newAnswerCache(cfg.AnswerCache)}
+}
+
+func TestNewAnswerCache_Disabled(t *testing.T) {
+	assert.Nil(t, newAnswerCache(config.AnswerCacheConfig{Threshold: 0.9}))
+}
+
+func TestAnswerScope(t *testing.T) {
+	s := newAnswerCacheTestServer(nil)
+	yes := true
+	base := s.answerScope("team-a", ChatRequest{Message: "q"}, websearch.ModeDisabled)
+	assert.Equal(t, "documents", base.Collection)
+	assert.Equal(t, "team-a", base.Tenant)
+
+	assert.Equal(t, base, s.answerScope("team-a", ChatRequest{Message: "other question"}, websearch.ModeDisabled))
+	assert.NotEqual(t, base, s.answerScope("team-a", ChatRequest{Message: "q", Rerank: &yes}, websearch.ModeDisabled))
+	assert.NotEqual(t, base, s.answerScope("team-a", ChatRequest{Message: "q"}, websearch.ModeFallback))
+	assert.NotEqual(t, base, s.answerScope("team-b", ChatRequest{Message: "q"}, websearch.ModeDisabled))
+}
+
+func TestLookupAnswer(t *testing.T) {
+	ctx := context.Background()
+	embedder := &mocks.MockEmbeddingService{}
+	embedder.On("EmbedQuery", mock.Anything, "How do I restart?").Return([]float32{1, 0}, nil)
+	embedder.On("EmbedQuery", mock.Anything, "How to restart the server?").Return([]float32{0.98, 0.2}, nil)
+	s := newAnswerCacheTestServer(embedder)
+	req := ChatRequest{Message: "How do I restart?"}
+
+	hit, lookup := s.lookupAnswer(ctx, "team-a", req, websearch.ModeDisabled)
+	assert.Nil(t, hit)
+	require.NotNil(t, lookup)
+	assert.Equal(t, []float32{1, 0}, lookup.queryVector())
+
+	s.storeAnswer(ctx, lookup, req.Message, "Run make restart.", newCitations([]qdrant.SearchResult{
+		{ID: "c1", Payload: map[string]string{qdrant.DocumentField: "d1", qdrant.SourceField: "ops.md"}},
+	}))
+
+	hit, lookup = s.lookupAnswer(ctx, "team-a", ChatRequest{Message: "How to restart the server?"}, websearch.ModeDisabled)
+	require.NotNil(t, hit)
+	assert.Nil(t, lookup)
+	assert.Equal(t, "Run make restart.", hit.Answer)
+	assert.Equal(t, []Citation{{ChunkID: "c1", DocumentID: "d1", Source: "ops.md"}}, newAPICitations(hit.Citations))
+
+	hit, _ = s.lookupAnswer(ctx, "team-b", req, websearch.ModeDisabled)
+	assert.Nil(t, hit, "other tenants do not share answers")
+
+	s.invalidateAnswers("team-a")
+	hit, _ = s.lookupAnswer(ctx, "team-a", req, websearch.ModeDisabled)
+	assert.Nil(t, hit)
+}
+
+func TestLookupAnswer_UploadDuringRun(t *testing.T) {
+	ctx := context.Background()
+	embedder := &mocks.MockEmbeddingService{}
+	embedder.On("EmbedQuery", mock.Anything, mock.Anything).Return([]float32{1, 0}, nil)
+	s := newAnswerCacheTestServer(embedder)
+	req := ChatRequest{Message: "How do I restart?"}
+
+	_, lookup := s.lookupAnswer(ctx, "team-a", req, websearch.ModeDisabled)
+	require.NotNil(t, lookup)
+	s.invalidateAnswers("team-a")
+	s.storeAnswer(ctx, lookup, req.Message, "Outdated answer.", nil)
+
+	hit, _ := s.lookupAnswer(ctx, "team-a", req, websearch.ModeDisabled)
+	assert.Nil(t, hit)
+}
+
+func TestLookupAnswer_Unavailable(t *testing.T) {
+	ctx := context.Background()
+
+	// Disabled cache
+	hit, lookup := (&Server{}).lookupAnswer(ctx, "team-a", ChatRequest{Message: "q"}, websearch.ModeDisabled)
+	assert.Nil(t, hit)
+	assert.Nil(t, lookup)
+	assert.Nil(t, lookup.queryVector())
+
+	embedder := &mocks.MockEmbeddingService{}
+	embedder.On("EmbedQuery", mock.Anything, mock.Anything).Return(nil, errors.New("quota exceeded"))
+	s := newAnswerCacheTestServer(embedder)
+	hit, lookup = s.lookupAnswer(ctx, "team-a", ChatRequest{Message: "q"}, websearch.ModeDisabled)
+	assert.Nil(t, hit)
+	assert.Nil(t, lookup)
+
+	// Nothing to store without a lookup
+	s.storeAnswer(ctx, nil, "q", "a", nil)
+	assert.Zero(t, s.answers.Len())
+}
+
+func TestAppendCachedTurn(t *testing.T) {
+	ctx := context.Background()
+	s := newAnswerCacheTestServer(nil)
+	sessions := session.InMemoryService()
+	created, err := sessions.Create(ctx, &session.CreateRequest{AppName: appName, UserID: "user1"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.appendCachedTurn(ctx, sessions, created.Session, "How do I restart?", "Run make restart."))
+
+	got, err := sessions.Get(ctx, &session.GetRequest{AppName: appName, UserID: "user1", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	assert.Equal(t, []ragagent.Turn{
+		{Role: "user", Text: "How do I restart?"},
+		{Role: "model", Text: "Run make restart."},
+	}, ragagent.HistoryFromEvents(got.Session.Events()))
+}
+
+func TestNewCitations(t *testing.T) {
+	assert.Nil(t, newCitations(nil))
+	assert.Nil(t, newAPICitations(nil))
+	assert.Equal(t, []answercache.Citation{{ChunkID: "c1"}}, newCitations([]qdrant.SearchResult{{ID: "c1"}}))
+}
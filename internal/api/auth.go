@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+)
+
+// newKeyStore builds the API key store from server configuration.
+// It returns nil when no key source is configured, which disables
+// authentication. The legacy api_key becomes an admin key for the default
+// tenant and each tenant key gets the regular user scopes.
+func newKeyStore(cfg config.ServerConfig) (*auth.KeyStore, error) {
+	if cfg.APIKey == "" && len(cfg.TenantKeys) == 0 && cfg.KeysFile == "" {
+		return nil, nil
+	}
+
+	keys, err := auth.NewKeyStore(cfg.KeysFile)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultTenant := cfg.DefaultTenant
+	if defaultTenant == "" {
+		defaultTenant = auth.DefaultTenant
+	}
+	if cfg.APIKey != "" {
+		keys.AddStatic(cfg.APIKey, "config:api_key", defaultTenant, auth.AllScopes)
+	}
+	for key, tenant := range cfg.TenantKeys {
+		keys.AddStatic(key, "config:tenant_keys:"+tenant, tenant, auth.UserScopes)
+	}
+
+	return keys, nil
+}
+
+// newJWTVerifier builds the bearer token verifier from server configuration.
+// It returns nil when no JWKS source is configured.
+func newJWTVerifier(cfg config.ServerConfig) (*auth.JWTVerifier, error) {
+	jwt := cfg.JWT
+	if jwt.JWKSURL == "" && jwt.JWKSFile == "" {
+		return nil, nil
+	}
+
+	defaultScopes, err := parseScopes(jwt.DefaultScopes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt default_scopes: %w", err)
+	}
+
+	roleScopes := make(map[string][]auth.Scope, len(jwt.RoleScopes))
+	for role, names := range jwt.RoleScopes {
+		scopes, err := parseScopes(names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwt role_scopes for %q: %w", role, err)
+		}
+		roleScopes[role] = scopes
+	}
+
+	return auth.NewJWTVerifier(auth.JWTConfig{
+		JWKSURL:       jwt.JWKSURL,
+		JWKSFile:      jwt.JWKSFile,
+		CacheTTL:      time.Duration(jwt.CacheTTL) * time.Second,
+		Issuer:        jwt.Issuer,
+		Audience:      jwt.Audience,
+		Leeway:        time.Duration(jwt.Leeway) * time.Second,
+		UserClaim:     jwt.UserClaim,
+		TenantClaim:   jwt.TenantClaim,
+		RolesClaim:    jwt.RolesClaim,
+		DefaultTenant: cfg.DefaultTenant,
+		DefaultScopes: defaultScopes,
+		RoleScopes:    roleScopes,
+	})
+}
+
+func parseScopes(names []string) ([]auth.Scope, error) {
+	scopes := make([]auth.Scope, len(names))
+	for i, name := range names {
+		scope, err := auth.ParseScope(name)
+		if err != nil {
+			return nil, err
+		}
+		scopes[i] = scope
+	}
+	return scopes, nil
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestJWKS writes a single-key JWKS file and returns its path and a
+// function signing RS256 tokens with the matching private key.
+func newTestJWKS(t *testing.T) (string, func(claims map[string]any) string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	enc := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test",
+		"n":   enc(key.N.Bytes()),
+		"e":   enc(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	sign := func(claims map[string]any) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test"})
+		payload, _ := json.Marshal(claims)
+		input := enc(header) + "." + enc(payload)
+		digest := sha256.Sum256([]byte(input))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return input + "." + enc(sig)
+	}
+
+	return path, sign
+}
+
+func TestNewJWTVerifier_Disabled(t *testing.T) {
+	v, err := newJWTVerifier(config.ServerConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestNewJWTVerifier_InvalidScopes(t *testing.T) {
+	_, err := newJWTVerifier(config.ServerConfig{JWT: config.JWTConfig{
+		JWKSFile:      "jwks.json",
+		DefaultScopes: []string{"everything"},
+	}})
+	assert.Error(t, err)
+
+	_, err = newJWTVerifier(config.ServerConfig{JWT: config.JWTConfig{
+		JWKSFile:   "jwks.json",
+		RoleScopes: map[string][]string{"ops": {"root"}},
+	}})
+	assert.Error(t, err)
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{header: "Bearer abc.def.ghi", want: "abc.def.ghi", ok: true},
+		{header: "bearer token", want: "token", ok: true},
+		{header: "Basic dXNlcjpwYXNz"},
+		{header: "Bearer "},
+		{header: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", tt.header)
+			got, ok := bearerToken(req)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMiddlewareAuth_BearerToken(t *testing.T) {
+	path, sign := newTestJWKS(t)
+	verifier, err := newJWTVerifier(config.ServerConfig{JWT: config.JWTConfig{
+		JWKSFile:      path,
+		UserClaim:     "sub",
+		TenantClaim:   "tenant",
+		RolesClaim:    "roles",
+		DefaultScopes: []string{"search", "chat"},
+	}})
+	require.NoError(t, err)
+
+	m := newMiddleware(newTestKeys(t, "admin-key"), verifier, "", rateLimitConfig{})
+
+	var principal *auth.Principal
+	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	token := sign(map[string]any{
+		"sub":    "alice",
+		"tenant": "team-a",
+		"roles":  []string{"analyst"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, principal)
+	assert.Equal(t, "alice", principal.Subject)
+	assert.Equal(t, "team-a", principal.Tenant)
+	assert.Equal(t, []string{"analyst"}, principal.Roles)
+	assert.Equal(t, "team-a/alice", principal.SessionUserID("spoofed-user"))
+
+	// API keys keep working alongside bearer tokens
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-API-Key", "admin-key")
+	w = httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// An invalid bearer token is rejected even with a valid API key
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer not.a.token")
+	req.Header.Set("X-API-Key", "admin-key")
+	w = httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestMiddlewareAuth_BearerWithoutVerifier(t *testing.T) {
+	m := newMiddleware(newTestKeys(t, "admin-key"), nil, "", rateLimitConfig{})
+	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer some.jwt.token")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
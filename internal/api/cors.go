@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+)
+
+// corsPolicy applies the configured cross-origin resource sharing rules.
+type corsPolicy struct {
+	allowAllOrigins  bool
+	origins          map[string]bool
+	wildcards        []originPattern
+	methods          string
+	allowAllHeaders  bool
+	headers          string
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// originPattern matches origins with a single "*", e.g. "https://*.example.com".
+type originPattern struct {
+	prefix, suffix string
+}
+
+func (p originPattern) match(origin string) bool {
+	return len(origin) > len(p.prefix)+len(p.suffix) &&
+		strings.HasPrefix(origin, p.prefix) &&
+		strings.HasSuffix(origin, p.suffix)
+}
+
+// errCORSWildcardCredentials rejects credentialed requests from any origin,
+// which would let every website act with the caller's credentials.
+var errCORSWildcardCredentials = errors.New(`cors: allow_credentials requires explicit allowed_origins, not "*"`)
+
+// newCORSPolicy builds the CORS policy from configuration.
+// Origins, methods and headers are matched case-insensitively.
+func newCORSPolicy(cfg config.CORSConfig) (*corsPolicy, error) {
+	p := &corsPolicy{
+		origins:          make(map[string]bool),
+		methods:          strings.ToUpper(strings.Join(cfg.AllowedMethods, ", ")),
+		headers:          strings.Join(cfg.AllowedHeaders, ", "),
+		exposedHeaders:   strings.Join(cfg.ExposedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			p.allowAllOrigins = true
+		case strings.Count(origin, "*") == 1:
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.wildcards = append(p.wildcards, originPattern{prefix: prefix, suffix: suffix})
+		case origin != "":
+			p.origins[origin] = true
+		}
+	}
+
+	if p.allowAllOrigins && p.allowCredentials {
+		return nil, errCORSWildcardCredentials
+	}
+
+	for _, header := range cfg.AllowedHeaders {
+		if strings.TrimSpace(header) == "*" {
+			p.allowAllHeaders = true
+		}
+	}
+
+	return p, nil
+}
+
+// allowOrigin reports whether requests from origin are allowed.
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAllOrigins {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		if w.match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// handler wraps next with the policy. A nil policy adds no CORS headers.
+// Preflight requests are answered directly and never reach next.
+func (p *corsPolicy) handler(next http.Handler) http.Handler {
+	if p == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" || !p.allowOrigin(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p.allowAllOrigins {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.allowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if p.exposedHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", p.exposedHeaders)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Set("Access-Control-Allow-Methods", p.methods)
+		if p.allowAllHeaders {
+			if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else if p.headers != "" {
+			h.Set("Access-Control-Allow-Headers", p.headers)
+		}
+		if p.maxAge != "" {
+			h.Set("Access-Control-Max-Age", p.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCORSTestHandler(t *testing.T, cfg config.CORSConfig) http.Handler {
+	t.Helper()
+	p, err := newCORSPolicy(cfg)
+	require.NoError(t, err)
+	return p.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCORS_AllowedOrigins(t *testing.T) {
+	handler := newCORSTestHandler(t, config.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		ExposedHeaders: []string{"X-Request-ID"},
+	})
+
+	tests := []struct {
+		origin string
+		want   string
+	}{
+		{origin: "https://app.example.com", want: "https://app.example.com"},
+		{origin: "HTTPS://APP.EXAMPLE.COM", want: "HTTPS://APP.EXAMPLE.COM"},
+		{origin: "https://team.example.org", want: "https://team.example.org"},
+		{origin: "https://.example.org"},
+		{origin: "https://evil.com"},
+		{origin: "https://app.example.com.evil.com"},
+		{origin: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			// Disallowed origins still reach the handler; the browser blocks them
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "Origin", w.Header().Get("Vary"))
+			if tt.want != "" {
+				assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+			}
+		})
+	}
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	handler := newCORSTestHandler(t, config.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"get", "post"},
+		AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+		MaxAge:         300,
+	})
+
+	req := httptest.NewRequest("OPTIONS", "/api/v1/chat", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "x-api-key")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, X-API-Key", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "300", w.Header().Get("Access-Control-Max-Age"))
+
+	// Preflights from other origins are answered without CORS headers
+	req.Header.Set("Origin", "https://evil.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORS_PlainOptionsReachesHandler(t *testing.T) {
+	handler := newCORSTestHandler(t, config.CORSConfig{AllowedOrigins: []string{"*"}})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCORS_Credentials(t *testing.T) {
+	_, err := newCORSPolicy(config.CORSConfig{AllowedOrigins: []string{"https://app.example.com", " * "}, AllowCredentials: true})
+	assert.ErrorIs(t, err, errCORSWildcardCredentials, "any website could send credentialed requests")
+
+	handler := newCORSTestHandler(t, config.CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedHeaders:   []string{"*"},
+		AllowCredentials: true,
+	})
+
+	req := httptest.NewRequest("OPTIONS", "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "authorization, x-custom")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	// A wildcard is never sent with credentials; the origin is echoed
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "authorization, x-custom", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_NilPolicy(t *testing.T) {
+	var p *corsPolicy
+	handler := p.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mfmezger/agentic_rag_go/internal/answercache"
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/feedback"
+)
+
+// maxFeedbackText caps the comment and corrected answer, in characters.
+const maxFeedbackText = 10000
+
+// FeedbackRequest is the request body for feedback on a chat answer.
+type FeedbackRequest struct {
+	SessionID string `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// MessageID is the message_id of the chat response.
+	MessageID string `json:"message_id" example:"9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"`
+	Rating    string `json:"rating" enums:"up,down" example:"down"`
+	Comment   string `json:"comment,omitempty" example:"This describes the old version."`
+	// CorrectedAnswer is the answer the user expected.
+	CorrectedAnswer string `json:"corrected_answer,omitempty" example:"Restart the server after upgrading to 2.0."`
+}
+
+// FeedbackResponse is the response for feedback on a chat answer.
+type FeedbackResponse struct {
+	MessageID   string    `json:"message_id" example:"9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"`
+	Rating      string    `json:"rating" example:"down"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// FeedbackRecord is a rated chat answer as exported. The retrieved chunks
+// and sources are what the pipeline returned, not relevance judgements.
+type FeedbackRecord struct {
+	ID             string `json:"id" example:"9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"`
+	SessionID      string `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Tenant         string `json:"tenant" example:"team-a"`
+	UserID         string `json:"user_id,omitempty" example:"user123"`
+	Query          string `json:"query" example:"Do I need to restart after upgrading?"`
+	RetrievalQuery string `json:"retrieval_query,omitempty"`
+	// ChunkIDs lists the retrieved chunks in rank order.
+	ChunkIDs         []string  `json:"chunk_ids"`
+	RetrievedSources []string  `json:"retrieved_sources,omitempty" example:"upgrade.md"`
+	Answer           string    `json:"answer"`
+	Rating           string    `json:"rating" example:"down"`
+	Comment          string    `json:"comment,omitempty"`
+	CorrectedAnswer  string    `json:"corrected_answer,omitempty"`
+	Account          string    `json:"account,omitempty" example:"key:3f2a9c1d5e7b8a60"`
+	CreatedAt        time.Time `json:"created_at"`
+	SubmittedAt      time.Time `json:"submitted_at"`
+}
+
+// newFeedbackStore builds the feedback store from configuration.
+func newFeedbackStore(cfg config.FeedbackConfig) (*feedback.Store, error) {
+	return feedback.NewStore(feedback.Config{Path: cfg.File, MaxPending: cfg.MaxPending})
+}
+
+// recordInteraction keeps a chat answer so feedback can be given on it.
+func (s *Server) recordInteraction(ctx context.Context, i feedback.Interaction, citations []answercache.Citation) {
+	if s.feedback == nil {
+		return
+	}
+
+	i.ChunkIDs = make([]string, len(citations))
+	seen := make(map[string]bool)
+	for n, c := range citations {
+		i.ChunkIDs[n] = c.ChunkID
+		if c.Source != "" && !seen[c.Source] {
+			seen[c.Source] = true
+			i.RetrievedSources = append(i.RetrievedSources, c.Source)
+		}
+	}
+	s.feedback.Add(i)
+	slog.DebugContext(ctx, "Recorded answer for feedback", "message_id", i.ID, "chunks", len(i.ChunkIDs))
+}
+
+// handleFeedback handles the POST /api/v1/feedback endpoint.
+//
+//	@Summary		Rate a chat answer
+//	@Description	Records a rating, comment and optional corrected answer for a chat response, identified by its session and message ID. Submitting again replaces earlier feedback.
+//	@Tags			chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		FeedbackRequest	true	"Feedback"
+//	@Success		200		{object}	FeedbackResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/feedback [post]
+func (s *Server) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	var req FeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.SessionID == "" || req.MessageID == "" {
+		s.writeError(w, http.StatusBadRequest, "session_id and message_id are required")
+		return
+	}
+	rating, err := feedback.ParseRating(req.Rating)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid rating: "+err.Error())
+		return
+	}
+	if utf8.RuneCountInString(req.Comment) > maxFeedbackText || utf8.RuneCountInString(req.CorrectedAnswer) > maxFeedbackText {
+		s.writeError(w, http.StatusBadRequest, "comment and corrected_answer must not exceed 10000 characters")
+		return
+	}
+
+	principal := s.principalFrom(r)
+	record, err := s.feedback.Submit(principal.Tenant, req.SessionID, req.MessageID, feedback.Feedback{
+		Rating:          rating,
+		Comment:         req.Comment,
+		CorrectedAnswer: req.CorrectedAnswer,
+		Account:         principal.AccountID(),
+	})
+	if errors.Is(err, feedback.ErrNotFound) {
+		s.writeError(w, http.StatusNotFound, "Message not found")
+		return
+	}
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to record feedback: "+err.Error())
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Recorded feedback",
+		"message_id", record.ID,
+		"rating", record.Rating,
+		"tenant", principal.Tenant,
+	)
+
+	s.writeJSON(w, http.StatusOK, FeedbackResponse{
+		MessageID:   record.ID,
+		Rating:      string(record.Rating),
+		SubmittedAt: record.SubmittedAt,
+	})
+}
+
+// handleExportFeedback handles the GET /api/v1/admin/feedback endpoint.
+//
+//	@Summary		Export feedback
+//	@Description	Exports rated answers as JSON Lines, oldest feedback first: the query, retrieved chunk IDs and sources, the answer and the feedback. Retrieved sources record what was returned, not what was relevant.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			tenant	query		string	false	"Only this tenant; defaults to the caller's, operators export every tenant without it"
+//	@Param			rating	query		string	false	"Only this rating"	Enums(up, down)
+//	@Success		200		{object}	FeedbackRecord
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/admin/feedback [get]
+func (s *Server) handleExportFeedback(w http.ResponseWriter, r *http.Request) {
+	principal := s.principalFrom(r)
+	filter := feedback.Filter{Tenant: r.URL.Query().Get("tenant")}
+	if filter.Tenant == "" && !principal.HasScope(auth.ScopeOperator) {
+		filter.Tenant = principal.Tenant
+	}
+	if filter.Tenant != "" && !canManageTenant(principal, filter.Tenant) {
+		s.writeError(w, http.StatusForbidden, "Cannot export feedback of another tenant")
+		return
+	}
+	if v := r.URL.Query().Get("rating"); v != "" {
+		rating, err := feedback.ParseRating(v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid rating: "+err.Error())
+			return
+		}
+		filter.Rating = rating
+	}
+
+	records := s.feedback.Export(filter)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(newFeedbackRecord(record)); err != nil {
+			slog.WarnContext(r.Context(), "Failed to write feedback export", "error", err)
+			return
+		}
+	}
+}
+
+func newFeedbackRecord(r feedback.Record) FeedbackRecord {
+	return FeedbackRecord{
+		ID:               r.ID,
+		SessionID:        r.SessionID,
+		Tenant:           r.Tenant,
+		UserID:           r.UserID,
+		Query:            r.Query,
+		RetrievalQuery:   r.RetrievalQuery,
+		ChunkIDs:         r.ChunkIDs,
+		RetrievedSources: r.RetrievedSources,
+		Answer:           r.Answer,
+		Rating:           string(r.Rating),
+		Comment:          r.Comment,
+		CorrectedAnswer:  r.CorrectedAnswer,
+		Account:          r.Account,
+		CreatedAt:        r.CreatedAt,
+		SubmittedAt:      r.SubmittedAt,
+	}
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/answercache"
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/feedback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFeedbackTestServer returns an admin test server with a feedback store
+// and a chat key for tenant team-a.
+func newFeedbackTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	s := newAdminTestServer(t)
+	store, err := feedback.NewStore(feedback.Config{})
+	require.NoError(t, err)
+	s.feedback = store
+
+	w := doAdminRequest(t, s, "POST", "/api/v1/admin/keys", "admin-key", CreateKeyRequest{
+		Name:   "frontend",
+		Tenant: "team-a",
+		Scopes: []string{"chat"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created CreateKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	return s, created.Key
+}
+
+func readExport(t *testing.T, body string) []FeedbackRecord {
+	t.Helper()
+	var records []FeedbackRecord
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var r FeedbackRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestFeedback_SubmitAndExport(t *testing.T) {
+	s, key := newFeedbackTestServer(t)
+	s.recordInteraction(context.Background(), feedback.Interaction{
+		ID:        "m1",
+		SessionID: "s1",
+		Tenant:    "team-a",
+		Query:     "Do I restart?",
+		Answer:    "No.",
+	}, []answercache.Citation{
+		{ChunkID: "c1", Source: "upgrade.md"},
+		{ChunkID: "c2", Source: "upgrade.md"},
+		{ChunkID: "c3"},
+	})
+
+	w := doAdminRequest(t, s, "POST", "/api/v1/feedback", key, FeedbackRequest{
+		SessionID:       "s1",
+		MessageID:       "m1",
+		Rating:          "down",
+		Comment:         "Outdated",
+		CorrectedAnswer: "Yes, restart after upgrading.",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp FeedbackResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "m1", resp.MessageID)
+	assert.Equal(t, "down", resp.Rating)
+	assert.False(t, resp.SubmittedAt.IsZero())
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback?rating=down", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	// Retrieved sources must not read as relevance labels in eval datasets
+	assert.NotContains(t, w.Body.String(), `"sources"`)
+	records := readExport(t, w.Body.String())
+	require.Len(t, records, 1)
+	r := records[0]
+	assert.Equal(t, "m1", r.ID)
+	assert.Equal(t, "Do I restart?", r.Query)
+	assert.Equal(t, "No.", r.Answer)
+	assert.Equal(t, []string{"c1", "c2", "c3"}, r.ChunkIDs)
+	assert.Equal(t, []string{"upgrade.md"}, r.RetrievedSources)
+	assert.Equal(t, "Yes, restart after upgrading.", r.CorrectedAnswer)
+	assert.Equal(t, "team-a", r.Tenant)
+	assert.NotEmpty(t, r.Account)
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback?rating=up", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, readExport(t, w.Body.String()))
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback?tenant=team-b", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, readExport(t, w.Body.String()))
+}
+
+func TestFeedback_ExportTenantAdmin(t *testing.T) {
+	s, _ := newFeedbackTestServer(t)
+	for _, tenant := range []string{"team-a", "team-b"} {
+		s.recordInteraction(context.Background(), feedback.Interaction{ID: tenant + "-m1", SessionID: "s1", Tenant: tenant}, nil)
+		_, err := s.feedback.Submit(tenant, "s1", tenant+"-m1", feedback.Feedback{Rating: feedback.RatingUp})
+		require.NoError(t, err)
+	}
+
+	w := doAdminRequest(t, s, "POST", "/api/v1/admin/keys", "admin-key", CreateKeyRequest{
+		Name:   "team-a-admin",
+		Tenant: "team-a",
+		Scopes: []string{"admin"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created CreateKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// Tenant admins export their own tenant by default
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback", created.Key, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	records := readExport(t, w.Body.String())
+	require.Len(t, records, 1)
+	assert.Equal(t, "team-a", records[0].Tenant)
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback?tenant=team-b", created.Key, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Operators export every tenant
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, readExport(t, w.Body.String()), 2)
+}
+
+func TestFeedback_NotFound(t *testing.T) {
+	s, key := newFeedbackTestServer(t)
+	s.recordInteraction(context.Background(), feedback.Interaction{ID: "m1", SessionID: "s1", Tenant: auth.DefaultTenant}, nil)
+
+	// The answer belongs to another tenant
+	w := doAdminRequest(t, s, "POST", "/api/v1/feedback", key, FeedbackRequest{SessionID: "s1", MessageID: "m1", Rating: "up"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAdminRequest(t, s, "POST", "/api/v1/feedback", "admin-key", FeedbackRequest{SessionID: "s2", MessageID: "m1", Rating: "up"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doAdminRequest(t, s, "POST", "/api/v1/feedback", "admin-key", FeedbackRequest{SessionID: "s1", MessageID: "m1", Rating: "up"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestFeedback_OwnRateLimit(t *testing.T) {
+	s, key := newFeedbackTestServer(t)
+	s.middleware.rateLimiter = newRateLimiter(rateLimitConfig{
+		routes: map[string]routeLimit{"chat": {limit: 1, window: time.Minute}},
+	})
+
+	// Rating answers doesn't draw on the chat budget
+	for i := 0; i < 3; i++ {
+		w := doAdminRequest(t, s, "POST", "/api/v1/feedback", key, FeedbackRequest{SessionID: "s1", MessageID: "m1", Rating: "up"})
+		assert.Equal(t, http.StatusNotFound, w.Code, "request %d", i)
+	}
+}
+
+func TestFeedback_Validation(t *testing.T) {
+	s, key := newFeedbackTestServer(t)
+
+	tests := []struct {
+		name string
+		body any
+	}{
+		{name: "invalid json", body: "not an object"},
+		{name: "missing message", body: FeedbackRequest{SessionID: "s1", Rating: "up"}},
+		{name: "missing session", body: FeedbackRequest{MessageID: "m1", Rating: "up"}},
+		{name: "unknown rating", body: FeedbackRequest{SessionID: "s1", MessageID: "m1", Rating: "5"}},
+		{name: "comment too long", body: FeedbackRequest{SessionID: "s1", MessageID: "m1", Rating: "up", Comment: strings.Repeat("x", maxFeedbackText+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doAdminRequest(t, s, "POST", "/api/v1/feedback", key, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	w := doAdminRequest(t, s, "GET", "/api/v1/admin/feedback?rating=meh", "admin-key", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	// Exports are admin only
+	w = doAdminRequest(t, s, "GET", "/api/v1/admin/feedback", key, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
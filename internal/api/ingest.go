@@ -0,0 +1,320 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mfmezger/agentic_rag_go/internal/chunking"
+	"github.com/mfmezger/agentic_rag_go/internal/enrich"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+
+	"github.com/google/uuid"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// uploadChunker splits an upload into the chunks to embed.
+type uploadChunker struct {
+	chunker chunking.Chunker
+	// child splits the chunks into small child chunks linked to them as
+	// parents; nil unless indexing parent documents.
+	child textsplitter.TextSplitter
+}
+
+// errChunkSize is returned for chunk sizes the splitters cannot honour.
+var errChunkSize = errors.New("chunk_size must be positive and chunk_overlap between 0 and chunk_size")
+
+// uploadChunker resolves the chunking of an upload from the configured
+// defaults and the upload's overrides. size and overlap, when set, override
+// the size of the embedded chunks: the child chunks when indexing parent
+// documents.
+func (s *Server) uploadChunker(req UploadTextRequest, size, overlap *int) (*uploadChunker, error) {
+	name := s.cfg.Retriever.Chunking.Strategy
+	if req.ChunkStrategy != "" {
+		name = req.ChunkStrategy
+	}
+	strategy, err := chunking.ParseStrategy(name)
+	if err != nil {
+		return nil, err
+	}
+	language := s.cfg.Retriever.Chunking.Language
+	if req.Language != "" {
+		language = req.Language
+	}
+
+	parentDocument := s.cfg.Retriever.ParentDocument.Enabled
+	if req.ParentDocument != nil {
+		parentDocument = *req.ParentDocument
+	}
+
+	// Sizes apply to the embedded chunks: the children with parent documents
+	pd := s.cfg.Retriever.ParentDocument
+	embedSize, embedOverlap := s.cfg.Retriever.ChunkSize, s.cfg.Retriever.ChunkOverlap
+	if parentDocument {
+		embedSize, embedOverlap = pd.ChildSize, pd.ChildOverlap
+	}
+	if size != nil {
+		embedSize = *size
+	}
+	if overlap != nil {
+		embedOverlap = *overlap
+	}
+	if embedSize <= 0 || embedOverlap < 0 || embedOverlap >= embedSize {
+		return nil, errChunkSize
+	}
+
+	sem := s.cfg.Retriever.Chunking.Semantic
+	cfg := chunking.Config{
+		Strategy: strategy,
+		Size:     embedSize,
+		Overlap:  embedOverlap,
+		Language: language,
+		Embedder: s.embedder,
+		Semantic: chunking.SemanticConfig{
+			Percentile: sem.Percentile,
+			MinSize:    sem.MinSize,
+			MaxSize:    sem.MaxSize,
+		},
+	}
+	u := &uploadChunker{}
+	if parentDocument {
+		// The strategy shapes the parent sections; children are plain
+		// slices of them
+		cfg.Size, cfg.Overlap = pd.ParentSize, pd.ParentOverlap
+		u.child = textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(embedSize),
+			textsplitter.WithChunkOverlap(embedOverlap),
+		)
+	}
+
+	if u.chunker, err = chunking.New(cfg); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// split returns the chunks of text and, when indexing parent documents,
+// the number of parent sections.
+func (u *uploadChunker) split(ctx context.Context, text string) ([]chunking.Chunk, int, error) {
+	chunks, err := u.chunker.Chunk(ctx, text)
+	if err != nil || u.child == nil {
+		return chunks, 0, err
+	}
+
+	var children []chunking.Chunk
+	for i, parent := range chunks {
+		texts, err := u.child.SplitText(parent.Text)
+		if err != nil {
+			return nil, 0, fmt.Errorf("split parent %d: %w", i, err)
+		}
+
+		parentID := uuid.New().String()
+		for j, t := range texts {
+			metadata := make(map[string]string, len(parent.Metadata)+4)
+			for k, v := range parent.Metadata {
+				metadata[k] = v
+			}
+			// Written last so chunker metadata can't break the parent link
+			metadata[qdrant.ParentField] = parentID
+			metadata["parent_index"] = strconv.Itoa(i)
+			metadata[qdrant.ParentChunkField] = strconv.Itoa(j)
+			// The section is stored once, on its first child; retrieval
+			// looks it up from there
+			if j == 0 {
+				metadata[qdrant.ParentContentField] = parent.Text
+			}
+			children = append(children, chunking.Chunk{Text: t, Metadata: metadata})
+		}
+	}
+	return children, len(chunks), nil
+}
+
+// enrichmentMode resolves the contextual enrichment mode of an upload.
+func (s *Server) enrichmentMode(req UploadTextRequest) (enrich.Mode, error) {
+	name := s.cfg.Retriever.Enrichment.Mode
+	if req.Enrichment != "" {
+		name = req.Enrichment
+	}
+	return enrich.ParseMode(name)
+}
+
+// enrichChunks returns the texts to embed for chunks: each chunk prefixed
+// with its context header, which is also recorded in the chunk metadata.
+// Without enrichment the texts are the chunks themselves.
+func (s *Server) enrichChunks(ctx context.Context, mode enrich.Mode, req UploadTextRequest, chunks []chunking.Chunk) ([]string, error) {
+	texts := make([]string, len(chunks))
+	if mode == enrich.ModeNone {
+		for i, c := range chunks {
+			texts[i] = c.Text
+		}
+		return texts, nil
+	}
+
+	doc := enrich.Document{Title: req.Title, Summary: req.Summary, Text: req.Text}
+	if doc.Title == "" {
+		doc.Title = req.Metadata["title"]
+	}
+	if doc.Title == "" {
+		doc.Title = req.Source
+	}
+	// A missing summary only makes headers less specific
+	if err := s.enricher.Describe(ctx, mode, &doc); err != nil {
+		slog.WarnContext(ctx, "Failed to describe document, using template headers", "error", err)
+	}
+
+	for i, c := range chunks {
+		header, err := s.enricher.Header(mode, doc, c.Metadata[chunking.HeadingPathField])
+		if err != nil {
+			return nil, err
+		}
+		if header != "" {
+			if chunks[i].Metadata == nil {
+				chunks[i].Metadata = make(map[string]string)
+			}
+			chunks[i].Metadata[enrich.ContextField] = header
+		}
+		texts[i] = enrich.Prepend(header, c.Text)
+	}
+	return texts, nil
+}
+
+// chunkMetadata returns the payload stored with the i-th chunk of an
+// upload: the upload's metadata, what the chunker derived, and the chunk's
+// position in its document.
+func chunkMetadata(req UploadTextRequest, c chunking.Chunk, documentID string, i int) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range c.Metadata {
+		metadata[k] = v
+	}
+	if req.Source != "" {
+		metadata[qdrant.SourceField] = req.Source
+	}
+	metadata[qdrant.DocumentField] = documentID
+	metadata[qdrant.ChunkIndexField] = strconv.Itoa(i)
+	return metadata
+}
+
+// PreviewChunksRequest is the request body for the chunking preview. It
+// takes the upload body plus optional chunk sizes.
+type PreviewChunksRequest struct {
+	UploadTextRequest
+	// ChunkSize overrides the configured chunk size, in estimated tokens
+	// for the token_estimate strategy and in characters otherwise. With
+	// parent documents it sizes the child chunks.
+	ChunkSize *int `json:"chunk_size,omitempty" example:"256"`
+	// ChunkOverlap overrides the configured chunk overlap, in the same
+	// unit as ChunkSize.
+	ChunkOverlap *int `json:"chunk_overlap,omitempty" example:"32"`
+}
+
+// PreviewChunksResponse is the response for the chunking preview.
+type PreviewChunksResponse struct {
+	ChunkCount int `json:"chunk_count" example:"5"`
+	// ParentCount is the number of parent sections when indexing parent
+	// documents.
+	ParentCount int            `json:"parent_count,omitempty" example:"2"`
+	Chunks      []PreviewChunk `json:"chunks"`
+}
+
+// PreviewChunk is a chunk as an upload would store it.
+type PreviewChunk struct {
+	Index int    `json:"index" example:"0"`
+	Text  string `json:"text" example:"Your document text goes here..."`
+	// EmbeddedText is the text embedded for the chunk when enrichment
+	// prepends a context header.
+	EmbeddedText string `json:"embedded_text,omitempty" example:"Document: Upgrade Guide\n\nYour document text goes here..."`
+	// Characters is the chunk length in characters.
+	Characters int `json:"characters" example:"480"`
+	// Tokens is the estimated number of embedding model tokens.
+	Tokens   int               `json:"tokens" example:"120"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// handlePreviewChunks handles the POST /api/v1/documents/preview endpoint.
+//
+//	@Summary		Preview chunking
+//	@Description	Chunks text like an upload would and returns the chunks with their sizes and metadata, without embedding or storing them
+//	@Tags			documents
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		PreviewChunksRequest	true	"Text to chunk"
+//	@Success		200		{object}	PreviewChunksResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/documents/preview [post]
+func (s *Server) handlePreviewChunks(w http.ResponseWriter, r *http.Request) {
+	var req PreviewChunksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Text == "" {
+		s.writeError(w, http.StatusBadRequest, "Text field is required")
+		return
+	}
+
+	chunker, err := s.uploadChunker(req.UploadTextRequest, req.ChunkSize, req.ChunkOverlap)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid chunking options: "+err.Error())
+		return
+	}
+	mode, err := s.enrichmentMode(req.UploadTextRequest)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid enrichment options: "+err.Error())
+		return
+	}
+
+	// Semantic chunking and LLM enrichment call models, even for a preview,
+	// so previews count against the quota
+	principal := s.principalFrom(r)
+	if !s.checkQuota(w, principal) {
+		return
+	}
+	ctx, record := s.meterUsage(r.Context(), principal)
+	defer record()
+
+	chunks, parentCount, err := chunker.split(ctx, req.Text)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to split text: "+err.Error())
+		return
+	}
+
+	texts, err := s.enrichChunks(ctx, mode, req.UploadTextRequest, chunks)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to enrich chunks: "+err.Error())
+		return
+	}
+
+	// Nothing is stored, so the document ID only shows the shape of the
+	// payload
+	documentID := uuid.New().String()
+	preview := make([]PreviewChunk, len(chunks))
+	for i, c := range chunks {
+		preview[i] = PreviewChunk{
+			Index:      i,
+			Text:       c.Text,
+			Characters: utf8.RuneCountInString(c.Text),
+			Tokens:     chunking.EstimateTokens(c.Text),
+			Metadata:   chunkMetadata(req.UploadTextRequest, c, documentID, i),
+		}
+		if texts[i] != c.Text {
+			preview[i].EmbeddedText = texts[i]
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, PreviewChunksResponse{
+		ChunkCount:  len(chunks),
+		ParentCount: parentCount,
+		Chunks:      preview,
+	})
+}
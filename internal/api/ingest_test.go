@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/chunking"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/enrich"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+func newIngestTestServer(parentDocument bool) *Server {
+	enricher, _ := enrich.New(enrich.Config{}, nil)
+	return &Server{enricher: enricher, middleware: newMiddleware(nil, nil, "default", rateLimitConfig{}), cfg: &config.Config{Retriever: config.RetrieverConfig{
+		ChunkSize:    25,
+		ChunkOverlap: 0,
+		Chunking:     config.ChunkingConfig{Strategy: "recursive"},
+		ParentDocument: config.ParentDocumentConfig{
+			Enabled:    parentDocument,
+			ParentSize: 200,
+			ChildSize:  50,
+		},
+	}}}
+}
+
+func TestUploadChunker(t *testing.T) {
+	s := newIngestTestServer(false)
+
+	u, err := s.uploadChunker(UploadTextRequest{}, nil, nil)
+	require.NoError(t, err)
+	chunks, parents, err := u.split(context.Background(), "first sentence here. second sentence here.")
+	require.NoError(t, err)
+	assert.Zero(t, parents)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "first sentence here.", chunks[0].Text)
+}
+
+func TestUploadChunker_Strategy(t *testing.T) {
+	s := newIngestTestServer(false)
+
+	u, err := s.uploadChunker(UploadTextRequest{ChunkStrategy: "markdown"}, nil, nil)
+	require.NoError(t, err)
+	chunks, _, err := u.split(context.Background(), "# Guide\n\n## Install\n\nRun it.")
+	require.NoError(t, err)
+	require.NotEmpty(t, chunks)
+	assert.Equal(t, "Guide > Install", chunks[len(chunks)-1].Metadata[chunking.HeadingPathField])
+
+	u, err = s.uploadChunker(UploadTextRequest{ChunkStrategy: "code", Language: "python"}, nil, nil)
+	require.NoError(t, err)
+	chunks, _, err = u.split(context.Background(), "def a():\n    pass\n\ndef b():\n    pass\n")
+	require.NoError(t, err)
+	assert.Equal(t, "python", chunks[0].Metadata[chunking.LanguageField])
+}
+
+func TestUploadChunker_Invalid(t *testing.T) {
+	s := newIngestTestServer(false)
+
+	_, err := s.uploadChunker(UploadTextRequest{ChunkStrategy: "paragraph"}, nil, nil)
+	assert.ErrorIs(t, err, chunking.ErrUnknownStrategy)
+
+	_, err = s.uploadChunker(UploadTextRequest{ChunkStrategy: "code"}, nil, nil)
+	assert.ErrorIs(t, err, chunking.ErrUnknownLanguage)
+
+	// No embedder configured
+	_, err = s.uploadChunker(UploadTextRequest{ChunkStrategy: "semantic"}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestUploadChunker_ParentDocument(t *testing.T) {
+	s := newIngestTestServer(true)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+
+	u, err := s.uploadChunker(UploadTextRequest{}, nil, nil)
+	require.NoError(t, err)
+	chunks, parents, err := u.split(context.Background(), text)
+	require.NoError(t, err)
+	require.Greater(t, parents, 1)
+	require.Greater(t, len(chunks), parents)
+
+	// Each section is stored once, on its first child
+	sections := map[string]string{}
+	for _, c := range chunks {
+		parentID := c.Metadata[qdrant.ParentField]
+		require.NotEmpty(t, parentID)
+		assert.LessOrEqual(t, len(c.Text), 50)
+		if c.Metadata[qdrant.ParentChunkField] == "0" {
+			require.NotContains(t, sections, parentID)
+			sections[parentID] = c.Metadata[qdrant.ParentContentField]
+		} else {
+			assert.NotContains(t, c.Metadata, qdrant.ParentContentField)
+		}
+	}
+	assert.Len(t, sections, parents)
+	for _, c := range chunks {
+		assert.Contains(t, sections[c.Metadata[qdrant.ParentField]], c.Text, "child is part of its parent")
+	}
+
+	// Uploads can opt out
+	disabled := false
+	u, err = s.uploadChunker(UploadTextRequest{ParentDocument: &disabled}, nil, nil)
+	require.NoError(t, err)
+	_, parents, err = u.split(context.Background(), text)
+	require.NoError(t, err)
+	assert.Zero(t, parents)
+}
+
+// stubChunker returns fixed chunks.
+type stubChunker []chunking.Chunk
+
+func (s stubChunker) Chunk(context.Context, string) ([]chunking.Chunk, error) {
+	return s, nil
+}
+
+func TestUploadChunker_ParentFieldsWin(t *testing.T) {
+	u := &uploadChunker{
+		chunker: stubChunker{{Text: "Run make restart.", Metadata: map[string]string{
+			qdrant.ParentField:        "bogus",
+			qdrant.ParentContentField: "bogus",
+			"heading_path":            "Ops",
+		}}},
+		child: textsplitter.NewRecursiveCharacter(textsplitter.WithChunkSize(100)),
+	}
+
+	chunks, _, err := u.split(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.NotEqual(t, "bogus", chunks[0].Metadata[qdrant.ParentField])
+	assert.Equal(t, "Run make restart.", chunks[0].Metadata[qdrant.ParentContentField])
+	assert.Equal(t, "Ops", chunks[0].Metadata["heading_path"])
+}
+
+func TestUploadChunker_Sizes(t *testing.T) {
+	s := newIngestTestServer(false)
+	size, overlap := 10, 20
+
+	_, err := s.uploadChunker(UploadTextRequest{}, &size, &overlap)
+	assert.ErrorIs(t, err, errChunkSize)
+
+	zero := 0
+	_, err = s.uploadChunker(UploadTextRequest{}, &zero, nil)
+	assert.ErrorIs(t, err, errChunkSize)
+}
+
+func TestEnrichChunks(t *testing.T) {
+	s := newIngestTestServer(false)
+	chunks := []chunking.Chunk{
+		{Text: "It must be restarted after the upgrade.", Metadata: map[string]string{chunking.HeadingPathField: "Upgrade > Restart"}},
+		{Text: "Intro."},
+	}
+	req := UploadTextRequest{Source: "upgrade.md", Summary: "Upgrading the server."}
+
+	texts, err := s.enrichChunks(context.Background(), enrich.ModeTemplate, req, chunks)
+	require.NoError(t, err)
+	header := "Document: upgrade.md\nSection: Upgrade > Restart\nSummary: Upgrading the server."
+	assert.Equal(t, header+"\n\nIt must be restarted after the upgrade.", texts[0])
+	assert.Equal(t, header, chunks[0].Metadata[enrich.ContextField])
+	assert.Equal(t, "It must be restarted after the upgrade.", chunks[0].Text, "stored text stays original")
+	assert.Equal(t, "Document: upgrade.md\nSummary: Upgrading the server.", chunks[1].Metadata[enrich.ContextField])
+
+	// The title metadata wins over the source
+	req.Metadata = map[string]string{"title": "Upgrade Guide"}
+	texts, err = s.enrichChunks(context.Background(), enrich.ModeTemplate, req, chunks[1:])
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(texts[0], "Document: Upgrade Guide\n"))
+
+	texts, err = s.enrichChunks(context.Background(), enrich.ModeNone, req, []chunking.Chunk{{Text: "plain"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plain"}, texts)
+}
+
+func TestEnrichChunks_DescribeFails(t *testing.T) {
+	// Without a model llm mode falls back to the template header
+	s := newIngestTestServer(false)
+	chunks := []chunking.Chunk{{Text: "chunk"}}
+
+	texts, err := s.enrichChunks(context.Background(), enrich.ModeLLM, UploadTextRequest{Title: "Guide"}, chunks)
+	require.NoError(t, err)
+	assert.Equal(t, "Document: Guide\n\nchunk", texts[0])
+}
+
+func TestHandlePreviewChunks(t *testing.T) {
+	s := newIngestTestServer(false)
+	body := `{"text": "# Guide\n\nFirst paragraph of the guide.\n\n## Setup\n\nRun it.", "source": "guide.md", "metadata": {"team": "docs"}, "chunk_strategy": "markdown", "chunk_size": 40, "chunk_overlap": 0}`
+
+	w := httptest.NewRecorder()
+	s.handlePreviewChunks(w, httptest.NewRequest(http.MethodPost, "/api/v1/documents/preview", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp PreviewChunksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 2, resp.ChunkCount)
+	require.Len(t, resp.Chunks, 2)
+
+	first := resp.Chunks[0]
+	assert.Equal(t, "# Guide\n\nFirst paragraph of the guide.", first.Text)
+	assert.Equal(t, 38, first.Characters)
+	assert.Equal(t, chunking.EstimateTokens(first.Text), first.Tokens)
+	assert.Equal(t, "docs", first.Metadata["team"])
+	assert.Equal(t, "guide.md", first.Metadata[qdrant.SourceField])
+	assert.Equal(t, "0", first.Metadata[qdrant.ChunkIndexField])
+	assert.NotEmpty(t, first.Metadata[qdrant.DocumentField])
+
+	second := resp.Chunks[1]
+	assert.Equal(t, 1, second.Index)
+	assert.Equal(t, "Guide > Setup", second.Metadata[chunking.HeadingPathField])
+	assert.Equal(t, first.Metadata[qdrant.DocumentField], second.Metadata[qdrant.DocumentField])
+	assert.Empty(t, second.EmbeddedText)
+}
+
+func TestHandlePreviewChunks_Enrichment(t *testing.T) {
+	s := newIngestTestServer(false)
+	body := `{"text": "# Guide\n\n## Setup\n\nRun it.", "title": "Guide", "chunk_strategy": "markdown", "enrichment": "template"}`
+
+	w := httptest.NewRecorder()
+	s.handlePreviewChunks(w, httptest.NewRequest(http.MethodPost, "/api/v1/documents/preview", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp PreviewChunksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Chunks)
+
+	last := resp.Chunks[len(resp.Chunks)-1]
+	assert.Equal(t, "## Setup\n\nRun it.", last.Text)
+	assert.Equal(t, "Document: Guide\nSection: Guide > Setup\n\n## Setup\n\nRun it.", last.EmbeddedText)
+	assert.Equal(t, "Document: Guide\nSection: Guide > Setup", last.Metadata[enrich.ContextField])
+}
+
+func TestHandlePreviewChunks_Invalid(t *testing.T) {
+	s := newIngestTestServer(false)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed", body: `{`},
+		{name: "missing text", body: `{"source": "a.md"}`},
+		{name: "unknown strategy", body: `{"text": "x", "chunk_strategy": "paragraph"}`},
+		{name: "overlap too large", body: `{"text": "x", "chunk_size": 10, "chunk_overlap": 10}`},
+		{name: "unknown enrichment", body: `{"text": "x", "enrichment": "summary"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.handlePreviewChunks(w, httptest.NewRequest(http.MethodPost, "/api/v1/documents/preview", strings.NewReader(tt.body)))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
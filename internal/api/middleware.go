@@ -1,50 +1,124 @@
 package api
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/logging"
 )
 
 type middleware struct {
-	apiKey      string
-	rateLimiter *rateLimiter
-}
-
-type rateLimiter struct {
-	clients map[string]*clientLimiter
-	mu      sync.RWMutex
-	rate    int
-	window  time.Duration
-}
-
-type clientLimiter struct {
-	tokens int
-	last   time.Time
-	mu     sync.Mutex
+	// keys and jwt authenticate callers; both nil disables authentication.
+	keys          *auth.KeyStore
+	jwt           *auth.JWTVerifier
+	defaultTenant string
+	rateLimiter   *rateLimiter
 }
 
-func newMiddleware(apiKey string, rateLimit int, rateWindow time.Duration) *middleware {
+func newMiddleware(keys *auth.KeyStore, jwt *auth.JWTVerifier, defaultTenant string, rateLimits rateLimitConfig) *middleware {
+	if defaultTenant == "" {
+		defaultTenant = auth.DefaultTenant
+	}
 	return &middleware{
-		apiKey: apiKey,
-		rateLimiter: &rateLimiter{
-			clients: make(map[string]*clientLimiter),
-			rate:    rateLimit,
-			window:  rateWindow,
-		},
+		keys:          keys,
+		jwt:           jwt,
+		defaultTenant: defaultTenant,
+		rateLimiter:   newRateLimiter(rateLimits),
 	}
 }
 
+// auth authenticates the request and stores the resulting principal,
+// including its tenant, in the request context.
 func (m *middleware) auth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if m.apiKey == "" {
-			next.ServeHTTP(w, r)
+		principal, ok := m.authenticate(r)
+		if !ok {
+			m.rejectUnauthenticated(w, r)
 			return
 		}
 
-		key := r.Header.Get("X-API-Key")
-		if key != m.apiKey {
-			http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		setLogPrincipal(r.Context(), principal)
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+// authRoute is the rate limit class of failed authentications.
+const authRoute = "auth"
+
+// rejectUnauthenticated answers a failed authentication. Failures are
+// limited per client address to slow down guessing keys and tokens; only
+// failures count, so callers sharing an address with a guesser still get
+// in with valid credentials.
+func (m *middleware) rejectUnauthenticated(w http.ResponseWriter, r *http.Request) {
+	failures := m.rateLimiter.limitFor(authRoute)
+	if failures.limit > 0 && failures.window > 0 {
+		decision := m.rateLimiter.allow(authRoute+"|ip:"+m.rateLimiter.clientIP(r), failures)
+		if !decision.allowed {
+			decision.setHeaders(w.Header())
+			http.Error(w, `{"error":"Too many failed authentications"}`, http.StatusTooManyRequests)
+			return
+		}
+	}
+	http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+}
+
+// authenticate resolves the principal for a request from a bearer token
+// or an X-API-Key header. When authentication is disabled every caller acts
+// for the default tenant with all non-admin scopes.
+func (m *middleware) authenticate(r *http.Request) (*auth.Principal, bool) {
+	if m.keys == nil && m.jwt == nil {
+		return &auth.Principal{Tenant: m.defaultTenant, Scopes: auth.UserScopes}, true
+	}
+
+	if token, ok := bearerToken(r); ok {
+		if m.jwt == nil {
+			return nil, false
+		}
+		principal, err := m.jwt.Verify(r.Context(), token)
+		if err != nil {
+			return nil, false
+		}
+		return principal, true
+	}
+
+	if m.keys == nil {
+		return nil, false
+	}
+	key, err := m.keys.Authenticate(r.Header.Get("X-API-Key"))
+	if err != nil {
+		return nil, false
+	}
+
+	return &auth.Principal{
+		Tenant: key.Tenant,
+		KeyID:  key.ID,
+		Scopes: key.Scopes,
+	}, true
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// requireScope rejects authenticated callers lacking scope.
+// It must run inside auth.
+func (m *middleware) requireScope(scope auth.Scope, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok || !principal.HasScope(scope) {
+			http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
 			return
 		}
 
@@ -52,15 +126,21 @@ func (m *middleware) auth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func (m *middleware) rateLimit(next http.HandlerFunc) http.HandlerFunc {
+// rateLimit limits requests per caller for the given route class. It runs
+// inside auth so authenticated callers are limited per API key or user
+// rather than per address.
+func (m *middleware) rateLimit(route string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if m.rateLimiter.rate == 0 {
+		limit := m.rateLimiter.limitFor(route)
+		if limit.limit <= 0 || limit.window <= 0 {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		clientIP := r.RemoteAddr
-		if !m.rateLimiter.allow(clientIP) {
+		key := route + "|" + m.rateLimiter.clientKey(r)
+		decision := m.rateLimiter.allow(key, limit)
+		decision.setHeaders(w.Header())
+		if !decision.allowed {
 			http.Error(w, `{"error":"Rate limit exceeded"}`, http.StatusTooManyRequests)
 			return
 		}
@@ -69,47 +149,132 @@ func (m *middleware) rateLimit(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func (rl *rateLimiter) allow(ip string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+// close releases background resources.
+func (m *middleware) close() {
+	m.rateLimiter.close()
+}
+
+// requestIDHeader carries the correlation ID of a request.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs.
+const maxRequestIDLength = 128
 
-	cl, exists := rl.clients[ip]
-	if !exists {
-		cl = &clientLimiter{
-			tokens: rl.rate - 1,
-			last:   time.Now(),
+// requestID propagates the caller's X-Request-ID, or generates one, and
+// stores it in the request context so every log line of the request
+// carries it. The ID is echoed in the response.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
 		}
-		rl.clients[ip] = cl
-		return true
-	}
 
-	cl.mu.Lock()
-	defer cl.mu.Unlock()
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
 
-	elapsed := time.Since(cl.last)
-	if elapsed >= rl.window {
-		cl.tokens = rl.rate - 1
-		cl.last = time.Now()
-		return true
+// validRequestID accepts IDs that are safe to echo and log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
 	}
-
-	if cl.tokens > 0 {
-		cl.tokens--
-		return true
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
 	}
+	return true
+}
 
-	return false
+// requestLog collects details for the access log that only inner handlers
+// know, such as the authenticated principal.
+type requestLog struct {
+	principal *auth.Principal
 }
 
-func (rl *rateLimiter) cleanup() {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+type requestLogKey struct{}
+
+// setLogPrincipal records the principal for the access log.
+func setLogPrincipal(ctx context.Context, p *auth.Principal) {
+	if rl, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		rl.principal = p
+	}
+}
+
+// accessLog logs one line per request with its method, route, status,
+// latency and principal.
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rl := &requestLog{}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		ctx := context.WithValue(r.Context(), requestLogKey{}, rl)
+		req := r.WithContext(ctx)
+		next.ServeHTTP(rec, req)
+
+		// The mux stores the matched pattern on the request it was given
+		route := req.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if rl.principal != nil {
+			attrs = append(attrs,
+				slog.String("tenant", rl.principal.Tenant),
+				slog.String("account", rl.principal.AccountID()),
+			)
+		}
 
-	for ip, cl := range rl.clients {
-		cl.mu.Lock()
-		if time.Since(cl.last) > rl.window*5 {
-			delete(rl.clients, ip)
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
 		}
-		cl.mu.Unlock()
+		slog.LogAttrs(ctx, level, "http request", attrs...)
+	})
+}
+
+// statusRecorder captures the status code and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
 	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
 }
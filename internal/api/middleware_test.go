@@ -1,24 +1,40 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// newTestKeys returns a key store holding apiKey as an admin key,
+// or nil (authentication disabled) when apiKey is empty.
+func newTestKeys(t *testing.T, apiKey string) *auth.KeyStore {
+	t.Helper()
+	keys, err := newKeyStore(config.ServerConfig{APIKey: apiKey})
+	require.NoError(t, err)
+	return keys
+}
+
 func TestNewMiddleware(t *testing.T) {
-	m := newMiddleware("test-key", 10, 60*time.Second)
+	m := newMiddleware(newTestKeys(t, "test-key"), nil, "", rateLimitConfig{limit: 10, window: 60 * time.Second})
 	assert.NotNil(t, m)
-	assert.Equal(t, "test-key", m.apiKey)
-	assert.Equal(t, 10, m.rateLimiter.rate)
-	assert.Equal(t, 60*time.Second, m.rateLimiter.window)
+	assert.NotNil(t, m.keys)
+	assert.Equal(t, auth.DefaultTenant, m.defaultTenant)
+	assert.Equal(t, routeLimit{limit: 10, window: 60 * time.Second}, m.rateLimiter.limitFor("search"))
 }
 
 func TestMiddlewareAuth_NoKey(t *testing.T) {
-	m := newMiddleware("", 0, time.Second)
+	m := newMiddleware(nil, nil, "", rateLimitConfig{})
 	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
@@ -34,7 +50,7 @@ func TestMiddlewareAuth_NoKey(t *testing.T) {
 }
 
 func TestMiddlewareAuth_WithValidKey(t *testing.T) {
-	m := newMiddleware("secret-key", 0, time.Second)
+	m := newMiddleware(newTestKeys(t, "secret-key"), nil, "", rateLimitConfig{})
 	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
@@ -51,7 +67,7 @@ func TestMiddlewareAuth_WithValidKey(t *testing.T) {
 }
 
 func TestMiddlewareAuth_WithInvalidKey(t *testing.T) {
-	m := newMiddleware("secret-key", 0, time.Second)
+	m := newMiddleware(newTestKeys(t, "secret-key"), nil, "", rateLimitConfig{})
 	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
@@ -68,7 +84,7 @@ func TestMiddlewareAuth_WithInvalidKey(t *testing.T) {
 }
 
 func TestMiddlewareAuth_NoHeader(t *testing.T) {
-	m := newMiddleware("secret-key", 0, time.Second)
+	m := newMiddleware(newTestKeys(t, "secret-key"), nil, "", rateLimitConfig{})
 	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
@@ -82,9 +98,172 @@ func TestMiddlewareAuth_NoHeader(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestMiddlewareAuth_FailureLimit(t *testing.T) {
+	m := newMiddleware(newTestKeys(t, "secret-key"), nil, "", rateLimitConfig{
+		routes: map[string]routeLimit{authRoute: {limit: 3, window: time.Minute}},
+	})
+	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(ip, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	// Successful authentications don't count
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, request("192.168.1.1", "secret-key").Code)
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, request("192.168.1.1", "wrong-key").Code, "attempt %d", i)
+	}
+	w := request("192.168.1.1", "wrong-key")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Valid credentials from the same address, e.g. behind a shared
+	// proxy, still get in
+	assert.Equal(t, http.StatusOK, request("192.168.1.1", "secret-key").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("192.168.1.1", "wrong-key").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("192.168.1.2", "wrong-key").Code)
+}
+
+func TestMiddlewareAuth_PrincipalTenant(t *testing.T) {
+	keys, err := newKeyStore(config.ServerConfig{
+		APIKey:        "secret-key",
+		DefaultTenant: "shared",
+		TenantKeys:    map[string]string{"team-a-key": "team-a"},
+	})
+	require.NoError(t, err)
+	m := newMiddleware(keys, nil, "shared", rateLimitConfig{})
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "tenant key", key: "team-a-key", wantStatus: http.StatusOK, wantTenant: "team-a"},
+		{name: "static key", key: "secret-key", wantStatus: http.StatusOK, wantTenant: "shared"},
+		{name: "unknown key", key: "other", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenant string
+			handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
+				p, ok := auth.FromContext(r.Context())
+				require.True(t, ok)
+				tenant = p.Tenant
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantTenant, tenant)
+		})
+	}
+}
+
+func TestMiddlewareAuth_DefaultTenantWithoutKeys(t *testing.T) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{})
+
+	var principal *auth.Principal
+	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.FromContext(r.Context())
+	})
+
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	require.NotNil(t, principal)
+	assert.Equal(t, auth.DefaultTenant, principal.Tenant)
+	assert.Empty(t, principal.Subject)
+	assert.False(t, principal.HasScope(auth.ScopeAdmin))
+}
+
+func TestMiddlewareAuth_MintedKey(t *testing.T) {
+	keys := newTestKeys(t, "admin-key")
+	secret, key, err := keys.Mint("reader", "team-a", []auth.Scope{auth.ScopeSearch}, 0)
+	require.NoError(t, err)
+	m := newMiddleware(keys, nil, "", rateLimitConfig{})
+
+	var principal *auth.Principal
+	handler := m.auth(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.FromContext(r.Context())
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-API-Key", secret)
+	handler(httptest.NewRecorder(), req)
+
+	require.NotNil(t, principal)
+	assert.Equal(t, key.ID, principal.KeyID)
+	assert.Equal(t, "team-a", principal.Tenant)
+
+	require.NoError(t, keys.Revoke(key.ID))
+	w := httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestMiddlewareRequireScope(t *testing.T) {
+	keys := newTestKeys(t, "admin-key")
+	secret, _, err := keys.Mint("reader", "default", []auth.Scope{auth.ScopeSearch}, 0)
+	require.NoError(t, err)
+	m := newMiddleware(keys, nil, "", rateLimitConfig{})
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	tests := []struct {
+		name       string
+		key        string
+		scope      auth.Scope
+		wantStatus int
+	}{
+		{name: "granted scope", key: secret, scope: auth.ScopeSearch, wantStatus: http.StatusOK},
+		{name: "missing scope", key: secret, scope: auth.ScopeIngest, wantStatus: http.StatusForbidden},
+		{name: "admin implies scope", key: "admin-key", scope: auth.ScopeIngest, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := m.auth(m.requireScope(tt.scope, ok))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestMiddlewareRequireScope_NoPrincipal(t *testing.T) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{})
+	handler := m.requireScope(auth.ScopeSearch, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
 func TestMiddlewareRateLimit_Disabled(t *testing.T) {
-	m := newMiddleware("", 0, time.Second)
-	handler := m.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -101,8 +280,8 @@ func TestMiddlewareRateLimit_Disabled(t *testing.T) {
 func TestMiddlewareRateLimit_Enabled(t *testing.T) {
 	rate := 5
 	window := 1 * time.Second
-	m := newMiddleware("", rate, window)
-	handler := m.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{limit: rate, window: window})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -124,8 +303,8 @@ func TestMiddlewareRateLimit_Enabled(t *testing.T) {
 func TestMiddlewareRateLimit_DifferentIPs(t *testing.T) {
 	rate := 3
 	window := 1 * time.Second
-	m := newMiddleware("", rate, window)
-	handler := m.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{limit: rate, window: window})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -145,8 +324,8 @@ func TestMiddlewareRateLimit_DifferentIPs(t *testing.T) {
 func TestMiddlewareRateLimit_WindowReset(t *testing.T) {
 	rate := 2
 	window := 100 * time.Millisecond
-	m := newMiddleware("", rate, window)
-	handler := m.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{limit: rate, window: window})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -170,37 +349,113 @@ func TestMiddlewareRateLimit_WindowReset(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code, "Should succeed after window reset")
 }
 
-func TestRateLimiter_Cleanup(t *testing.T) {
-	rl := &rateLimiter{
-		clients: make(map[string]*clientLimiter),
-		rate:    10,
-		window:  1 * time.Second,
-	}
+func TestMiddlewareRateLimit_Headers(t *testing.T) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{limit: 2, window: time.Minute})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
 
-	rl.clients["192.168.1.1"] = &clientLimiter{
-		tokens: 5,
-		last:   time.Now(),
-	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+
+	w := httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	handler(httptest.NewRecorder(), req)
+
+	w = httptest.NewRecorder()
+	handler(w, req)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestMiddlewareRateLimit_IgnoresPort(t *testing.T) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{limit: 1, window: time.Minute})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	w := httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A new connection from the same host shares the bucket
+	req.RemoteAddr = "192.168.1.1:5678"
+	w = httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestMiddlewareRateLimit_PerRoute(t *testing.T) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{
+		limit:  5,
+		window: time.Minute,
+		routes: map[string]routeLimit{"chat": {limit: 1, window: time.Minute}},
+	})
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	chat := m.rateLimit("chat", ok)
+	search := m.rateLimit("search", ok)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+
+	w := httptest.NewRecorder()
+	chat(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	chat(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 
-	rl.clients["192.168.1.2"] = &clientLimiter{
-		tokens: 5,
-		last:   time.Now().Add(-10 * time.Second),
+	// Search has its own, larger bucket
+	for i := 0; i < 5; i++ {
+		w = httptest.NewRecorder()
+		search(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Request %d should succeed", i)
 	}
+}
 
-	assert.Equal(t, 2, len(rl.clients))
+func TestMiddlewareRateLimit_PerAPIKey(t *testing.T) {
+	keys := newTestKeys(t, "admin-key")
+	m := newMiddleware(keys, nil, "", rateLimitConfig{limit: 1, window: time.Minute})
+	secret, _, err := keys.Mint("reader", "default", []auth.Scope{auth.ScopeSearch}, 0)
+	require.NoError(t, err)
+
+	handler := m.auth(m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
 
-	rl.cleanup()
+	// Two keys behind the same address are limited independently
+	for _, key := range []string{"admin-key", secret} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
 
-	assert.Equal(t, 1, len(rl.clients))
-	assert.Contains(t, rl.clients, "192.168.1.1")
-	assert.NotContains(t, rl.clients, "192.168.1.2")
+	// The same key is limited across addresses
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Set("X-API-Key", secret)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 }
 
 func TestClientLimiter_ConcurrentAccess(t *testing.T) {
 	rate := 100
 	window := 1 * time.Second
-	m := newMiddleware("", rate, window)
-	handler := m.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+	m := newMiddleware(nil, nil, "", rateLimitConfig{limit: rate, window: window})
+	handler := m.rateLimit("search", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -220,3 +475,66 @@ func TestClientLimiter_ConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := requestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	// Generated when missing
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, w.Header().Get("X-Request-ID"))
+
+	// Propagated when supplied
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "upstream-123")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, "upstream-123", seen)
+	assert.Equal(t, "upstream-123", w.Header().Get("X-Request-ID"))
+
+	// Replaced when unsafe to log
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.NotEqual(t, "bad id\nwith newline", seen)
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(logging.Config{Format: "json"}, &buf)
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	keys := newTestKeys(t, "admin-key")
+	m := newMiddleware(keys, nil, "", rateLimitConfig{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", m.auth(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}))
+
+	req := httptest.NewRequest("GET", "/items/42", nil)
+	req.Header.Set("X-API-Key", "admin-key")
+	req.Header.Set("X-Request-ID", "req-1")
+	requestID(accessLog(mux)).ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "http request", record["msg"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "GET /items/{id}", record["route"])
+	assert.Equal(t, "/items/42", record["path"])
+	assert.Equal(t, float64(http.StatusTeapot), record["status"])
+	assert.Equal(t, float64(len("short and stout")), record["bytes"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, auth.DefaultTenant, record["tenant"])
+	assert.Contains(t, record["account"], "key:static_")
+	assert.Contains(t, record, "latency")
+}
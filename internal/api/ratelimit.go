@@ -0,0 +1,278 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+)
+
+// routeLimit allows limit requests per window, all of which may be used
+// in a single burst.
+type routeLimit struct {
+	limit  int
+	window time.Duration
+}
+
+// rateLimitConfig configures the rate limiter.
+type rateLimitConfig struct {
+	// limit and window apply to routes without an override; a zero limit
+	// disables rate limiting for them.
+	limit  int
+	window time.Duration
+	// routes overrides the limit per route class, named after the scope
+	// the route requires (search, ingest, chat, admin), or after the
+	// route for cheap ones (feedback, usage). The auth class limits failed
+	// authentications per client address.
+	routes map[string]routeLimit
+	// trustedProxies may set X-Forwarded-For for the client address.
+	trustedProxies []netip.Prefix
+}
+
+// newRateLimitConfig builds the rate limit configuration from server settings.
+func newRateLimitConfig(cfg config.ServerConfig) (rateLimitConfig, error) {
+	rl := rateLimitConfig{
+		limit:  cfg.RateLimit,
+		window: time.Duration(cfg.RateWindow) * time.Second,
+		routes: make(map[string]routeLimit, len(cfg.RateLimits)),
+	}
+
+	for route, limit := range cfg.RateLimits {
+		window := limit.Window
+		if window <= 0 {
+			window = cfg.RateWindow
+		}
+		rl.routes[route] = routeLimit{
+			limit:  limit.Limit,
+			window: time.Duration(window) * time.Second,
+		}
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return rateLimitConfig{}, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		rl.trustedProxies = append(rl.trustedProxies, prefix)
+	}
+
+	return rl, nil
+}
+
+// parsePrefix accepts a CIDR or a single address.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// rateLimiter implements the generic cell rate algorithm (GCRA): each
+// client has a theoretical arrival time (TAT) that advances by one
+// emission interval per request, which gives smooth token-bucket behaviour
+// with a single timestamp of state per client.
+type rateLimiter struct {
+	cfg     rateLimitConfig
+	clients map[string]*clientLimiter
+	mu      sync.Mutex
+	now     func() time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+type clientLimiter struct {
+	tat time.Time
+}
+
+// rateDecision is the outcome of a rate limit check.
+type rateDecision struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func newRateLimiter(cfg rateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		cfg:     cfg,
+		clients: make(map[string]*clientLimiter),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+}
+
+// limitFor returns the limit for a route class.
+func (rl *rateLimiter) limitFor(route string) routeLimit {
+	if l, ok := rl.cfg.routes[route]; ok {
+		return l
+	}
+	return routeLimit{limit: rl.cfg.limit, window: rl.cfg.window}
+}
+
+// enabled reports whether any route is rate limited.
+func (rl *rateLimiter) enabled() bool {
+	if rl.cfg.limit > 0 {
+		return true
+	}
+	for _, l := range rl.cfg.routes {
+		if l.limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// allow records a request from key against limit.
+func (rl *rateLimiter) allow(key string, limit routeLimit) rateDecision {
+	interval := limit.window / time.Duration(limit.limit)
+	burst := interval * time.Duration(limit.limit-1)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	cl, ok := rl.clients[key]
+	if !ok {
+		cl = &clientLimiter{tat: now}
+		rl.clients[key] = cl
+	}
+
+	tat := cl.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	// Too early: the request would push TAT beyond the burst tolerance
+	if allowAt := tat.Add(-burst); now.Before(allowAt) {
+		return rateDecision{
+			limit:      limit.limit,
+			reset:      tat.Sub(now),
+			retryAfter: allowAt.Sub(now),
+		}
+	}
+
+	cl.tat = tat.Add(interval)
+	used := cl.tat.Sub(now)
+	remaining := int((burst + interval - used) / interval)
+
+	return rateDecision{
+		allowed:   true,
+		limit:     limit.limit,
+		remaining: max(remaining, 0),
+		reset:     used,
+	}
+}
+
+// cleanup drops clients whose allowance has fully replenished; they are
+// indistinguishable from clients never seen before.
+func (rl *rateLimiter) cleanup() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	for key, cl := range rl.clients {
+		if !cl.tat.After(now) {
+			delete(rl.clients, key)
+		}
+	}
+}
+
+// startEviction runs cleanup every interval until close is called.
+func (rl *rateLimiter) startEviction(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rl.cleanup()
+			case <-rl.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close stops the eviction loop.
+func (rl *rateLimiter) close() {
+	rl.once.Do(func() { close(rl.stop) })
+}
+
+// clientKey identifies the caller for rate limiting: the API key or user
+// when authenticated, otherwise the client IP.
+func (rl *rateLimiter) clientKey(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok && !p.Anonymous() {
+		return p.AccountID()
+	}
+	return "ip:" + rl.clientIP(r)
+}
+
+// clientIP returns the client address without port. X-Forwarded-For is
+// only honoured when the direct peer is a trusted proxy, and is walked from
+// the right so clients can't spoof their address by prepending entries.
+func (rl *rateLimiter) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !rl.trusted(peer) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		addr, err := netip.ParseAddr(hop)
+		if err != nil {
+			break
+		}
+		if !rl.trusted(addr) {
+			return addr.String()
+		}
+		host = addr.String()
+	}
+
+	return host
+}
+
+func (rl *rateLimiter) trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range rl.cfg.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// setHeaders writes the RateLimit-* headers (and Retry-After when denied).
+func (d rateDecision) setHeaders(h http.Header) {
+	h.Set("RateLimit-Limit", strconv.Itoa(d.limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.reset)))
+	if !d.allowed {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(d.retryAfter)))
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimiter(cfg rateLimitConfig) (*rateLimiter, *time.Time) {
+	rl := newRateLimiter(cfg)
+	clock := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return clock }
+	return rl, &clock
+}
+
+func TestRateLimiter_GCRA(t *testing.T) {
+	rl, clock := newTestRateLimiter(rateLimitConfig{})
+	limit := routeLimit{limit: 4, window: 4 * time.Second}
+
+	// The full limit is available as a burst
+	for i := 0; i < 4; i++ {
+		d := rl.allow("client", limit)
+		require.True(t, d.allowed, "Request %d should succeed", i)
+		assert.Equal(t, 3-i, d.remaining)
+	}
+
+	d := rl.allow("client", limit)
+	assert.False(t, d.allowed)
+	assert.Equal(t, time.Second, d.retryAfter)
+	assert.Equal(t, 4*time.Second, d.reset)
+
+	// One emission interval later exactly one request is available again
+	*clock = clock.Add(time.Second)
+	assert.True(t, rl.allow("client", limit).allowed)
+	assert.False(t, rl.allow("client", limit).allowed)
+
+	// After a full window the bucket is full again
+	*clock = clock.Add(4 * time.Second)
+	d = rl.allow("client", limit)
+	assert.True(t, d.allowed)
+	assert.Equal(t, 3, d.remaining)
+}
+
+func TestRateLimiter_Cleanup(t *testing.T) {
+	rl, clock := newTestRateLimiter(rateLimitConfig{})
+	limit := routeLimit{limit: 10, window: 10 * time.Second}
+
+	rl.allow("192.168.1.1", limit)
+	*clock = clock.Add(500 * time.Millisecond)
+	rl.allow("192.168.1.2", limit)
+	assert.Equal(t, 2, len(rl.clients))
+
+	// The first client has replenished, the second has not
+	*clock = clock.Add(600 * time.Millisecond)
+	rl.cleanup()
+
+	assert.Equal(t, 1, len(rl.clients))
+	assert.NotContains(t, rl.clients, "192.168.1.1")
+	assert.Contains(t, rl.clients, "192.168.1.2")
+}
+
+func TestRateLimiter_Eviction(t *testing.T) {
+	rl := newRateLimiter(rateLimitConfig{})
+	rl.allow("client", routeLimit{limit: 100, window: time.Millisecond})
+
+	rl.startEviction(5 * time.Millisecond)
+	defer rl.close()
+
+	assert.Eventually(t, func() bool {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		return len(rl.clients) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// close is idempotent
+	rl.close()
+}
+
+func TestRateLimiter_ClientIP(t *testing.T) {
+	rl := newRateLimiter(rateLimitConfig{
+		trustedProxies: []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("::1/128"),
+		},
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:5555", want: "203.0.113.7"},
+		{name: "untrusted peer ignores header", remoteAddr: "203.0.113.7:5555", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:5555", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "spoofed entries are skipped", remoteAddr: "10.1.2.3:5555", forwarded: "1.2.3.4, 198.51.100.1", want: "198.51.100.1"},
+		{name: "proxy chain", remoteAddr: "10.1.2.3:5555", forwarded: "198.51.100.1, 10.9.9.9", want: "198.51.100.1"},
+		{name: "only proxies", remoteAddr: "10.1.2.3:5555", forwarded: "10.9.9.9", want: "10.9.9.9"},
+		{name: "garbage stops the walk", remoteAddr: "10.1.2.3:5555", forwarded: "198.51.100.1, junk", want: "10.1.2.3"},
+		{name: "ipv6 proxy", remoteAddr: "[::1]:5555", forwarded: "2001:db8::1", want: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			assert.Equal(t, tt.want, rl.clientIP(req))
+		})
+	}
+}
+
+func TestRateLimiter_ClientKey(t *testing.T) {
+	rl := newRateLimiter(rateLimitConfig{})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.7:5555"
+	assert.Equal(t, "ip:203.0.113.7", rl.clientKey(req))
+
+	ctx := auth.WithPrincipal(req.Context(), &auth.Principal{Tenant: "team-a", Subject: "alice"})
+	assert.Equal(t, "user:team-a/alice", rl.clientKey(req.WithContext(ctx)))
+
+	ctx = auth.WithPrincipal(req.Context(), &auth.Principal{Tenant: "team-a", KeyID: "k1"})
+	assert.Equal(t, "key:k1", rl.clientKey(req.WithContext(ctx)))
+
+	// Anonymous principals (authentication disabled) fall back to the address
+	ctx = auth.WithPrincipal(req.Context(), &auth.Principal{Tenant: "default"})
+	assert.Equal(t, "ip:203.0.113.7", rl.clientKey(req.WithContext(ctx)))
+}
+
+func TestNewRateLimitConfig(t *testing.T) {
+	cfg, err := newRateLimitConfig(config.ServerConfig{
+		RateLimit:  100,
+		RateWindow: 60,
+		RateLimits: map[string]config.RateLimitConfig{
+			"chat":   {Limit: 10},
+			"ingest": {Limit: 5, Window: 3600},
+		},
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.10"},
+	})
+	require.NoError(t, err)
+
+	rl := newRateLimiter(cfg)
+	assert.Equal(t, routeLimit{limit: 100, window: time.Minute}, rl.limitFor("search"))
+	assert.Equal(t, routeLimit{limit: 10, window: time.Minute}, rl.limitFor("chat"))
+	assert.Equal(t, routeLimit{limit: 5, window: time.Hour}, rl.limitFor("ingest"))
+	assert.True(t, rl.trusted(netip.MustParseAddr("192.168.1.10")))
+	assert.False(t, rl.trusted(netip.MustParseAddr("192.168.1.11")))
+
+	_, err = newRateLimitConfig(config.ServerConfig{TrustedProxies: []string{"not-an-ip"}})
+	assert.Error(t, err)
+}
+
+func TestRateLimiter_Enabled(t *testing.T) {
+	assert.False(t, newRateLimiter(rateLimitConfig{}).enabled())
+	assert.True(t, newRateLimiter(rateLimitConfig{limit: 1}).enabled())
+	assert.True(t, newRateLimiter(rateLimitConfig{
+		routes: map[string]routeLimit{"chat": {limit: 1, window: time.Second}},
+	}).enabled())
+}
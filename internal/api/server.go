@@ -11,13 +11,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"time"
 
 	ragagent "github.com/mfmezger/agentic_rag_go/internal/agent"
+	"github.com/mfmezger/agentic_rag_go/internal/answercache"
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/chunking"
 	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/enrich"
+	"github.com/mfmezger/agentic_rag_go/internal/feedback"
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
 	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/session"
@@ -25,18 +31,25 @@ import (
 
 	"github.com/google/uuid"
 	httpSwagger "github.com/swaggo/http-swagger"
-	"github.com/tmc/langchaingo/textsplitter"
 )
 
 // Server is the REST API server.
 type Server struct {
-	cfg          *config.Config
-	qdrant       *qdrant.Client
-	mux          *http.ServeMux
-	splitter     textsplitter.TextSplitter
-	agentFactory *ragagent.Factory
-	middleware   *middleware
-	apiVersion   string
+	cfg           *config.Config
+	qdrant        *qdrant.Client
+	mux           *http.ServeMux
+	agentFactory  *ragagent.Factory
+	embedder      chunking.Embedder
+	queryEmbedder queryEmbedder
+	enricher      *enrich.Enricher
+	keys          *auth.KeyStore
+	usage         *usage.Tracker
+	feedback      *feedback.Store
+	answers       *answercache.Cache
+	cors          *corsPolicy
+	webPolicy     *websearch.Policy
+	middleware    *middleware
+	apiVersion    string
 }
 
 // NewServer creates a new API server.
@@ -57,11 +70,15 @@ func NewServer(ctx context.Context, cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to ensure collection: %w", err)
 	}
 
-	// Create text splitter using langchaingo
-	splitter := textsplitter.NewRecursiveCharacter(
-		textsplitter.WithChunkSize(cfg.Retriever.ChunkSize),
-		textsplitter.WithChunkOverlap(cfg.Retriever.ChunkOverlap),
-	)
+	// Points stored before multi-tenancy have no tenant; they belong to
+	// the default tenant, the only one there was
+	legacyTenant := cfg.Server.DefaultTenant
+	if legacyTenant == "" {
+		legacyTenant = auth.DefaultTenant
+	}
+	if _, err := qdrantClient.AssignTenant(ctx, cfg.VectorStore.Collection, legacyTenant); err != nil {
+		return nil, fmt.Errorf("failed to assign points without tenant: %w", err)
+	}
 
 	// Create agent factory
 	agentFactory, err := ragagent.NewFactory(ctx, cfg, qdrantClient)
@@ -69,20 +86,70 @@ func NewServer(ctx context.Context, cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to create agent factory: %w", err)
 	}
 
+	enricher, err := enrich.New(enrich.Config{
+		Template: cfg.Retriever.Enrichment.Template,
+		MaxChars: cfg.Retriever.Enrichment.MaxChars,
+	}, agentFactory.Model())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create enricher: %w", err)
+	}
+
+	// Create API key store and bearer token verifier (nil when not configured)
+	keys, err := newKeyStore(cfg.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key store: %w", err)
+	}
+	jwtVerifier, err := newJWTVerifier(cfg.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwt verifier: %w", err)
+	}
+	rateLimits, err := newRateLimitConfig(cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+	cors, err := newCORSPolicy(cfg.Server.CORS)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create token usage tracker
+	usageTracker, err := newUsageTracker(cfg.Usage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create usage tracker: %w", err)
+	}
+
+	feedbackStore, err := newFeedbackStore(cfg.Feedback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feedback store: %w", err)
+	}
+
 	s := &Server{
-		cfg:          cfg,
-		qdrant:       qdrantClient,
-		mux:          http.NewServeMux(),
-		splitter:     splitter,
-		agentFactory: agentFactory,
+		cfg:           cfg,
+		qdrant:        qdrantClient,
+		mux:           http.NewServeMux(),
+		agentFactory:  agentFactory,
+		embedder:      agentFactory.EmbeddingService(),
+		queryEmbedder: agentFactory.EmbeddingService(),
+		enricher:      enricher,
+		keys:          keys,
+		usage:         usageTracker,
+		feedback:      feedbackStore,
+		answers:       newAnswerCache(cfg.AnswerCache),
+		cors:          cors,
+		webPolicy:     agentFactory.WebSearchPolicy(),
 		middleware: newMiddleware(
-			cfg.Server.APIKey,
-			cfg.Server.RateLimit,
-			time.Duration(cfg.Server.RateWindow)*time.Second,
+			keys,
+			jwtVerifier,
+			cfg.Server.DefaultTenant,
+			rateLimits,
 		),
 		apiVersion: "v1",
 	}
 
+	if s.middleware.rateLimiter.enabled() {
+		s.middleware.rateLimiter.startEviction(rateLimits.window)
+	}
+
 	// Register routes
 	s.registerRoutes()
 
@@ -95,42 +162,59 @@ func (s *Server) registerRoutes() {
 
 	s.mux.HandleFunc("GET /health", s.handleHealth)
 
-	s.mux.HandleFunc("POST "+v1Prefix+"/upload_text",
-		s.middleware.rateLimit(s.middleware.auth(s.handleUploadText)))
-	s.mux.HandleFunc("POST "+v1Prefix+"/search",
-		s.middleware.rateLimit(s.middleware.auth(s.handleSearch)))
-	s.mux.HandleFunc("POST "+v1Prefix+"/chat",
-		s.middleware.rateLimit(s.middleware.auth(s.handleChat)))
+	s.mux.HandleFunc("POST "+v1Prefix+"/upload_text", s.protect(auth.ScopeIngest, s.handleUploadText))
+	s.mux.HandleFunc("POST "+v1Prefix+"/search", s.protect(auth.ScopeSearch, s.handleSearch))
+	s.mux.HandleFunc("POST "+v1Prefix+"/chat", s.protect(auth.ScopeChat, s.handleChat))
+	s.mux.HandleFunc("POST "+v1Prefix+"/feedback", s.protectAs("feedback", auth.ScopeChat, s.handleFeedback))
+
+	s.mux.HandleFunc("POST "+v1Prefix+"/documents/upload", s.protect(auth.ScopeIngest, s.handleUploadTextV2))
+	s.mux.HandleFunc("POST "+v1Prefix+"/documents/preview", s.protect(auth.ScopeIngest, s.handlePreviewChunks))
+	s.mux.HandleFunc("POST "+v1Prefix+"/documents/search", s.protect(auth.ScopeSearch, s.handleSearchV2))
+	s.mux.HandleFunc("POST "+v1Prefix+"/conversations/chat", s.protect(auth.ScopeChat, s.handleChatV2))
 
-	s.mux.HandleFunc("POST "+v1Prefix+"/documents/upload",
-		s.middleware.rateLimit(s.middleware.auth(s.handleUploadTextV2)))
-	s.mux.HandleFunc("POST "+v1Prefix+"/documents/search",
-		s.middleware.rateLimit(s.middleware.auth(s.handleSearchV2)))
-	s.mux.HandleFunc("POST "+v1Prefix+"/conversations/chat",
-		s.middleware.rateLimit(s.middleware.auth(s.handleChatV2)))
+	s.mux.HandleFunc("GET "+v1Prefix+"/usage", s.authenticated(s.handleUsage))
+
+	s.mux.HandleFunc("POST "+v1Prefix+"/admin/keys", s.protect(auth.ScopeAdmin, s.requireKeyStore(s.handleCreateKey)))
+	s.mux.HandleFunc("GET "+v1Prefix+"/admin/keys", s.protect(auth.ScopeAdmin, s.requireKeyStore(s.handleListKeys)))
+	s.mux.HandleFunc("DELETE "+v1Prefix+"/admin/keys/{id}", s.protect(auth.ScopeAdmin, s.requireKeyStore(s.handleRevokeKey)))
+	s.mux.HandleFunc("GET "+v1Prefix+"/admin/feedback", s.protect(auth.ScopeAdmin, s.handleExportFeedback))
 
 	s.mux.Handle("GET /docs/", httpSwagger.Handler(
 		httpSwagger.URL("/docs/doc.json"),
 	))
 }
 
-// ServeHTTP implements http.Handler.
-func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Add CORS headers
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// protect wraps a handler with authentication, rate limiting and a scope
+// check. The required scope doubles as the route's rate limit class.
+func (s *Server) protect(scope auth.Scope, h http.HandlerFunc) http.HandlerFunc {
+	return s.protectAs(string(scope), scope, h)
+}
 
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
+// protectAs is protect with a rate limit class of its own, for cheap
+// routes that shouldn't draw on their scope's budget.
+func (s *Server) protectAs(route string, scope auth.Scope, h http.HandlerFunc) http.HandlerFunc {
+	return s.middleware.auth(s.middleware.rateLimit(route, s.middleware.requireScope(scope, h)))
+}
+
+// authenticated wraps a handler open to every authenticated caller,
+// regardless of scope.
+func (s *Server) authenticated(h http.HandlerFunc) http.HandlerFunc {
+	return s.middleware.auth(s.middleware.rateLimit("usage", h))
+}
 
-	s.mux.ServeHTTP(w, r)
+// appName is the ADK application name used for sessions and runners.
+const appName = "agentic_rag_go"
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID(accessLog(s.cors.handler(s.mux))).ServeHTTP(w, r)
 }
 
 // Close cleans up server resources.
 func (s *Server) Close() error {
+	if s.middleware != nil {
+		s.middleware.close()
+	}
 	if s.qdrant != nil {
 		return s.qdrant.Close()
 	}
@@ -140,8 +224,7 @@ func (s *Server) Close() error {
 // Start starts the HTTP server.
 func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.cfg.Server.Host, s.cfg.Server.Port)
-	log.Printf("Starting API server on %s", addr)
-	log.Printf("Swagger docs available at http://%s/docs/", addr)
+	slog.Info("Starting API server", "addr", addr, "docs", fmt.Sprintf("http://%s/docs/", addr))
 	return http.ListenAndServe(addr, s)
 }
 
@@ -165,6 +248,21 @@ type UploadTextRequest struct {
 	Text     string            `json:"text" example:"Your document text goes here..."`
 	Metadata map[string]string `json:"metadata,omitempty" example:"author:John Doe"`
 	Source   string            `json:"source,omitempty" example:"document.pdf"`
+	// ParentDocument overrides whether small child chunks are indexed
+	// and linked to larger parent sections returned by retrieval.
+	ParentDocument *bool `json:"parent_document,omitempty" example:"true"`
+	// ChunkStrategy overrides the configured chunking strategy.
+	ChunkStrategy string `json:"chunk_strategy,omitempty" enums:"recursive,markdown,token_estimate,sentence,code,semantic" example:"markdown"`
+	// Language is the programming language for the code strategy.
+	Language string `json:"language,omitempty" example:"go"`
+	// Enrichment overrides the configured contextual enrichment mode.
+	Enrichment string `json:"enrichment,omitempty" enums:"none,template,llm" example:"template"`
+	// Title is the document title used in chunk context headers; it
+	// defaults to the "title" metadata, then the source.
+	Title string `json:"title,omitempty" example:"Upgrade Guide"`
+	// Summary is a one-line document summary for chunk context headers.
+	// In llm mode the model writes it when empty.
+	Summary string `json:"summary,omitempty" example:"How to upgrade the server to version 2."`
 }
 
 // UploadTextResponse is the response for upload_text.
@@ -172,6 +270,11 @@ type UploadTextResponse struct {
 	Message    string   `json:"message" example:"Text uploaded and chunked successfully"`
 	ChunkCount int      `json:"chunk_count" example:"5"`
 	ChunkIDs   []string `json:"chunk_ids"`
+	// DocumentID is shared by all chunks of the upload.
+	DocumentID string `json:"document_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	// ParentCount is the number of parent sections, set when the upload
+	// was indexed with parent documents.
+	ParentCount int `json:"parent_count,omitempty" example:"2"`
 }
 
 // ErrorResponse represents an error response.
@@ -189,6 +292,7 @@ type ErrorResponse struct {
 //	@Param			request	body		UploadTextRequest	true	"Text to upload"
 //	@Success		200		{object}	UploadTextResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/upload_text [post]
 func (s *Server) handleUploadText(w http.ResponseWriter, r *http.Request) {
@@ -203,8 +307,24 @@ func (s *Server) handleUploadText(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Split text into chunks using langchaingo
-	chunks, err := s.splitter.SplitText(req.Text)
+	principal := s.principalFrom(r)
+	if !s.checkQuota(w, principal) {
+		return
+	}
+	ctx, record := s.meterUsage(r.Context(), principal)
+	defer record()
+
+	chunker, err := s.uploadChunker(req, nil, nil)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid chunking options: "+err.Error())
+		return
+	}
+	mode, err := s.enrichmentMode(req)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid enrichment options: "+err.Error())
+		return
+	}
+	chunks, parentCount, err := chunker.split(ctx, req.Text)
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, "Failed to split text: "+err.Error())
 		return
@@ -215,52 +335,60 @@ func (s *Server) handleUploadText(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate embeddings for all chunks using Gemini
-	embeddings, err := s.agentFactory.EmbeddingService().EmbedDocuments(r.Context(), chunks)
+	// Generate embeddings for all chunks using Gemini. Enriched chunks are
+	// embedded with their context header; the stored content stays the
+	// original text.
+	texts, err := s.enrichChunks(ctx, mode, req, chunks)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to enrich chunks: "+err.Error())
+		return
+	}
+	embeddings, err := s.agentFactory.EmbeddingService().EmbedDocuments(ctx, texts)
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, "Failed to generate embeddings: "+err.Error())
 		return
 	}
 
-	// Prepare documents for Qdrant
+	// Prepare documents for Qdrant. The shared document ID lets retrieval
+	// find a chunk's neighbours.
 	docs := make([]qdrant.Document, len(chunks))
 	chunkIDs := make([]string, len(chunks))
+	documentID := uuid.New().String()
 
-	for i, chunk := range chunks {
+	for i, c := range chunks {
 		id := uuid.New().String()
 		chunkIDs[i] = id
 
-		// Merge metadata
-		metadata := make(map[string]string)
-		for k, v := range req.Metadata {
-			metadata[k] = v
-		}
-		if req.Source != "" {
-			metadata["source"] = req.Source
-		}
-		metadata["chunk_index"] = fmt.Sprintf("%d", i)
-
 		docs[i] = qdrant.Document{
 			ID:       id,
-			Content:  chunk,
-			Metadata: metadata,
+			Content:  c.Text,
+			Metadata: chunkMetadata(req, c, documentID, i),
 			Dense:    embeddings[i],
 			Sparse:   nil, // TODO: Add BM25 sparse vector for hybrid search
 		}
 	}
 
-	// Store in Qdrant
-	if err := s.qdrant.Upsert(r.Context(), s.cfg.VectorStore.Collection, docs); err != nil {
+	// Store in Qdrant, owned by the caller's tenant
+	if err := s.qdrant.Upsert(ctx, s.cfg.VectorStore.Collection, principal.Tenant, docs); err != nil {
 		s.writeError(w, http.StatusInternalServerError, "Failed to store documents: "+err.Error())
 		return
 	}
-
-	log.Printf("Uploaded %d chunks from text (source: %s)", len(chunks), req.Source)
+	// Answers drawn from the tenant's old chunks may now be outdated
+	s.invalidateAnswers(principal.Tenant)
+
+	slog.InfoContext(ctx, "Uploaded text",
+		"chunks", len(chunks),
+		"parents", parentCount,
+		"source", req.Source,
+		"tenant", principal.Tenant,
+	)
 
 	s.writeJSON(w, http.StatusOK, UploadTextResponse{
-		Message:    "Text uploaded and chunked successfully",
-		ChunkCount: len(chunks),
-		ChunkIDs:   chunkIDs,
+		Message:     "Text uploaded and chunked successfully",
+		ChunkCount:  len(chunks),
+		ChunkIDs:    chunkIDs,
+		DocumentID:  documentID,
+		ParentCount: parentCount,
 	})
 }
 
@@ -268,11 +396,30 @@ func (s *Server) handleUploadText(w http.ResponseWriter, r *http.Request) {
 type SearchRequest struct {
 	Query string `json:"query" example:"What is machine learning?"`
 	TopK  int    `json:"top_k,omitempty" example:"5"`
+	// Rerank overrides whether the configured reranker runs.
+	Rerank *bool `json:"rerank,omitempty" example:"true"`
+	// Expand overrides whether the query is expanded into several
+	// searches fused with reciprocal rank fusion.
+	Expand *bool `json:"expand,omitempty" example:"false"`
+	// HyDE overrides whether a hypothetical answer passage is embedded
+	// in place of the query.
+	HyDE *bool `json:"hyde,omitempty" example:"false"`
+	// MMR overrides whether results are re-selected by maximal marginal
+	// relevance to reduce near-duplicates.
+	MMR *bool `json:"mmr,omitempty" example:"true"`
+	// ContextWindow overrides whether each result is expanded with its
+	// neighbouring chunks.
+	ContextWindow *bool `json:"context_window,omitempty" example:"true"`
 }
 
 // SearchResponse is the response for search.
 type SearchResponse struct {
 	Results []SearchResultItem `json:"results"`
+	// Reranked reports whether scores come from the reranker rather than
+	// hybrid search fusion.
+	Reranked bool `json:"reranked"`
+	// Queries lists the generated alternative queries when expansion ran.
+	Queries []string `json:"queries,omitempty"`
 }
 
 // SearchResultItem is a single search result.
@@ -281,18 +428,22 @@ type SearchResultItem struct {
 	Content  string            `json:"content" example:"Machine learning is..."`
 	Score    float32           `json:"score" example:"0.95"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// Passage is the result stitched together with its neighbouring
+	// chunks, set when context window expansion ran.
+	Passage string `json:"passage,omitempty" example:"Deep learning... Machine learning is..."`
 }
 
 // handleSearch handles the POST /api/v1/search endpoint.
 //
 //	@Summary		Search documents
-//	@Description	Search for documents using hybrid vector search
+//	@Description	Search for documents using hybrid vector search, optionally reranked
 //	@Tags			search
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		SearchRequest	true	"Search query"
 //	@Success		200		{object}	SearchResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/search [post]
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -307,35 +458,51 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	topK := req.TopK
-	if topK <= 0 {
-		topK = s.cfg.Retriever.TopK
-	}
-
-	// Generate query embedding using Gemini
-	queryVector, err := s.agentFactory.EmbeddingService().EmbedQuery(r.Context(), req.Query)
-	if err != nil {
-		s.writeError(w, http.StatusInternalServerError, "Failed to generate query embedding: "+err.Error())
+	principal := s.principalFrom(r)
+	if !s.checkQuota(w, principal) {
 		return
 	}
-
-	results, err := s.qdrant.HybridSearch(r.Context(), s.cfg.VectorStore.Collection, queryVector, nil, uint64(topK))
+	ctx, record := s.meterUsage(r.Context(), principal)
+	defer record()
+
+	retrieved, err := s.agentFactory.Retrieve(ctx, principal.Tenant, req.Query, ragagent.RetrieveOptions{
+		TopK:          req.TopK,
+		Rerank:        req.Rerank,
+		Expand:        req.Expand,
+		HyDE:          req.HyDE,
+		MMR:           req.MMR,
+		ContextWindow: req.ContextWindow,
+	})
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, "Search failed: "+err.Error())
 		return
 	}
 
-	items := make([]SearchResultItem, len(results))
-	for i, r := range results {
+	items := make([]SearchResultItem, len(retrieved.Documents))
+	for i, r := range retrieved.Documents {
 		items[i] = SearchResultItem{
 			ID:       r.ID,
 			Content:  r.Content,
 			Score:    r.Score,
 			Metadata: r.Payload,
+			Passage:  r.Passage,
 		}
 	}
 
-	s.writeJSON(w, http.StatusOK, SearchResponse{Results: items})
+	s.writeJSON(w, http.StatusOK, SearchResponse{
+		Results:  items,
+		Reranked: retrieved.Reranked,
+		Queries:  retrieved.Queries,
+	})
+}
+
+// principalFrom returns the authenticated principal of the request.
+// Routes without auth fall back to the default tenant.
+func (s *Server) principalFrom(r *http.Request) *auth.Principal {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		return p
+	}
+	return &auth.Principal{Tenant: s.middleware.defaultTenant}
 }
 
 // writeJSON writes a JSON response.
@@ -363,28 +530,64 @@ func (s *Server) handleChatV2(w http.ResponseWriter, r *http.Request) {
 }
 
 // ChatRequest is the request body for chat.
+// UserID is ignored for bearer token callers, whose identity comes from the
+// token; for API keys it is honoured but always scoped to the key's tenant.
 type ChatRequest struct {
 	Message   string `json:"message" example:"What is machine learning?"`
 	SessionID string `json:"session_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
 	UserID    string `json:"user_id,omitempty" example:"user123"`
+	// Rerank overrides whether the configured reranker runs.
+	Rerank *bool `json:"rerank,omitempty" example:"true"`
+	// Expand overrides whether the query is expanded into several
+	// searches fused with reciprocal rank fusion.
+	Expand *bool `json:"expand,omitempty" example:"false"`
+	// MMR overrides whether results are re-selected by maximal marginal
+	// relevance to reduce near-duplicates.
+	MMR *bool `json:"mmr,omitempty" example:"true"`
+	// ContextWindow overrides whether each retrieved document is expanded
+	// with its neighbouring chunks.
+	ContextWindow *bool `json:"context_window,omitempty" example:"true"`
+	// WebSearch overrides the web search mode. Relaxing the configured
+	// mode requires an override role; tightening it is always allowed.
+	WebSearch string `json:"web_search,omitempty" enums:"disabled,fallback,always" example:"disabled"`
 }
 
 // ChatResponse is the response for chat.
 type ChatResponse struct {
 	Response  string `json:"response"`
 	SessionID string `json:"session_id"`
+	// MessageID identifies this response for feedback.
+	MessageID string `json:"message_id" example:"9b2f6c1e-4a7d-4f0e-8c3b-2d5e6f7a8b9c"`
+	// RetrievalQuery is the standalone query used for retrieval when the
+	// message was rewritten using the conversation history.
+	RetrievalQuery string `json:"retrieval_query,omitempty" example:"What are the pricing tiers of Qdrant Cloud?"`
+	// WebSearch is the web search mode applied to this request.
+	WebSearch string `json:"web_search" example:"fallback"`
+	// WebSearchUsed reports whether any part of the response is derived
+	// from web search.
+	WebSearchUsed bool `json:"web_search_used"`
+	// WebSources lists the web pages the response drew on.
+	WebSources []WebSource `json:"web_sources,omitempty"`
+	// Citations lists the retrieved chunks the response was given with.
+	Citations []Citation `json:"citations,omitempty"`
+	// Cached reports whether the response was reused from an earlier,
+	// similar question.
+	Cached bool `json:"cached"`
 }
 
 // handleChat handles the POST /api/v1/chat endpoint.
 //
 //	@Summary		Chat with RAG agent
-//	@Description	Send a message to the RAG agent which searches internal docs first, then web
+//	@Description	Send a message to the RAG agent which searches internal docs first, then web. With the answer cache enabled, the first question of a session may be answered from an earlier, similar question.
 //	@Tags			chat
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		ChatRequest	true	"Chat message"
 //	@Success		200		{object}	ChatResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/chat [post]
 func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
@@ -399,40 +602,91 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set defaults
-	userID := req.UserID
-	if userID == "" {
-		userID = "default_user"
-	}
-
 	ctx := r.Context()
 
+	// Sessions are scoped by tenant so one team can't resume another's session
+	principal := s.principalFrom(r)
+
+	webMode, ok := s.webSearchMode(w, principal, req.WebSearch)
+	if !ok {
+		return
+	}
+	userID := principal.SessionUserID(req.UserID)
+
 	// Create or get session
 	sessionID := req.SessionID
 	sessionService := s.agentFactory.SessionService()
+	var history []ragagent.Turn
+	var sess session.Session
 
 	if sessionID == "" {
 		// Create new session
 		resp, err := sessionService.Create(ctx, &session.CreateRequest{
-			AppName: "agentic_rag_go",
+			AppName: appName,
 			UserID:  userID,
 		})
 		if err != nil {
 			s.writeError(w, http.StatusInternalServerError, "Failed to create session: "+err.Error())
 			return
 		}
-		sessionID = resp.Session.ID()
+		sess = resp.Session
+		sessionID = sess.ID()
+	} else {
+		resp, err := sessionService.Get(ctx, &session.GetRequest{
+			AppName:   appName,
+			UserID:    userID,
+			SessionID: sessionID,
+		})
+		if err != nil {
+			s.writeError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		sess = resp.Session
+		history = ragagent.HistoryFromEvents(sess.Events())
+	}
+
+	if !s.checkQuota(w, principal) {
+		return
+	}
+
+	// Record tokens consumed so far, even when the agent fails midway
+	ctx, record := s.meterUsage(ctx, principal)
+	defer record()
+
+	// Follow-ups depend on the conversation, so only first questions are
+	// answered from the cache
+	var lookup *answerLookup
+	if len(history) == 0 {
+		var hit *answercache.Hit
+		hit, lookup = s.lookupAnswer(ctx, principal.Tenant, req, webMode)
+		if hit != nil {
+			s.writeCachedAnswer(ctx, w, principal.Tenant, userID, sess, req.Message, webMode, hit)
+			return
+		}
 	}
 
+	webSources := &websearch.Recorder{}
+	ctx = websearch.WithRecorder(ctx, webSources)
+
 	// Pre-fetch documents (cheap operation - runs before agent)
-	retrieved, err := s.agentFactory.Retrieve(ctx, req.Message)
+	retrieved, err := s.agentFactory.Retrieve(ctx, principal.Tenant, req.Message, ragagent.RetrieveOptions{
+		Rerank:        req.Rerank,
+		Expand:        req.Expand,
+		MMR:           req.MMR,
+		History:       history,
+		ContextWindow: req.ContextWindow,
+		QueryVector:   lookup.queryVector(),
+	})
 	if err != nil {
-		log.Printf("Warning: retrieval failed: %v", err)
+		slog.WarnContext(ctx, "Retrieval failed, continuing without context", "error", err)
 		// Continue without retrieved context - agent can still use GoogleSearch
 	}
 
 	// Create runner with pre-fetched context
-	runner, err := s.agentFactory.NewRunner(ctx, "agentic_rag_go", retrieved)
+	runner, err := s.agentFactory.NewRunner(ctx, appName, retrieved, ragagent.RunnerOptions{
+		Tenant:    principal.Tenant,
+		WebSearch: webMode,
+	})
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, "Failed to create runner: "+err.Error())
 		return
@@ -447,6 +701,9 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 			s.writeError(w, http.StatusInternalServerError, "Agent error: "+err.Error())
 			return
 		}
+		if !event.LLMResponse.Partial {
+			usage.Charge(ctx, usage.FromMetadata(event.LLMResponse.UsageMetadata))
+		}
 		if event.LLMResponse.Content == nil {
 			continue
 		}
@@ -457,8 +714,39 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	s.writeJSON(w, http.StatusOK, ChatResponse{
-		Response:  responseText,
-		SessionID: sessionID,
-	})
+	resp := ChatResponse{
+		Response:      responseText,
+		SessionID:     sessionID,
+		MessageID:     uuid.New().String(),
+		WebSearch:     string(webMode),
+		WebSearchUsed: webSources.Used(),
+		WebSources:    newWebSources(webSources.Sources()),
+	}
+	if retrieved != nil && retrieved.Condensed {
+		resp.RetrievalQuery = retrieved.Query
+	}
+	var citations []answercache.Citation
+	if retrieved != nil {
+		citations = newCitations(retrieved.Documents)
+	}
+	resp.Citations = newAPICitations(citations)
+
+	// Web results go stale independently of the knowledge base
+	if !resp.WebSearchUsed {
+		s.storeAnswer(ctx, lookup, req.Message, responseText, citations)
+	}
+
+	// Keep the answer with its retrieval so users can rate it
+	interaction := feedback.Interaction{
+		ID:             resp.MessageID,
+		SessionID:      sessionID,
+		Tenant:         principal.Tenant,
+		UserID:         userID,
+		Query:          req.Message,
+		RetrievalQuery: resp.RetrievalQuery,
+		Answer:         responseText,
+	}
+	s.recordInteraction(ctx, interaction, citations)
+
+	s.writeJSON(w, http.StatusOK, resp)
 }
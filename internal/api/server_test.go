@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
 	"github.com/mfmezger/agentic_rag_go/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -321,34 +322,52 @@ func TestSearchResultItem_EmptyMetadata(t *testing.T) {
 }
 
 func TestServer_ServeHTTP_CORSHeaders(t *testing.T) {
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+
+	cors, err := newCORSPolicy(cfg.Server.CORS)
+	require.NoError(t, err)
 	server := &Server{
-		mux: http.NewServeMux(),
+		mux:  http.NewServeMux(),
+		cors: cors,
 	}
 	server.mux.HandleFunc("GET /test", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
 	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "https://app.example.com")
 	w := httptest.NewRecorder()
 
 	server.ServeHTTP(w, req)
 
+	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
 }
 
 func TestServer_ServeHTTP_OptionsRequest(t *testing.T) {
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+
+	cors, err := newCORSPolicy(cfg.Server.CORS)
+	require.NoError(t, err)
 	server := &Server{
-		mux: http.NewServeMux(),
+		mux:  http.NewServeMux(),
+		cors: cors,
 	}
 
 	req := httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
 	w := httptest.NewRecorder()
 
 	server.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, POST, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, X-API-Key, X-Request-ID", w.Header().Get("Access-Control-Allow-Headers"))
 }
 
 func TestServer_Close(t *testing.T) {
@@ -361,9 +380,9 @@ func TestServer_Close(t *testing.T) {
 }
 
 func TestMiddleware_NewMiddleware(t *testing.T) {
-	m := newMiddleware("key", 100, time.Minute)
+	m := newMiddleware(newTestKeys(t, "key"), nil, "", rateLimitConfig{limit: 100, window: time.Minute})
 	assert.NotNil(t, m)
-	assert.Equal(t, "key", m.apiKey)
+	assert.NotNil(t, m.keys)
 	assert.NotNil(t, m.rateLimiter)
 }
 
@@ -434,3 +453,13 @@ func TestServer_Start_MethodsExist(t *testing.T) {
 	assert.NotNil(t, server.Close)
 	assert.NotNil(t, server.ServeHTTP)
 }
+
+func TestServer_PrincipalFrom(t *testing.T) {
+	server := &Server{middleware: newMiddleware(nil, nil, "fallback", rateLimitConfig{})}
+
+	req := httptest.NewRequest("POST", "/api/v1/search", nil)
+	assert.Equal(t, "fallback", server.principalFrom(req).Tenant)
+
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Tenant: "team-a"}))
+	assert.Equal(t, "team-a", server.principalFrom(req).Tenant)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
+)
+
+// UsagePeriod is the token usage of the caller in one quota period.
+type UsagePeriod struct {
+	Start            time.Time `json:"start"`
+	Reset            time.Time `json:"reset"`
+	PromptTokens     int64     `json:"prompt_tokens" example:"1200"`
+	CompletionTokens int64     `json:"completion_tokens" example:"300"`
+	EmbeddingTokens  int64     `json:"embedding_tokens" example:"80"`
+	TotalTokens      int64     `json:"total_tokens" example:"1580"`
+	// Limit and Remaining are omitted when the period is unlimited.
+	Limit     *int64 `json:"limit,omitempty" example:"100000"`
+	Remaining *int64 `json:"remaining,omitempty" example:"98420"`
+}
+
+// UsageResponse is the response for the usage endpoint.
+type UsageResponse struct {
+	Account string      `json:"account" example:"key:3f2a9c1d5e7b8a60"`
+	Tenant  string      `json:"tenant" example:"team-a"`
+	Daily   UsagePeriod `json:"daily"`
+	Monthly UsagePeriod `json:"monthly"`
+}
+
+// newUsageTracker builds the usage tracker from configuration.
+func newUsageTracker(cfg config.UsageConfig) (*usage.Tracker, error) {
+	tenants := make(map[string]usage.Quota, len(cfg.Tenants))
+	for tenant, q := range cfg.Tenants {
+		tenants[tenant] = usage.Quota{Daily: q.DailyTokens, Monthly: q.MonthlyTokens}
+	}
+
+	return usage.NewTracker(usage.Config{
+		Quota:        usage.Quota{Daily: cfg.DailyTokens, Monthly: cfg.MonthlyTokens},
+		TenantQuotas: tenants,
+		Path:         cfg.File,
+	})
+}
+
+// handleUsage handles the GET /api/v1/usage endpoint.
+//
+//	@Summary		Token usage
+//	@Description	Returns the caller's LLM and embedding token usage and remaining quota for the current day and month (UTC)
+//	@Tags			usage
+//	@Produce		json
+//	@Success		200	{object}	UsageResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Router			/usage [get]
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	principal := s.principalFrom(r)
+
+	var report usage.Report
+	if s.usage != nil {
+		report = s.usage.Report(principal.AccountID(), principal.Tenant)
+	} else {
+		report = usage.Report{Account: principal.AccountID(), Tenant: principal.Tenant}
+	}
+
+	s.writeJSON(w, http.StatusOK, UsageResponse{
+		Account: report.Account,
+		Tenant:  report.Tenant,
+		Daily:   newUsagePeriod(report.Daily),
+		Monthly: newUsagePeriod(report.Monthly),
+	})
+}
+
+// checkQuota writes a 429 response and returns false when the caller has
+// used up a token quota.
+func (s *Server) checkQuota(w http.ResponseWriter, principal *auth.Principal) bool {
+	if s.usage == nil {
+		return true
+	}
+
+	err := s.usage.Check(principal.AccountID(), principal.Tenant)
+	var quotaErr *usage.QuotaError
+	if errors.As(err, &quotaErr) {
+		retryAfter := time.Until(quotaErr.ResetAt)
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+		s.writeError(w, http.StatusTooManyRequests, "Token quota exceeded: "+quotaErr.Error())
+		return false
+	}
+
+	return true
+}
+
+// meterUsage attaches a usage meter to ctx. The returned function records
+// everything charged to the meter for principal; call it once the request's
+// work is done, including on failure.
+func (s *Server) meterUsage(ctx context.Context, principal *auth.Principal) (context.Context, func()) {
+	meter := &usage.Meter{}
+	return usage.WithMeter(ctx, meter), func() {
+		s.recordUsage(ctx, principal, meter.Usage())
+	}
+}
+
+// recordUsage adds consumed tokens to the caller's usage.
+func (s *Server) recordUsage(ctx context.Context, principal *auth.Principal, u usage.Usage) {
+	if s.usage == nil {
+		return
+	}
+	if err := s.usage.Record(principal.AccountID(), principal.Tenant, u); err != nil {
+		slog.WarnContext(ctx, "Failed to record usage", "account", principal.AccountID(), "error", err)
+		return
+	}
+	slog.DebugContext(ctx, "Recorded usage",
+		"account", principal.AccountID(),
+		"prompt_tokens", u.PromptTokens,
+		"completion_tokens", u.CompletionTokens,
+		"embedding_tokens", u.EmbeddingTokens,
+	)
+}
+
+func newUsagePeriod(p usage.Period) UsagePeriod {
+	period := UsagePeriod{
+		Start:            p.Start,
+		Reset:            p.Reset,
+		PromptTokens:     p.Usage.PromptTokens,
+		CompletionTokens: p.Usage.CompletionTokens,
+		EmbeddingTokens:  p.Usage.EmbeddingTokens,
+		TotalTokens:      p.Usage.Total(),
+	}
+	if p.Limit > 0 {
+		limit, remaining := p.Limit, p.Remaining()
+		period.Limit = &limit
+		period.Remaining = &remaining
+	}
+	return period
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/config"
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsageTestServer(t *testing.T, cfg config.UsageConfig) *Server {
+	t.Helper()
+	tracker, err := newUsageTracker(cfg)
+	require.NoError(t, err)
+
+	s := newAdminTestServer(t)
+	s.usage = tracker
+	return s
+}
+
+func TestUsage_Endpoint(t *testing.T) {
+	s := newUsageTestServer(t, config.UsageConfig{
+		DailyTokens: 1000,
+		Tenants:     map[string]config.QuotaConfig{"team-a": {DailyTokens: 500}},
+	})
+
+	secret, key, err := s.keys.Mint("frontend", "team-a", []auth.Scope{auth.ScopeSearch}, 0)
+	require.NoError(t, err)
+
+	principal := &auth.Principal{Tenant: "team-a", KeyID: key.ID}
+	s.recordUsage(context.Background(), principal, usage.Usage{PromptTokens: 100, CompletionTokens: 20, EmbeddingTokens: 5})
+
+	w := doAdminRequest(t, s, "GET", "/api/v1/usage", secret, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp UsageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "key:"+key.ID, resp.Account)
+	assert.Equal(t, "team-a", resp.Tenant)
+	assert.Equal(t, int64(125), resp.Daily.TotalTokens)
+	assert.Equal(t, int64(5), resp.Daily.EmbeddingTokens)
+	require.NotNil(t, resp.Daily.Limit)
+	assert.Equal(t, int64(500), *resp.Daily.Limit)
+	assert.Equal(t, int64(375), *resp.Daily.Remaining)
+	assert.Nil(t, resp.Monthly.Limit)
+	assert.Equal(t, int64(125), resp.Monthly.TotalTokens)
+
+	// Usage is tracked per key
+	w = doAdminRequest(t, s, "GET", "/api/v1/usage", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(0), resp.Daily.TotalTokens)
+	assert.Equal(t, int64(1000), *resp.Daily.Limit)
+
+	w = doAdminRequest(t, s, "GET", "/api/v1/usage", "wrong-key", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUsage_CheckQuota(t *testing.T) {
+	s := newUsageTestServer(t, config.UsageConfig{MonthlyTokens: 100})
+	principal := &auth.Principal{Tenant: "default", Subject: "alice"}
+
+	w := httptest.NewRecorder()
+	assert.True(t, s.checkQuota(w, principal))
+
+	s.recordUsage(context.Background(), principal, usage.Usage{PromptTokens: 100})
+
+	w = httptest.NewRecorder()
+	assert.False(t, s.checkQuota(w, principal))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "monthly token quota exceeded")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Other users of the tenant keep their own quota
+	other := &auth.Principal{Tenant: "default", Subject: "bob"}
+	assert.True(t, s.checkQuota(httptest.NewRecorder(), other))
+}
+
+func TestUsage_WithoutTracker(t *testing.T) {
+	s := newAdminTestServer(t)
+	principal := &auth.Principal{Tenant: "default", KeyID: "k1"}
+
+	assert.True(t, s.checkQuota(httptest.NewRecorder(), principal))
+	s.recordUsage(context.Background(), principal, usage.Usage{PromptTokens: 1})
+
+	w := doAdminRequest(t, s, "GET", "/api/v1/usage", "admin-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+)
+
+// WebSource is a web page a chat response drew on.
+type WebSource struct {
+	Title string `json:"title" example:"docs.python.org"`
+	URL   string `json:"url" example:"https://docs.python.org/3/library/asyncio.html"`
+}
+
+// webSearchMode resolves the web search mode for a chat request, writing
+// an error response and returning false when the override is invalid or
+// not permitted.
+func (s *Server) webSearchMode(w http.ResponseWriter, principal *auth.Principal, requested string) (websearch.Mode, bool) {
+	var mode websearch.Mode
+	if requested != "" {
+		var err error
+		if mode, err = websearch.ParseMode(requested); err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid web_search: "+err.Error())
+			return "", false
+		}
+	}
+
+	mode, err := s.webPolicy.ModeFor(principal.Tenant, mode, principal.Roles, principal.HasScope(auth.ScopeAdmin))
+	if errors.Is(err, websearch.ErrOverrideDenied) {
+		s.writeError(w, http.StatusForbidden, "Not allowed to relax the web search policy")
+		return "", false
+	}
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to resolve web search policy: "+err.Error())
+		return "", false
+	}
+	return mode, true
+}
+
+func newWebSources(sources []websearch.Source) []WebSource {
+	if len(sources) == 0 {
+		return nil
+	}
+	out := make([]WebSource, len(sources))
+	for i, src := range sources {
+		out[i] = WebSource{Title: src.Title, URL: src.URL}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/auth"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSearchMode(t *testing.T) {
+	policy, err := websearch.NewPolicy(websearch.Config{
+		Mode:          "fallback",
+		TenantModes:   map[string]string{"legal": "disabled"},
+		OverrideRoles: []string{"researcher"},
+	})
+	require.NoError(t, err)
+	s := &Server{webPolicy: policy}
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		requested  string
+		wantMode   websearch.Mode
+		wantStatus int
+	}{
+		{name: "configured", principal: &auth.Principal{Tenant: "team-a"}, wantMode: websearch.ModeFallback},
+		{name: "tenant", principal: &auth.Principal{Tenant: "legal"}, wantMode: websearch.ModeDisabled},
+		{name: "tighten", principal: &auth.Principal{Tenant: "team-a"}, requested: "disabled", wantMode: websearch.ModeDisabled},
+		{name: "relax with role", principal: &auth.Principal{Tenant: "legal", Roles: []string{"researcher"}}, requested: "always", wantMode: websearch.ModeAlways},
+		{name: "relax as admin", principal: &auth.Principal{Tenant: "legal", Scopes: []auth.Scope{auth.ScopeAdmin}}, requested: "always", wantMode: websearch.ModeAlways},
+		{name: "relax denied", principal: &auth.Principal{Tenant: "team-a", Scopes: []auth.Scope{auth.ScopeChat}}, requested: "always", wantStatus: http.StatusForbidden},
+		{name: "invalid", principal: &auth.Principal{Tenant: "team-a"}, requested: "sometimes", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mode, ok := s.webSearchMode(w, tt.principal, tt.requested)
+			if tt.wantStatus != 0 {
+				assert.False(t, ok)
+				assert.Equal(t, tt.wantStatus, w.Code)
+				return
+			}
+			require.True(t, ok, w.Body.String())
+			assert.Equal(t, tt.wantMode, mode)
+		})
+	}
+}
+
+func TestNewWebSources(t *testing.T) {
+	assert.Nil(t, newWebSources(nil))
+	assert.Equal(t, []WebSource{{Title: "go.dev", URL: "https://go.dev"}},
+		newWebSources([]websearch.Source{{Title: "go.dev", URL: "https://go.dev"}}))
+}
@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// jwk is a single JSON Web Key as published in a JWKS document.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksDocument is a JSON Web Key Set.
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// parseJWKS decodes a JWKS document into public keys indexed by key ID.
+// Keys not meant for signatures or of unsupported types are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc jwksDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64url value: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// jwksCache loads a JWKS from a URL or file and caches it.
+// The set is refreshed after ttl, or early when a token references an
+// unknown key ID (at most once per minRefresh to avoid hammering the issuer).
+// Fetches run outside the lock and are shared by concurrent requests. A
+// failed fetch keeps the previous keys in service and delays the next
+// attempt with exponential backoff.
+type jwksCache struct {
+	url        string
+	file       string
+	ttl        time.Duration
+	minRefresh time.Duration
+	minRetry   time.Duration
+	maxRetry   time.Duration
+	client     *http.Client
+	now        func() time.Time
+	fetches    singleflight.Group
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time // last successful fetch
+	attempted time.Time // last fetch attempt, successful or not
+	failures  int       // consecutive failed attempts
+	lastErr   error
+}
+
+func newJWKSCache(url, file string, ttl time.Duration) *jwksCache {
+	return &jwksCache{
+		url:        url,
+		file:       file,
+		ttl:        ttl,
+		minRefresh: time.Minute,
+		minRetry:   time.Second,
+		maxRetry:   5 * time.Minute,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// key returns the public key for kid. An empty kid matches the only key
+// of a single-key set.
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	keys := c.keys
+	var refresh <-chan singleflight.Result
+	if keys == nil || c.now().Sub(c.fetchedAt) >= c.ttl {
+		refresh = c.refreshLocked(0)
+	}
+	lastErr := c.lastErr
+	c.mu.Unlock()
+
+	// Expired keys stay in service while the refresh runs in the background
+	if keys == nil {
+		if refresh == nil {
+			return nil, fmt.Errorf("jwks unavailable: %w", lastErr)
+		}
+		var err error
+		if keys, err = waitRefresh(ctx, refresh); err != nil {
+			return nil, err
+		}
+	}
+
+	if pub, ok := lookupKey(keys, kid); ok {
+		return pub, nil
+	}
+
+	// Unknown key: the issuer may have rotated keys since the last fetch
+	c.mu.Lock()
+	refresh = c.refreshLocked(c.minRefresh)
+	c.mu.Unlock()
+	if refresh != nil {
+		keys, err := waitRefresh(ctx, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if pub, ok := lookupKey(keys, kid); ok {
+			return pub, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if pub, ok := keys[kid]; ok {
+		return pub, true
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, pub := range keys {
+			return pub, true
+		}
+	}
+	return nil, false
+}
+
+// refreshLocked starts a fetch, or joins the one in flight, unless the
+// last attempt was less than interval ago or failed within the backoff
+// delay. It returns nil when no fetch may run. Callers must hold c.mu.
+func (c *jwksCache) refreshLocked(interval time.Duration) <-chan singleflight.Result {
+	if !c.attempted.IsZero() && c.now().Sub(c.attempted) < max(interval, c.retryDelayLocked()) {
+		return nil
+	}
+	// Detached from the request: other requests may be waiting on it
+	return c.fetches.DoChan("jwks", func() (any, error) {
+		return c.load(context.Background())
+	})
+}
+
+// retryDelayLocked returns the backoff after the consecutive failures so
+// far. Callers must hold c.mu.
+func (c *jwksCache) retryDelayLocked() time.Duration {
+	if c.failures == 0 {
+		return 0
+	}
+	delay := c.minRetry << min(c.failures-1, 16)
+	return min(delay, c.maxRetry)
+}
+
+// load fetches and parses the key set and records the attempt.
+func (c *jwksCache) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	keys, err := c.fetchKeys(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempted = c.now()
+	if err != nil {
+		c.failures++
+		c.lastErr = err
+		slog.Warn("JWKS refresh failed", "error", err, "failures", c.failures, "retry_in", c.retryDelayLocked())
+		return nil, err
+	}
+	c.keys = keys
+	c.fetchedAt = c.attempted
+	c.failures = 0
+	c.lastErr = nil
+	return keys, nil
+}
+
+func (c *jwksCache) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := c.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseJWKS(data)
+}
+
+// waitRefresh waits for a fetch started by refreshLocked.
+func waitRefresh(ctx context.Context, refresh <-chan singleflight.Result) (map[string]crypto.PublicKey, error) {
+	select {
+	case res := <-refresh:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(map[string]crypto.PublicKey), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *jwksCache) fetch(ctx context.Context) ([]byte, error) {
+	if c.file != "" {
+		data, err := os.ReadFile(c.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwks file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for any bearer token that fails verification.
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTConfig configures bearer token verification.
+type JWTConfig struct {
+	// JWKSURL or JWKSFile supplies the issuer's public keys.
+	JWKSURL  string
+	JWKSFile string
+	// CacheTTL controls how long a fetched key set is reused.
+	CacheTTL time.Duration
+	// Issuer and Audience, when set, must match the token's iss and aud.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp and nbf.
+	Leeway time.Duration
+	// UserClaim, TenantClaim and RolesClaim name the claims mapped onto the
+	// principal. Dotted paths such as "realm_access.roles" reach into
+	// nested objects.
+	UserClaim   string
+	TenantClaim string
+	RolesClaim  string
+	// DefaultTenant is used when the token carries no tenant claim.
+	DefaultTenant string
+	// DefaultScopes are granted to every valid token.
+	DefaultScopes []Scope
+	// RoleScopes grants additional scopes per role.
+	RoleScopes map[string][]Scope
+}
+
+// JWTVerifier validates bearer tokens and maps their claims to principals.
+type JWTVerifier struct {
+	cfg  JWTConfig
+	keys *jwksCache
+	now  func() time.Time
+}
+
+// NewJWTVerifier creates a verifier. Either a JWKS URL or file is required.
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	if cfg.JWKSURL == "" && cfg.JWKSFile == "" {
+		return nil, errors.New("jwks url or file is required")
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 10 * time.Minute
+	}
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = "tenant"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.DefaultTenant == "" {
+		cfg.DefaultTenant = DefaultTenant
+	}
+
+	return &JWTVerifier{
+		cfg:  cfg,
+		keys: newJWKSCache(cfg.JWKSURL, cfg.JWKSFile, cfg.CacheTTL),
+		now:  time.Now,
+	}, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the token's signature and standard claims and returns the
+// principal it represents.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature encoding", ErrInvalidToken)
+	}
+
+	pub, err := v.keys.key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if err := verifySignature(header.Alg, pub, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return v.principal(claims)
+}
+
+func (v *JWTVerifier) validateClaims(claims map[string]any) error {
+	now := v.now()
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if !now.Before(exp.Add(v.cfg.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(v.cfg.Leeway).Before(nbf) {
+		return errors.New("token not yet valid")
+	}
+
+	if v.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+
+	if v.cfg.Audience != "" && !slices.Contains(stringsClaim(claims["aud"]), v.cfg.Audience) {
+		return errors.New("token not issued for this audience")
+	}
+
+	return nil
+}
+
+func (v *JWTVerifier) principal(claims map[string]any) (*Principal, error) {
+	subject, _ := lookupClaim(claims, v.cfg.UserClaim).(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.cfg.UserClaim)
+	}
+
+	tenant, _ := lookupClaim(claims, v.cfg.TenantClaim).(string)
+	if tenant == "" {
+		tenant = v.cfg.DefaultTenant
+	}
+
+	roles := stringsClaim(lookupClaim(claims, v.cfg.RolesClaim))
+
+	scopes := slices.Clone(v.cfg.DefaultScopes)
+	for _, role := range roles {
+		for _, scope := range v.cfg.RoleScopes[role] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return &Principal{
+		Subject: subject,
+		Tenant:  tenant,
+		Roles:   roles,
+		Scopes:  scopes,
+	}, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("invalid segment encoding: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid segment: %w", err)
+	}
+	return nil
+}
+
+// verifySignature checks an RS* or ES* signature over signingInput.
+// Symmetric and "none" algorithms are rejected outright.
+func verifySignature(alg string, pub crypto.PublicKey, signingInput string, signature []byte) error {
+	var h hash.Hash
+	var cryptoHash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		h, cryptoHash = sha256.New(), crypto.SHA256
+	case "RS384", "ES384":
+		h, cryptoHash = sha512.New384(), crypto.SHA384
+	case "RS512", "ES512":
+		h, cryptoHash = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %q does not match rsa key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, cryptoHash, digest, signature); err != nil {
+			return errors.New("signature mismatch")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %q does not match ec key", alg)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid ecdsa signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("signature mismatch")
+		}
+	default:
+		return errors.New("unsupported key type")
+	}
+
+	return nil
+}
+
+// lookupClaim resolves a dotted claim path in nested claim objects.
+func lookupClaim(claims map[string]any, path string) any {
+	var current any = claims
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = obj[part]
+	}
+	return current
+}
+
+// stringsClaim accepts a string array, a single string or a
+// space-separated string (as used by the OAuth "scope" claim).
+func stringsClaim(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return strings.Fields(val)
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func numericClaim(claims map[string]any, name string) (time.Time, bool) {
+	n, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(n), 0), true
+}
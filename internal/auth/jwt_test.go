@@ -0,0 +1,409 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   b64(key.N.Bytes()),
+		E:   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   b64(key.X.FillBytes(make([]byte, 32))),
+		Y:   b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...jwk) string {
+	t.Helper()
+	data, err := json.Marshal(jwksDocument{Keys: keys})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return input + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":    "alice",
+		"iss":    "https://idp.example.com",
+		"aud":    []string{"rag-api"},
+		"exp":    testNow.Add(time.Hour).Unix(),
+		"tenant": "team-a",
+		"roles":  []string{"rag-admin"},
+	}
+}
+
+func newTestVerifier(t *testing.T, cfg JWTConfig) *JWTVerifier {
+	t.Helper()
+	v, err := NewJWTVerifier(cfg)
+	require.NoError(t, err)
+	v.now = func() time.Time { return testNow }
+	v.keys.now = v.now
+	return v
+}
+
+func TestNewJWTVerifier_RequiresKeySource(t *testing.T) {
+	_, err := NewJWTVerifier(JWTConfig{})
+	assert.Error(t, err)
+}
+
+func TestJWTVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v := newTestVerifier(t, JWTConfig{
+		JWKSFile:      writeJWKS(t, rsaJWK("k1", key)),
+		Issuer:        "https://idp.example.com",
+		Audience:      "rag-api",
+		DefaultScopes: []Scope{ScopeSearch, ScopeChat},
+		RoleScopes:    map[string][]Scope{"rag-admin": {ScopeAdmin}},
+	})
+
+	p, err := v.Verify(context.Background(), signToken(t, "RS256", "k1", key, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+	assert.Equal(t, "team-a", p.Tenant)
+	assert.Equal(t, []string{"rag-admin"}, p.Roles)
+	assert.Equal(t, []Scope{ScopeSearch, ScopeChat, ScopeAdmin}, p.Scopes)
+}
+
+func TestJWTVerifier_ES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	v := newTestVerifier(t, JWTConfig{JWKSFile: writeJWKS(t, ecJWK("ec1", key))})
+
+	claims := validClaims()
+	delete(claims, "tenant")
+	p, err := v.Verify(context.Background(), signToken(t, "ES256", "ec1", key, claims))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTenant, p.Tenant)
+	assert.Empty(t, p.Scopes)
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v := newTestVerifier(t, JWTConfig{
+		JWKSFile: writeJWKS(t, rsaJWK("k1", key)),
+		Issuer:   "https://idp.example.com",
+		Audience: "rag-api",
+		Leeway:   time.Minute,
+	})
+
+	with := func(mutate func(map[string]any)) map[string]any {
+		c := validClaims()
+		mutate(c)
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-jwt"},
+		{name: "wrong signer", token: signToken(t, "RS256", "k1", other, validClaims())},
+		{name: "unknown kid", token: signToken(t, "RS256", "k2", key, validClaims())},
+		{name: "alg none", token: signToken(t, "none", "k1", key, validClaims())},
+		{name: "expired", token: signToken(t, "RS256", "k1", key, with(func(c map[string]any) {
+			c["exp"] = testNow.Add(-2 * time.Minute).Unix()
+		}))},
+		{name: "missing exp", token: signToken(t, "RS256", "k1", key, with(func(c map[string]any) {
+			delete(c, "exp")
+		}))},
+		{name: "not yet valid", token: signToken(t, "RS256", "k1", key, with(func(c map[string]any) {
+			c["nbf"] = testNow.Add(time.Hour).Unix()
+		}))},
+		{name: "wrong issuer", token: signToken(t, "RS256", "k1", key, with(func(c map[string]any) {
+			c["iss"] = "https://evil.example.com"
+		}))},
+		{name: "wrong audience", token: signToken(t, "RS256", "k1", key, with(func(c map[string]any) {
+			c["aud"] = "other-api"
+		}))},
+		{name: "missing subject", token: signToken(t, "RS256", "k1", key, with(func(c map[string]any) {
+			delete(c, "sub")
+		}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestJWTVerifier_LeewayAllowsSkew(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v := newTestVerifier(t, JWTConfig{JWKSFile: writeJWKS(t, rsaJWK("k1", key)), Leeway: time.Minute})
+
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-30 * time.Second).Unix()
+	_, err = v.Verify(context.Background(), signToken(t, "RS256", "k1", key, claims))
+	assert.NoError(t, err)
+}
+
+func TestJWTVerifier_NestedClaims(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v := newTestVerifier(t, JWTConfig{
+		JWKSFile:    writeJWKS(t, rsaJWK("k1", key)),
+		UserClaim:   "email",
+		TenantClaim: "org.id",
+		RolesClaim:  "realm_access.roles",
+		RoleScopes:  map[string][]Scope{"reader": {ScopeSearch}},
+	})
+
+	claims := validClaims()
+	claims["email"] = "bob@example.com"
+	claims["org"] = map[string]any{"id": "team-b"}
+	claims["realm_access"] = map[string]any{"roles": []string{"reader", "other"}}
+
+	p, err := v.Verify(context.Background(), signToken(t, "RS256", "k1", key, claims))
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", p.Subject)
+	assert.Equal(t, "team-b", p.Tenant)
+	assert.Equal(t, []string{"reader", "other"}, p.Roles)
+	assert.Equal(t, []Scope{ScopeSearch}, p.Scopes)
+}
+
+func TestJWKSCache_URLRefreshOnUnknownKid(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	var current atomic.Value
+	current.Store(jwksDocument{Keys: []jwk{rsaJWK("k1", first)}})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(current.Load())
+	}))
+	defer srv.Close()
+
+	v := newTestVerifier(t, JWTConfig{JWKSURL: srv.URL})
+	clock := testNow
+	v.now = func() time.Time { return clock }
+	v.keys.now = v.now
+
+	_, err = v.Verify(context.Background(), signToken(t, "RS256", "k1", first, validClaims()))
+	require.NoError(t, err)
+	_, err = v.Verify(context.Background(), signToken(t, "RS256", "k1", first, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "key set should be cached")
+
+	// Issuer rotates keys; an unknown kid triggers a refresh once allowed
+	current.Store(jwksDocument{Keys: []jwk{rsaJWK("k2", rotated)}})
+	clock = clock.Add(2 * time.Minute)
+
+	_, err = v.Verify(context.Background(), signToken(t, "RS256", "k2", rotated, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestParseJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	enc := rsaJWK("enc", key)
+	enc.Use = "enc"
+	data, err := json.Marshal(jwksDocument{Keys: []jwk{rsaJWK("sig", key), enc, {Kty: "oct", Kid: "hmac"}}})
+	require.NoError(t, err)
+
+	keys, err := parseJWKS(data)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Contains(t, keys, "sig")
+
+	_, err = parseJWKS([]byte(`{"keys":[]}`))
+	assert.Error(t, err)
+}
+
+func TestStringsClaim(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, stringsClaim("a b"))
+	assert.Equal(t, []string{"a", "b"}, stringsClaim([]any{"a", 1, "b"}))
+	assert.Nil(t, stringsClaim(42))
+}
+
+// testClock is a settable clock safe to read from background refreshes.
+type testClock struct{ now atomic.Int64 }
+
+func newTestClock() *testClock {
+	c := &testClock{}
+	c.now.Store(testNow.UnixNano())
+	return c
+}
+
+func (c *testClock) Now() time.Time          { return time.Unix(0, c.now.Load()).UTC() }
+func (c *testClock) Advance(d time.Duration) { c.now.Add(int64(d)) }
+
+func TestJWKSCache_ServesStaleKeysWhileRefreshFails(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(jwksDocument{Keys: []jwk{rsaJWK("k1", key)}})
+	}))
+	defer srv.Close()
+
+	clock := newTestClock()
+	c := newJWKSCache(srv.URL, "", time.Hour)
+	c.now = clock.Now
+
+	_, err = c.key(context.Background(), "k1")
+	require.NoError(t, err)
+
+	// The issuer goes down after the keys expire
+	failing.Store(true)
+	clock.Advance(2 * time.Hour)
+	_, err = c.key(context.Background(), "k1")
+	require.NoError(t, err, "expired keys are served while refreshing")
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.failures == 1
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = c.key(context.Background(), "k1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "failed refresh backs off")
+}
+
+func TestJWKSCache_BackoffWithoutKeys(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	clock := newTestClock()
+	c := newJWKSCache(srv.URL, "", time.Hour)
+	c.now = clock.Now
+
+	_, err := c.key(context.Background(), "k1")
+	require.Error(t, err)
+	_, err = c.key(context.Background(), "k1")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "retry waits for the backoff")
+
+	clock.Advance(time.Second)
+	_, err = c.key(context.Background(), "k1")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// The delay doubles after each failure
+	clock.Advance(time.Second)
+	_, err = c.key(context.Background(), "k1")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+	clock.Advance(time.Second)
+	_, err = c.key(context.Background(), "k1")
+	require.Error(t, err)
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+func TestJWKSCache_ConcurrentFetch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(jwksDocument{Keys: []jwk{rsaJWK("k1", key)}})
+	}))
+	defer srv.Close()
+
+	c := newJWKSCache(srv.URL, "", time.Hour)
+	errs := make(chan error, 8)
+	for range cap(errs) {
+		go func() {
+			_, err := c.key(context.Background(), "k1")
+			errs <- err
+		}()
+	}
+
+	require.Eventually(t, func() bool { return fetches.Load() == 1 }, time.Second, 10*time.Millisecond)
+	close(release)
+	for range cap(errs) {
+		assert.NoError(t, <-errs)
+	}
+	assert.Equal(t, int32(1), fetches.Load(), "requests share one fetch")
+}
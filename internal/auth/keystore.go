@@ -0,0 +1,353 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	// ScopeSearch allows read-only document search.
+	ScopeSearch Scope = "search"
+	// ScopeIngest allows uploading documents.
+	ScopeIngest Scope = "ingest"
+	// ScopeChat allows talking to the agent.
+	ScopeChat Scope = "chat"
+	// ScopeAdmin allows managing the API keys and feedback of the caller's
+	// tenant and implies every other scope except ScopeOperator.
+	ScopeAdmin Scope = "admin"
+	// ScopeOperator extends ScopeAdmin to every tenant and implies every
+	// other scope.
+	ScopeOperator Scope = "operator"
+)
+
+// AllScopes lists every known scope.
+var AllScopes = []Scope{ScopeSearch, ScopeIngest, ScopeChat, ScopeAdmin, ScopeOperator}
+
+// UserScopes are the scopes granted to non-admin callers by default.
+var UserScopes = []Scope{ScopeSearch, ScopeIngest, ScopeChat}
+
+// ParseScope validates a scope name.
+func ParseScope(s string) (Scope, error) {
+	for _, scope := range AllScopes {
+		if string(scope) == s {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scope %q", s)
+}
+
+var (
+	// ErrInvalidKey is returned when a presented key matches no stored key.
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrKeyExpired is returned when a key is past its expiry.
+	ErrKeyExpired = errors.New("api key expired")
+	// ErrKeyRevoked is returned when a key has been revoked.
+	ErrKeyRevoked = errors.New("api key revoked")
+	// ErrKeyNotFound is returned when no key has the requested ID.
+	ErrKeyNotFound = errors.New("api key not found")
+	// ErrStaticKey is returned when trying to revoke a key defined in config.
+	ErrStaticKey = errors.New("api key is defined in configuration")
+)
+
+// keyPrefix marks secrets minted by the key store.
+const keyPrefix = "rag_"
+
+// APIKey is a stored API key. Only the SHA-256 hash of the secret is kept.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Tenant    string     `json:"tenant"`
+	Scopes    []Scope    `json:"scopes"`
+	Hash      string     `json:"hash"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+
+	// static keys come from configuration and are never persisted.
+	static bool
+}
+
+// HasScope reports whether the key grants scope.
+func (k *APIKey) HasScope(scope Scope) bool {
+	return hasScope(k.Scopes, scope)
+}
+
+// Static reports whether the key is defined in configuration.
+func (k *APIKey) Static() bool {
+	return k.static
+}
+
+// KeyStore holds API keys and authenticates presented secrets.
+// Minted keys are persisted to a JSON file when a path is configured,
+// so keys can be created and revoked without restarting the server.
+type KeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*APIKey
+	path string
+	now  func() time.Time
+}
+
+// NewKeyStore creates a key store, loading previously minted keys from path.
+// An empty path keeps minted keys in memory only.
+func NewKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{
+		keys: make(map[string]*APIKey),
+		path: path,
+		now:  time.Now,
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var keys []*APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+	for _, k := range keys {
+		s.keys[k.ID] = k
+	}
+
+	return s, nil
+}
+
+// AddStatic registers a key defined in configuration.
+// Its ID is derived from the secret's hash so it is stable across restarts.
+func (s *KeyStore) AddStatic(secret, name, tenant string, scopes []Scope) *APIKey {
+	hash := hashSecret(secret)
+	key := &APIKey{
+		ID:        "static_" + hash[:12],
+		Name:      name,
+		Tenant:    tenant,
+		Scopes:    scopes,
+		Hash:      hash,
+		CreatedAt: s.now().UTC(),
+		static:    true,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+
+	copied := *key
+	return &copied
+}
+
+// Mint creates a new key and returns its secret. The secret is only
+// available at creation time; a ttl of zero means the key never expires.
+func (s *KeyStore) Mint(name, tenant string, scopes []Scope, ttl time.Duration) (string, *APIKey, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+	secret := keyPrefix + id + "_" + token
+
+	key := &APIKey{
+		ID:        id,
+		Name:      name,
+		Tenant:    tenant,
+		Scopes:    scopes,
+		Hash:      hashSecret(secret),
+		CreatedAt: s.now().UTC(),
+	}
+	if ttl > 0 {
+		expires := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expires
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = key
+	if err := s.saveLocked(); err != nil {
+		delete(s.keys, id)
+		return "", nil, err
+	}
+
+	copied := *key
+	return secret, &copied, nil
+}
+
+// Revoke marks a minted key as revoked.
+func (s *KeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if key.static {
+		return ErrStaticKey
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	revoked := s.now().UTC()
+	key.RevokedAt = &revoked
+	if err := s.saveLocked(); err != nil {
+		key.RevokedAt = nil
+		return err
+	}
+
+	return nil
+}
+
+// Get returns a copy of the key with id.
+func (s *KeyStore) Get(id string) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return APIKey{}, ErrKeyNotFound
+	}
+	return *key, nil
+}
+
+// List returns copies of all keys ordered by creation time.
+func (s *KeyStore) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, *k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys
+}
+
+// Len returns the number of stored keys, including revoked ones.
+func (s *KeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+// Authenticate returns the key matching secret.
+// Every stored hash is compared in constant time so the response time
+// doesn't reveal which key, if any, was close to matching.
+func (s *KeyStore) Authenticate(secret string) (*APIKey, error) {
+	if secret == "" {
+		return nil, ErrInvalidKey
+	}
+	presented := []byte(hashSecret(secret))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var match *APIKey
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(presented, []byte(k.Hash)) == 1 {
+			match = k
+		}
+	}
+
+	if match == nil {
+		return nil, ErrInvalidKey
+	}
+	if match.RevokedAt != nil {
+		return nil, ErrKeyRevoked
+	}
+	if match.ExpiresAt != nil && !s.now().Before(*match.ExpiresAt) {
+		return nil, ErrKeyExpired
+	}
+
+	copied := *match
+	return &copied, nil
+}
+
+// saveLocked writes minted keys to disk. Callers must hold s.mu.
+func (s *KeyStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		if !k.static {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated key file
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".keys-*")
+	if err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	return nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hasScope(scopes []Scope, scope Scope) bool {
+	for _, s := range scopes {
+		if s == scope || s == ScopeOperator || (s == ScopeAdmin && scope != ScopeOperator) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScope(t *testing.T) {
+	scope, err := ParseScope("chat")
+	require.NoError(t, err)
+	assert.Equal(t, ScopeChat, scope)
+
+	_, err = ParseScope("superuser")
+	assert.Error(t, err)
+}
+
+func TestKeyStore_MintAndAuthenticate(t *testing.T) {
+	store, err := NewKeyStore("")
+	require.NoError(t, err)
+
+	secret, key, err := store.Mint("frontend", "team-a", []Scope{ScopeChat}, 0)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, keyPrefix+key.ID+"_"))
+	assert.NotContains(t, key.Hash, secret)
+	assert.Nil(t, key.ExpiresAt)
+
+	got, err := store.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, "team-a", got.Tenant)
+	assert.True(t, got.HasScope(ScopeChat))
+	assert.False(t, got.HasScope(ScopeIngest))
+}
+
+func TestKeyStore_AuthenticateInvalid(t *testing.T) {
+	store, err := NewKeyStore("")
+	require.NoError(t, err)
+	store.AddStatic("secret", "legacy", "default", AllScopes)
+
+	_, err = store.Authenticate("")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = store.Authenticate("wrong")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	key, err := store.Authenticate("secret")
+	require.NoError(t, err)
+	assert.True(t, key.Static())
+	assert.True(t, strings.HasPrefix(key.ID, "static_"))
+}
+
+func TestKeyStore_Expiry(t *testing.T) {
+	store, err := NewKeyStore("")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	secret, key, err := store.Mint("short-lived", "default", UserScopes, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, key.ExpiresAt)
+	assert.Equal(t, now.Add(time.Hour), *key.ExpiresAt)
+
+	_, err = store.Authenticate(secret)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	_, err = store.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrKeyExpired)
+}
+
+func TestKeyStore_Revoke(t *testing.T) {
+	store, err := NewKeyStore("")
+	require.NoError(t, err)
+	static := store.AddStatic("secret", "legacy", "default", AllScopes)
+
+	secret, key, err := store.Mint("ci", "default", UserScopes, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Revoke(key.ID))
+	_, err = store.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrKeyRevoked)
+
+	// Revoking twice is a no-op
+	assert.NoError(t, store.Revoke(key.ID))
+
+	assert.ErrorIs(t, store.Revoke("missing"), ErrKeyNotFound)
+	assert.ErrorIs(t, store.Revoke(static.ID), ErrStaticKey)
+}
+
+func TestKeyStore_Get(t *testing.T) {
+	store, err := NewKeyStore("")
+	require.NoError(t, err)
+	_, minted, err := store.Mint("ci", "team-a", UserScopes, 0)
+	require.NoError(t, err)
+
+	key, err := store.Get(minted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", key.Tenant)
+
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestKeyStore_List(t *testing.T) {
+	store, err := NewKeyStore("")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	_, first, err := store.Mint("first", "default", UserScopes, 0)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, second, err := store.Mint("second", "default", UserScopes, 0)
+	require.NoError(t, err)
+
+	keys := store.List()
+	require.Len(t, keys, 2)
+	assert.Equal(t, first.ID, keys[0].ID)
+	assert.Equal(t, second.ID, keys[1].ID)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestKeyStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	store, err := NewKeyStore(path)
+	require.NoError(t, err)
+	static := store.AddStatic("static-secret", "legacy", "default", AllScopes)
+
+	secret, key, err := store.Mint("persisted", "team-a", []Scope{ScopeSearch}, 0)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), secret)
+	assert.NotContains(t, string(data), static.ID)
+
+	reloaded, err := NewKeyStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded.Len())
+
+	got, err := reloaded.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, "team-a", got.Tenant)
+
+	_, err = reloaded.Authenticate("static-secret")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestNewKeyStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := NewKeyStore(path)
+	assert.Error(t, err)
+}
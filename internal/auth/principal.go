@@ -0,0 +1,72 @@
+// Package auth provides request principals and tenant scoping.
+package auth
+
+import "context"
+
+// DefaultTenant is the tenant used when no tenant is configured.
+const DefaultTenant = "default"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller within its tenant, e.g. a token's sub.
+	// Empty for shared credentials such as an API key.
+	Subject string
+	// Tenant isolates documents and sessions between teams.
+	Tenant string
+	// KeyID is the ID of the API key used to authenticate, if any.
+	KeyID string
+	// Roles are the identity provider roles of a bearer token caller.
+	Roles []string
+	// Scopes are the permissions granted to the caller.
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	return hasScope(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// SessionUserID returns the tenant-scoped user ID used for session storage.
+// The principal's subject takes precedence; the requested ID is only used
+// for shared credentials that carry no subject.
+func (p *Principal) SessionUserID(requested string) string {
+	user := p.Subject
+	if user == "" {
+		user = requested
+	}
+	if user == "" {
+		user = "default_user"
+	}
+	return p.Tenant + "/" + user
+}
+
+// AccountID identifies who the request is accounted to: the API key for key
+// callers, the tenant-scoped subject for bearer token callers and the tenant
+// itself for anonymous callers (authentication disabled).
+func (p *Principal) AccountID() string {
+	switch {
+	case p.KeyID != "":
+		return "key:" + p.KeyID
+	case p.Subject != "":
+		return "user:" + p.Tenant + "/" + p.Subject
+	}
+	return "tenant:" + p.Tenant
+}
+
+// Anonymous reports whether the principal carries no credential identity.
+func (p *Principal) Anonymous() bool {
+	return p.KeyID == "" && p.Subject == ""
+}
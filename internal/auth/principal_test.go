@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext_Missing(t *testing.T) {
+	p, ok := FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Nil(t, p)
+}
+
+func TestWithPrincipal_RoundTrip(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &Principal{Subject: "alice", Tenant: "team-a"})
+
+	p, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", p.Subject)
+	assert.Equal(t, "team-a", p.Tenant)
+}
+
+func TestWithPrincipal_Nil(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), nil)
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestPrincipal_SessionUserID(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		requested string
+		want      string
+	}{
+		{
+			name:      "subject wins over requested",
+			principal: Principal{Subject: "alice", Tenant: "team-a"},
+			requested: "mallory",
+			want:      "team-a/alice",
+		},
+		{
+			name:      "shared credential uses requested",
+			principal: Principal{Tenant: "team-a"},
+			requested: "bob",
+			want:      "team-a/bob",
+		},
+		{
+			name:      "default user",
+			principal: Principal{Tenant: "team-b"},
+			want:      "team-b/default_user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.principal.SessionUserID(tt.requested))
+		})
+	}
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	p := &Principal{Scopes: []Scope{ScopeSearch}}
+	assert.True(t, p.HasScope(ScopeSearch))
+	assert.False(t, p.HasScope(ScopeIngest))
+
+	admin := &Principal{Scopes: []Scope{ScopeAdmin}}
+	assert.True(t, admin.HasScope(ScopeIngest))
+	assert.True(t, admin.HasScope(ScopeChat))
+	assert.False(t, admin.HasScope(ScopeOperator), "admins stay within their tenant")
+
+	operator := &Principal{Scopes: []Scope{ScopeOperator}}
+	assert.True(t, operator.HasScope(ScopeAdmin))
+	assert.True(t, operator.HasScope(ScopeSearch))
+}
+
+func TestPrincipal_AccountID(t *testing.T) {
+	key := &Principal{Tenant: "team-a", KeyID: "k1", Subject: "ignored"}
+	assert.Equal(t, "key:k1", key.AccountID())
+	assert.False(t, key.Anonymous())
+
+	user := &Principal{Tenant: "team-a", Subject: "alice"}
+	assert.Equal(t, "user:team-a/alice", user.AccountID())
+	assert.False(t, user.Anonymous())
+
+	anon := &Principal{Tenant: "team-a"}
+	assert.Equal(t, "tenant:team-a", anon.AccountID())
+	assert.True(t, anon.Anonymous())
+}
@@ -0,0 +1,164 @@
+// Package chunking splits documents into chunks for embedding.
+package chunking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
+
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// Strategy names a way of splitting documents.
+type Strategy string
+
+// Chunking strategies.
+const (
+	// StrategyRecursive splits on paragraphs, lines, then words.
+	StrategyRecursive Strategy = "recursive"
+	// StrategyMarkdown splits along Markdown headings and keeps code
+	// blocks and tables whole.
+	StrategyMarkdown Strategy = "markdown"
+	// StrategyTokenEstimate splits like StrategyRecursive but sizes chunks
+	// in estimated tokens, about four characters each, rather than with
+	// the embedding model's tokenizer. Leave headroom below the model's
+	// input limit.
+	StrategyTokenEstimate Strategy = "token_estimate"
+	// StrategySentence packs whole sentences into chunks.
+	StrategySentence Strategy = "sentence"
+	// StrategyCode splits source code on language constructs.
+	StrategyCode Strategy = "code"
+	// StrategySemantic cuts where the topic shifts between sentences,
+	// judged by their embeddings.
+	StrategySemantic Strategy = "semantic"
+)
+
+// Payload keys set by chunkers.
+const (
+	// HeadingField holds the heading of the section a chunk belongs to.
+	HeadingField = "heading"
+	// HeadingPathField holds the breadcrumb of headings leading to the
+	// chunk, joined by HeadingSeparator.
+	HeadingPathField = "heading_path"
+	// LanguageField holds the programming language of a code chunk.
+	LanguageField = "code_language"
+)
+
+// HeadingSeparator joins the headings of a breadcrumb.
+const HeadingSeparator = " > "
+
+var (
+	// ErrUnknownStrategy is returned for an unsupported strategy name.
+	ErrUnknownStrategy = errors.New("unknown chunking strategy")
+	// ErrUnknownLanguage is returned when code chunking is asked for a
+	// language it has no separators for.
+	ErrUnknownLanguage = errors.New("unknown code language")
+)
+
+// Chunk is a piece of a document with the metadata the chunker derived
+// for it.
+type Chunk struct {
+	Text     string
+	Metadata map[string]string
+}
+
+// Chunker splits text into chunks.
+type Chunker interface {
+	Chunk(ctx context.Context, text string) ([]Chunk, error)
+}
+
+// Config selects and sizes a chunker.
+type Config struct {
+	Strategy Strategy
+	// Size and Overlap are measured in estimated tokens for
+	// StrategyTokenEstimate and in characters otherwise.
+	Size    int
+	Overlap int
+	// Language is the programming language for StrategyCode.
+	Language string
+	// Embedder and Semantic configure StrategySemantic, which ignores
+	// Size and Overlap.
+	Embedder Embedder
+	Semantic SemanticConfig
+}
+
+// ParseStrategy parses a strategy name. An empty name means
+// StrategyRecursive.
+func ParseStrategy(name string) (Strategy, error) {
+	switch s := Strategy(name); s {
+	case "":
+		return StrategyRecursive, nil
+	case StrategyRecursive, StrategyMarkdown, StrategyTokenEstimate, StrategySentence, StrategyCode, StrategySemantic:
+		return s, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownStrategy, name)
+	}
+}
+
+// New creates the chunker for cfg.
+func New(cfg Config) (Chunker, error) {
+	switch cfg.Strategy {
+	case "", StrategyRecursive:
+		return splitter{textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(cfg.Size),
+			textsplitter.WithChunkOverlap(cfg.Overlap),
+		), nil}, nil
+	case StrategyTokenEstimate:
+		return splitter{textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(cfg.Size),
+			textsplitter.WithChunkOverlap(cfg.Overlap),
+			textsplitter.WithLenFunc(EstimateTokens),
+		), nil}, nil
+	case StrategyMarkdown:
+		return NewMarkdown(cfg.Size, cfg.Overlap), nil
+	case StrategySentence:
+		return NewSentence(cfg.Size, cfg.Overlap), nil
+	case StrategyCode:
+		return NewCode(cfg.Language, cfg.Size, cfg.Overlap)
+	case StrategySemantic:
+		return NewSemantic(cfg.Embedder, cfg.Semantic)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownStrategy, cfg.Strategy)
+	}
+}
+
+// EstimateTokens estimates the number of embedding model tokens in text
+// from its length, using the same estimate as usage accounting.
+func EstimateTokens(text string) int {
+	return int(usage.EstimateTokens(text))
+}
+
+// splitter adapts a langchaingo text splitter, stamping every chunk with
+// fixed metadata.
+type splitter struct {
+	splitter textsplitter.TextSplitter
+	metadata map[string]string
+}
+
+func (s splitter) Chunk(ctx context.Context, text string) ([]Chunk, error) {
+	texts, err := s.splitter.SplitText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]Chunk, len(texts))
+	for i, t := range texts {
+		chunks[i] = Chunk{Text: t, Metadata: copyMetadata(s.metadata)}
+	}
+	return chunks, nil
+}
+
+// copyMetadata returns a copy of m, or nil when m is empty, so chunks never
+// share a map.
+func copyMetadata(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package chunking
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Strategy
+		wantErr bool
+	}{
+		{name: "", want: StrategyRecursive},
+		{name: "markdown", want: StrategyMarkdown},
+		{name: "token_estimate", want: StrategyTokenEstimate},
+		{name: "sentence", want: StrategySentence},
+		{name: "code", want: StrategyCode},
+		{name: "paragraph", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStrategy(tt.name)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownStrategy)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, s := range []Strategy{"", StrategyRecursive, StrategyMarkdown, StrategyTokenEstimate, StrategySentence} {
+		c, err := New(Config{Strategy: s, Size: 100, Overlap: 10})
+		require.NoError(t, err, s)
+		assert.NotNil(t, c, s)
+	}
+
+	_, err := New(Config{Strategy: StrategyCode, Size: 100})
+	assert.ErrorIs(t, err, ErrUnknownLanguage)
+
+	_, err = New(Config{Strategy: "paragraph"})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+}
+
+func TestTokenStrategy(t *testing.T) {
+	c, err := New(Config{Strategy: StrategyTokenEstimate, Size: 10})
+	require.NoError(t, err)
+
+	chunks, err := c.Chunk(context.Background(), strings.Repeat("word ", 40))
+	require.NoError(t, err)
+	require.Greater(t, len(chunks), 1)
+	for _, ch := range chunks {
+		assert.LessOrEqual(t, EstimateTokens(ch.Text), 10)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("abcd"))
+	assert.Equal(t, 3, EstimateTokens("abcdefghij"))
+}
@@ -0,0 +1,75 @@
+package chunking
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// codeSeparators lists, per language, the boundaries to split source code
+// on, from the coarsest to the finest.
+var codeSeparators = map[string][]string{
+	"go": {
+		"\nfunc ", "\nvar ", "\nconst ", "\ntype ",
+		"\n\tif ", "\n\tfor ", "\n\tswitch ", "\n\tcase ",
+	},
+	"python": {
+		"\nclass ", "\ndef ", "\n\tdef ", "\n    def ",
+		"\n\tif ", "\n    if ", "\n\tfor ", "\n    for ",
+	},
+	"javascript": {
+		"\nfunction ", "\nclass ", "\nexport ", "\nconst ", "\nlet ", "\nvar ",
+		"\nif ", "\nfor ", "\nwhile ", "\nswitch ",
+	},
+	"typescript": {
+		"\nfunction ", "\nclass ", "\ninterface ", "\nenum ", "\ntype ", "\nexport ",
+		"\nconst ", "\nlet ", "\nvar ",
+		"\nif ", "\nfor ", "\nwhile ", "\nswitch ",
+	},
+	"java": {
+		"\nclass ", "\ninterface ", "\nenum ",
+		"\npublic ", "\nprotected ", "\nprivate ", "\nstatic ",
+		"\n    public ", "\n    protected ", "\n    private ",
+		"\nif ", "\nfor ", "\nwhile ", "\nswitch ",
+	},
+	"rust": {
+		"\nfn ", "\npub fn ", "\nimpl ", "\nstruct ", "\nenum ", "\ntrait ", "\nmod ",
+		"\nconst ", "\nlet ", "\nif ", "\nwhile ", "\nfor ", "\nloop ", "\nmatch ",
+	},
+}
+
+// languageAliases maps common short names to codeSeparators keys.
+var languageAliases = map[string]string{
+	"golang": "go",
+	"py":     "python",
+	"js":     "javascript",
+	"ts":     "typescript",
+	"rs":     "rust",
+}
+
+// NewCode creates a chunker that splits source code in language on
+// declarations first, then blocks, lines and words. Chunks are tagged with
+// the language.
+func NewCode(language string, size, overlap int) (Chunker, error) {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := languageAliases[lang]; ok {
+		lang = alias
+	}
+	seps, ok := codeSeparators[lang]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLanguage, language)
+	}
+
+	separators := append(append([]string{}, seps...), "\n\n", "\n", " ", "")
+	return splitter{
+		splitter: textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(size),
+			textsplitter.WithChunkOverlap(overlap),
+			textsplitter.WithSeparators(separators),
+			// Keep keywords such as "func" with the code they introduce
+			textsplitter.WithKeepSeparator(true),
+		),
+		metadata: map[string]string{LanguageField: lang},
+	}, nil
+}
@@ -0,0 +1,40 @@
+package chunking
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCode(t *testing.T) {
+	src := `package main
+
+func first() {
+	println("one")
+}
+
+func second() {
+	println("two")
+}
+`
+	c, err := NewCode("golang", 45, 0)
+	require.NoError(t, err)
+
+	chunks, err := c.Chunk(context.Background(), src)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "package main", chunks[0].Text)
+	assert.True(t, strings.HasPrefix(chunks[1].Text, "func first()"), chunks[1].Text)
+	assert.True(t, strings.HasPrefix(chunks[2].Text, "func second()"), chunks[2].Text)
+	for _, ch := range chunks {
+		assert.Equal(t, "go", ch.Metadata[LanguageField])
+	}
+}
+
+func TestNewCode_UnknownLanguage(t *testing.T) {
+	_, err := NewCode("cobol", 100, 0)
+	assert.ErrorIs(t, err, ErrUnknownLanguage)
+}
@@ -0,0 +1,196 @@
+package chunking
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// headingPattern matches an ATX heading such as "## Install ##".
+var headingPattern = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// Markdown splits Markdown along its headings. Each section is packed from
+// whole blocks (paragraphs, fenced code blocks and tables) up to the chunk
+// size; only blocks larger than a chunk are cut, with overlap. Chunks carry
+// the heading breadcrumb of their section.
+type Markdown struct {
+	size     int
+	fallback textsplitter.TextSplitter
+}
+
+// NewMarkdown creates a Markdown chunker. Sizes are in characters.
+func NewMarkdown(size, overlap int) *Markdown {
+	return &Markdown{
+		size: size,
+		fallback: textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(size),
+			textsplitter.WithChunkOverlap(overlap),
+		),
+	}
+}
+
+// section is the text under one heading.
+type section struct {
+	path   []string
+	blocks []string
+}
+
+// Chunk implements Chunker.
+func (m *Markdown) Chunk(ctx context.Context, text string) ([]Chunk, error) {
+	var chunks []Chunk
+	for _, sec := range markdownSections(text) {
+		var metadata map[string]string
+		if len(sec.path) > 0 {
+			metadata = map[string]string{
+				HeadingField:     sec.path[len(sec.path)-1],
+				HeadingPathField: strings.Join(sec.path, HeadingSeparator),
+			}
+		}
+
+		texts, err := m.pack(sec.blocks)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range texts {
+			chunks = append(chunks, Chunk{Text: t, Metadata: copyMetadata(metadata)})
+		}
+	}
+	return chunks, nil
+}
+
+// pack joins consecutive blocks into chunks of at most size characters.
+func (m *Markdown) pack(blocks []string) ([]string, error) {
+	var texts []string
+	var current []string
+	length := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			texts = append(texts, strings.Join(current, "\n\n"))
+			current, length = nil, 0
+		}
+	}
+
+	for _, block := range blocks {
+		n := len([]rune(block))
+		if n > m.size {
+			flush()
+			parts, err := m.fallback.SplitText(block)
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, parts...)
+			continue
+		}
+		if len(current) > 0 && length+2+n > m.size {
+			flush()
+		}
+		if len(current) > 0 {
+			length += 2
+		}
+		current = append(current, block)
+		length += n
+	}
+	flush()
+
+	return texts, nil
+}
+
+// markdownSections splits text into sections at headings outside code
+// blocks. The heading line starts its section's first block.
+func markdownSections(text string) []section {
+	var sections []section
+	var stack []heading
+	current := section{}
+	var block []string
+	inFence, fence := false, ""
+	inTable := false
+
+	endBlock := func() {
+		if b := strings.TrimSpace(strings.Join(block, "\n")); b != "" {
+			current.blocks = append(current.blocks, b)
+		}
+		block = nil
+		inTable = false
+	}
+	endSection := func() {
+		endBlock()
+		if len(current.blocks) > 0 {
+			sections = append(sections, current)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if inFence {
+			block = append(block, line)
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+				endBlock()
+			}
+			continue
+		}
+
+		if f := fenceOf(trimmed); f != "" {
+			endBlock()
+			inFence, fence = true, f
+			block = append(block, line)
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(trimmed); match != nil {
+			endSection()
+			level := len(match[1])
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, heading{level: level, title: match[2]})
+
+			path := make([]string, len(stack))
+			for i, h := range stack {
+				path[i] = h.title
+			}
+			current = section{path: path}
+			block = []string{line}
+			continue
+		}
+
+		isRow := strings.HasPrefix(trimmed, "|")
+		switch {
+		case trimmed == "":
+			endBlock()
+		case isRow && !inTable:
+			// A table is a block of its own
+			endBlock()
+			inTable = true
+			block = append(block, line)
+		case !isRow && inTable:
+			endBlock()
+			block = append(block, line)
+		default:
+			block = append(block, line)
+		}
+	}
+	endSection()
+
+	return sections
+}
+
+// heading is an entry of the heading breadcrumb.
+type heading struct {
+	level int
+	title string
+}
+
+// fenceOf returns the fence that opens a code block on line, or "".
+func fenceOf(line string) string {
+	for _, f := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, f) {
+			return f
+		}
+	}
+	return ""
+}
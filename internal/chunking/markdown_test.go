@@ -0,0 +1,74 @@
+package chunking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const markdownDoc = `Preamble text.
+
+# Guide
+
+Intro paragraph.
+
+## Install
+
+Run the installer.
+
+` + "```sh\n# not a heading\nmake install\n```" + `
+
+## Configure ##
+
+| key | value |
+| --- | ----- |
+| port | 8080 |
+Text right after the table.
+
+# Reference
+
+Details.
+`
+
+func TestMarkdown(t *testing.T) {
+	chunks, err := NewMarkdown(200, 0).Chunk(context.Background(), markdownDoc)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, c := range chunks {
+		paths = append(paths, c.Metadata[HeadingPathField])
+	}
+	assert.Equal(t, []string{"", "Guide", "Guide > Install", "Guide > Configure", "Reference"}, paths)
+
+	assert.Nil(t, chunks[0].Metadata, "preamble has no heading")
+	assert.Equal(t, "Install", chunks[2].Metadata[HeadingField])
+	assert.Contains(t, chunks[2].Text, "# not a heading", "headings inside code blocks are ignored")
+	assert.Equal(t, "## Configure ##\n\n| key | value |\n| --- | ----- |\n| port | 8080 |\n\nText right after the table.", chunks[3].Text)
+}
+
+func TestMarkdown_PacksBlocks(t *testing.T) {
+	doc := "# Title\n\nfirst block\n\nsecond block\n\nthird block"
+
+	chunks, err := NewMarkdown(30, 0).Chunk(context.Background(), doc)
+	require.NoError(t, err)
+
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "# Title\n\nfirst block", chunks[0].Text)
+	assert.Equal(t, "second block\n\nthird block", chunks[1].Text)
+	assert.Equal(t, "Title", chunks[1].Metadata[HeadingPathField])
+}
+
+func TestMarkdown_SplitsLargeBlocks(t *testing.T) {
+	doc := "# Title\n\none two three four five six seven eight nine ten"
+
+	chunks, err := NewMarkdown(20, 0).Chunk(context.Background(), doc)
+	require.NoError(t, err)
+
+	require.Greater(t, len(chunks), 2)
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c.Text), 20)
+		assert.Equal(t, "Title", c.Metadata[HeadingField])
+	}
+}
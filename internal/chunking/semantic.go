@@ -0,0 +1,172 @@
+package chunking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// embedBatchSize caps the sentences embedded per request.
+const embedBatchSize = 100
+
+// Embedder embeds document texts.
+type Embedder interface {
+	EmbedDocuments(ctx context.Context, documents []string) ([][]float32, error)
+}
+
+// SemanticConfig tunes semantic chunking.
+type SemanticConfig struct {
+	// Percentile of adjacent-sentence similarities below which a topic
+	// boundary is assumed, between 0 and 100.
+	Percentile float64
+	// MinSize is the size in characters a chunk must reach before it is
+	// cut at a boundary.
+	MinSize int
+	// MaxSize is the size in characters at which a chunk is cut
+	// regardless of similarity.
+	MaxSize int
+}
+
+// Semantic cuts text where the topic shifts: sentences are embedded and a
+// chunk ends where the similarity of adjacent sentences drops below the
+// configured percentile of all adjacent similarities in the document.
+type Semantic struct {
+	embedder Embedder
+	cfg      SemanticConfig
+	fallback textsplitter.TextSplitter
+}
+
+// NewSemantic creates a semantic chunker.
+func NewSemantic(embedder Embedder, cfg SemanticConfig) (*Semantic, error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("semantic chunking needs an embedder")
+	}
+	if cfg.MaxSize <= 0 || cfg.MinSize < 0 || cfg.MinSize > cfg.MaxSize {
+		return nil, fmt.Errorf("semantic chunk sizes need 0 <= min (%d) <= max (%d)", cfg.MinSize, cfg.MaxSize)
+	}
+	if cfg.Percentile < 0 || cfg.Percentile > 100 {
+		return nil, fmt.Errorf("semantic percentile %v is outside 0-100", cfg.Percentile)
+	}
+
+	return &Semantic{
+		embedder: embedder,
+		cfg:      cfg,
+		fallback: textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(cfg.MaxSize),
+			textsplitter.WithChunkOverlap(0),
+		),
+	}, nil
+}
+
+// Chunk implements Chunker.
+func (s *Semantic) Chunk(ctx context.Context, text string) ([]Chunk, error) {
+	sentences := Sentences(text)
+	if len(sentences) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := s.embed(ctx, sentences)
+	if err != nil {
+		return nil, err
+	}
+	similarities := make([]float64, len(sentences)-1)
+	for i := range similarities {
+		similarities[i] = cosineSimilarity(vectors[i], vectors[i+1])
+	}
+	threshold := percentile(similarities, s.cfg.Percentile)
+
+	var chunks []Chunk
+	var current []string
+	length := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, Chunk{Text: strings.Join(current, " ")})
+			current, length = nil, 0
+		}
+	}
+
+	for i, sentence := range sentences {
+		n := utf8.RuneCountInString(sentence)
+		if n > s.cfg.MaxSize {
+			flush()
+			parts, err := s.fallback.SplitText(sentence)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range parts {
+				chunks = append(chunks, Chunk{Text: p})
+			}
+			continue
+		}
+
+		if len(current) > 0 {
+			boundary := similarities[i-1] < threshold && length >= s.cfg.MinSize
+			if boundary || length+1+n > s.cfg.MaxSize {
+				flush()
+			}
+		}
+		if len(current) > 0 {
+			length++
+		}
+		current = append(current, sentence)
+		length += n
+	}
+	flush()
+
+	return chunks, nil
+}
+
+// embed embeds sentences in batches.
+func (s *Semantic) embed(ctx context.Context, sentences []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(sentences))
+	for start := 0; start < len(sentences); start += embedBatchSize {
+		batch := sentences[start:min(start+embedBatchSize, len(sentences))]
+		embedded, err := s.embedder.EmbedDocuments(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("embed sentences: %w", err)
+		}
+		if len(embedded) != len(batch) {
+			return nil, fmt.Errorf("embed sentences: got %d embeddings, want %d", len(embedded), len(batch))
+		}
+		vectors = append(vectors, embedded...)
+	}
+	return vectors, nil
+}
+
+// percentile returns the p-th percentile of values by linear interpolation,
+// or 0 when values is empty.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 when
+// either is empty or their dimensions differ.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
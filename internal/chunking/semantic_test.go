@@ -0,0 +1,106 @@
+package chunking
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// topicEmbedder embeds a sentence by the topic word it mentions.
+type topicEmbedder struct {
+	calls [][]string
+	err   error
+}
+
+func (e *topicEmbedder) EmbedDocuments(ctx context.Context, documents []string) ([][]float32, error) {
+	e.calls = append(e.calls, documents)
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(documents))
+	for i, d := range documents {
+		d = strings.ToLower(d)
+		switch {
+		case strings.Contains(d, "cat"):
+			vectors[i] = []float32{1, 0.1, 0}
+		case strings.Contains(d, "tax"):
+			vectors[i] = []float32{0, 1, 0.1}
+		default:
+			vectors[i] = []float32{0.1, 0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func TestSemantic(t *testing.T) {
+	text := "The cat sleeps. The cat purrs. Tax forms are due. Tax rates rose. Rain is coming. Rain stops soon."
+	embedder := &topicEmbedder{}
+	s, err := NewSemantic(embedder, SemanticConfig{Percentile: 40, MaxSize: 1000})
+	require.NoError(t, err)
+
+	chunks, err := s.Chunk(context.Background(), text)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, c := range chunks {
+		texts = append(texts, c.Text)
+	}
+	assert.Equal(t, []string{
+		"The cat sleeps. The cat purrs.",
+		"Tax forms are due. Tax rates rose.",
+		"Rain is coming. Rain stops soon.",
+	}, texts)
+	require.Len(t, embedder.calls, 1)
+	assert.Len(t, embedder.calls[0], 6)
+}
+
+func TestSemantic_Sizes(t *testing.T) {
+	text := "The cat sleeps. The cat purrs. Tax forms are due. Rain is coming."
+
+	t.Run("min size holds back boundaries", func(t *testing.T) {
+		s, err := NewSemantic(&topicEmbedder{}, SemanticConfig{Percentile: 100, MinSize: 40, MaxSize: 1000})
+		require.NoError(t, err)
+		chunks, err := s.Chunk(context.Background(), text)
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, "The cat sleeps. The cat purrs. Tax forms are due.", chunks[0].Text)
+		assert.Equal(t, "Rain is coming.", chunks[1].Text)
+	})
+
+	t.Run("max size forces cuts", func(t *testing.T) {
+		s, err := NewSemantic(&topicEmbedder{}, SemanticConfig{Percentile: 0, MaxSize: 20})
+		require.NoError(t, err)
+		chunks, err := s.Chunk(context.Background(), text)
+		require.NoError(t, err)
+		assert.Len(t, chunks, 4)
+	})
+}
+
+func TestSemantic_EmbedError(t *testing.T) {
+	s, err := NewSemantic(&topicEmbedder{err: errors.New("quota")}, SemanticConfig{Percentile: 10, MaxSize: 100})
+	require.NoError(t, err)
+
+	_, err = s.Chunk(context.Background(), "One. Two.")
+	assert.ErrorContains(t, err, "quota")
+}
+
+func TestNewSemantic_Invalid(t *testing.T) {
+	_, err := NewSemantic(nil, SemanticConfig{MaxSize: 100})
+	assert.Error(t, err)
+	_, err = NewSemantic(&topicEmbedder{}, SemanticConfig{MinSize: 200, MaxSize: 100})
+	assert.Error(t, err)
+	_, err = NewSemantic(&topicEmbedder{}, SemanticConfig{Percentile: 120, MaxSize: 100})
+	assert.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{0.4, 0.1, 0.3, 0.2}
+	assert.Equal(t, 0.1, percentile(values, 0))
+	assert.Equal(t, 0.4, percentile(values, 100))
+	assert.InDelta(t, 0.25, percentile(values, 50), 1e-9)
+	assert.Zero(t, percentile(nil, 50))
+}
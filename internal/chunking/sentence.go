@@ -0,0 +1,152 @@
+package chunking
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// Sentence packs whole sentences into chunks of up to size characters.
+// Consecutive chunks share trailing sentences of up to overlap characters.
+// Only sentences longer than a chunk are cut.
+type Sentence struct {
+	size     int
+	overlap  int
+	fallback textsplitter.TextSplitter
+}
+
+// NewSentence creates a sentence chunker. Sizes are in characters.
+func NewSentence(size, overlap int) *Sentence {
+	return &Sentence{
+		size:    size,
+		overlap: overlap,
+		fallback: textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(size),
+			textsplitter.WithChunkOverlap(overlap),
+		),
+	}
+}
+
+// Chunk implements Chunker.
+func (s *Sentence) Chunk(ctx context.Context, text string) ([]Chunk, error) {
+	var chunks []Chunk
+	var current []string
+	length := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, Chunk{Text: strings.Join(current, " ")})
+		}
+	}
+
+	for _, sentence := range Sentences(text) {
+		n := utf8.RuneCountInString(sentence)
+		if n > s.size {
+			flush()
+			current, length = nil, 0
+			parts, err := s.fallback.SplitText(sentence)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range parts {
+				chunks = append(chunks, Chunk{Text: p})
+			}
+			continue
+		}
+
+		if len(current) > 0 && length+1+n > s.size {
+			flush()
+			current, length = s.carry(current)
+			if len(current) > 0 && length+1+n > s.size {
+				current, length = nil, 0
+			}
+		}
+		if len(current) > 0 {
+			length++
+		}
+		current = append(current, sentence)
+		length += n
+	}
+	flush()
+
+	return chunks, nil
+}
+
+// carry returns the trailing sentences of a finished chunk that fit into
+// the overlap, and their joined length.
+func (s *Sentence) carry(sentences []string) ([]string, int) {
+	length := 0
+	start := len(sentences)
+	for start > 0 {
+		n := utf8.RuneCountInString(sentences[start-1])
+		if length > 0 {
+			n++
+		}
+		if length+n > s.overlap {
+			break
+		}
+		length += n
+		start--
+	}
+	return append([]string(nil), sentences[start:]...), length
+}
+
+// Sentences splits text into sentences. A sentence ends at '.', '!' or '?'
+// (with any closing quotes or brackets) followed by whitespace and an
+// upper case letter, digit or opening quote, or at a blank line. Lower case
+// continuations keep abbreviations such as "e.g." intact.
+func Sentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+
+	emit := func(end int) {
+		if s := strings.Join(strings.Fields(string(runes[start:end])), " "); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+	}
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\n' && i+1 < len(runes) && isBlankLineAhead(runes[i+1:]):
+			emit(i)
+		case r == '.' || r == '!' || r == '?':
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune(`"')]»”’`, runes[end]) {
+				end++
+			}
+			next := end
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			if next == end || next == len(runes) {
+				continue
+			}
+			if c := runes[next]; unicode.IsUpper(c) || unicode.IsDigit(c) || strings.ContainsRune(`"'(«“‘`, c) {
+				emit(end)
+				i = end - 1
+			}
+		}
+	}
+	emit(len(runes))
+
+	return sentences
+}
+
+// isBlankLineAhead reports whether runes start with a line holding only
+// whitespace, i.e. the preceding newline ends a paragraph.
+func isBlankLineAhead(runes []rune) bool {
+	for _, r := range runes {
+		if r == '\n' {
+			return true
+		}
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package chunking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "punctuation",
+			text: "First one. Second one! Third one? Fourth",
+			want: []string{"First one.", "Second one!", "Third one?", "Fourth"},
+		},
+		{
+			name: "abbreviations and decimals",
+			text: "Use tools, e.g. a hammer. Version 1.5 is out.",
+			want: []string{"Use tools, e.g. a hammer.", "Version 1.5 is out."},
+		},
+		{
+			name: "quotes",
+			text: `He said "stop." Then he left.`,
+			want: []string{`He said "stop."`, "Then he left."},
+		},
+		{
+			name: "paragraphs",
+			text: "Heading without period\n\nNext paragraph\nwraps lines.",
+			want: []string{"Heading without period", "Next paragraph wraps lines."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sentences(tt.text))
+		})
+	}
+}
+
+func TestSentence(t *testing.T) {
+	text := "Alpha is first. Beta is second. Gamma is third. Delta is fourth."
+
+	chunks, err := NewSentence(35, 0).Chunk(context.Background(), text)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, c := range chunks {
+		texts = append(texts, c.Text)
+	}
+	assert.Equal(t, []string{"Alpha is first. Beta is second.", "Gamma is third. Delta is fourth."}, texts)
+}
+
+func TestSentence_Overlap(t *testing.T) {
+	text := "Alpha is first. Beta is second. Gamma is third."
+
+	chunks, err := NewSentence(35, 16).Chunk(context.Background(), text)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, c := range chunks {
+		texts = append(texts, c.Text)
+	}
+	assert.Equal(t, []string{"Alpha is first. Beta is second.", "Beta is second. Gamma is third."}, texts)
+}
+
+func TestSentence_LongSentence(t *testing.T) {
+	chunks, err := NewSentence(10, 0).Chunk(context.Background(), "one two three four five six")
+	require.NoError(t, err)
+
+	require.Greater(t, len(chunks), 1)
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c.Text), 10)
+	}
+}
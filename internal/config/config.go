@@ -18,7 +18,13 @@ type Config struct {
 	Agent       AgentConfig       `koanf:"agent"`
 	VectorStore VectorStoreConfig `koanf:"vectorstore"`
 	Retriever   RetrieverConfig   `koanf:"retriever"`
+	WebSearch   WebSearchConfig   `koanf:"web_search"`
 	Server      ServerConfig      `koanf:"server"`
+	Usage       UsageConfig       `koanf:"usage"`
+	Feedback    FeedbackConfig    `koanf:"feedback"`
+	AnswerCache AnswerCacheConfig `koanf:"answer_cache"`
+	Eval        EvalConfig        `koanf:"eval"`
+	Logging     LoggingConfig     `koanf:"logging"`
 	Tracing     TracingConfig     `koanf:"tracing"`
 }
 
@@ -36,6 +42,19 @@ type AgentConfig struct {
 	Name        string `koanf:"name"`
 	Description string `koanf:"description"`
 	Instruction string `koanf:"instruction"`
+	// Tools configures the sub-agents the RAG agent can call during a run.
+	Tools AgentToolsConfig `koanf:"tools"`
+}
+
+// AgentToolsConfig holds settings for agent-driven retrieval.
+type AgentToolsConfig struct {
+	// KnowledgeBase lets the agent search the knowledge base itself, in
+	// addition to the context retrieved before the run.
+	KnowledgeBase bool `koanf:"knowledge_base"`
+	// MaxSearches caps knowledge base searches per chat request (0 = unlimited).
+	MaxSearches int `koanf:"max_searches"`
+	// TopK is the maximum number of documents per tool search.
+	TopK int `koanf:"top_k"`
 }
 
 // VectorStoreConfig holds vector database settings.
@@ -50,10 +69,186 @@ type VectorStoreConfig struct {
 
 // RetrieverConfig holds retrieval settings.
 type RetrieverConfig struct {
-	TopK         int     `koanf:"top_k"`
-	MinScore     float64 `koanf:"min_score"`
-	ChunkSize    int     `koanf:"chunk_size"`
-	ChunkOverlap int     `koanf:"chunk_overlap"`
+	TopK           int                  `koanf:"top_k"`
+	MinScore       float64              `koanf:"min_score"`
+	ChunkSize      int                  `koanf:"chunk_size"`
+	ChunkOverlap   int                  `koanf:"chunk_overlap"`
+	Chunking       ChunkingConfig       `koanf:"chunking"`
+	Rerank         RerankConfig         `koanf:"rerank"`
+	Expansion      ExpansionConfig      `koanf:"expansion"`
+	HyDE           HyDEConfig           `koanf:"hyde"`
+	Condense       CondenseConfig       `koanf:"condense"`
+	MMR            MMRConfig            `koanf:"mmr"`
+	ContextWindow  ContextWindowConfig  `koanf:"context_window"`
+	ParentDocument ParentDocumentConfig `koanf:"parent_document"`
+	Enrichment     EnrichmentConfig     `koanf:"enrichment"`
+}
+
+// EnrichmentConfig holds settings for contextual chunk enrichment: a
+// header naming the document, section and document summary is prepended
+// to each chunk before embedding, while the original text is stored for
+// display.
+type EnrichmentConfig struct {
+	// Mode is "none", "template" (header from the upload's title and the
+	// chunk's section) or "llm" (the model also writes a missing title and
+	// a one-line summary). Uploads can override it.
+	Mode string `koanf:"mode"`
+	// Template is a Go text/template over .Title, .Section and .Summary;
+	// empty uses the built-in header.
+	Template string `koanf:"template"`
+	// MaxChars caps how much of a document the model reads in "llm" mode.
+	MaxChars int `koanf:"max_chars"`
+}
+
+// ChunkingConfig selects how uploads are split into chunks. Chunks are
+// sized by ChunkSize and ChunkOverlap, in estimated tokens for the
+// "token_estimate" strategy and in characters otherwise.
+type ChunkingConfig struct {
+	// Strategy is one of "recursive", "markdown", "token_estimate",
+	// "sentence", "code" or "semantic". Uploads can override it.
+	Strategy string `koanf:"strategy"`
+	// Language is the default programming language for "code".
+	Language string                 `koanf:"language"`
+	Semantic SemanticChunkingConfig `koanf:"semantic"`
+}
+
+// SemanticChunkingConfig holds settings for the "semantic" strategy, which
+// embeds sentences and cuts where adjacent sentences stop being similar.
+type SemanticChunkingConfig struct {
+	// Percentile of adjacent-sentence similarities in a document below
+	// which a chunk is cut.
+	Percentile float64 `koanf:"percentile"`
+	// MinSize and MaxSize bound chunk sizes in characters.
+	MinSize int `koanf:"min_size"`
+	MaxSize int `koanf:"max_size"`
+}
+
+// ParentDocumentConfig holds settings for small-to-big indexing: small
+// child chunks are embedded for search, and retrieval returns the larger
+// parent section they belong to. Each section is stored once, with its
+// first child.
+type ParentDocumentConfig struct {
+	// Enabled makes uploads index parent sections by default; uploads can
+	// override it. Retrieval always returns parents for such chunks.
+	Enabled       bool `koanf:"enabled"`
+	ParentSize    int  `koanf:"parent_size"`
+	ParentOverlap int  `koanf:"parent_overlap"`
+	ChildSize     int  `koanf:"child_size"`
+	ChildOverlap  int  `koanf:"child_overlap"`
+	// Candidates is the number of child hits fetched, so enough distinct
+	// parents remain after deduplication.
+	Candidates int `koanf:"candidates"`
+}
+
+// ContextWindowConfig holds settings for expanding each hit with its
+// neighbouring chunks from the same document.
+type ContextWindowConfig struct {
+	// Enabled makes expansion the default; requests can override it.
+	Enabled bool `koanf:"enabled"`
+	// Chunks is the number of chunks added on each side of a hit.
+	Chunks int `koanf:"chunks"`
+}
+
+// MMRConfig holds settings for maximal marginal relevance re-selection,
+// which trades relevance for diversity among the returned documents.
+type MMRConfig struct {
+	// Enabled makes MMR the default; requests can override it.
+	Enabled bool `koanf:"enabled"`
+	// Lambda weighs relevance against diversity: 1 is pure relevance,
+	// 0 pure diversity.
+	Lambda float64 `koanf:"lambda"`
+	// Candidates is the number of hits fetched to select top_k from.
+	Candidates int `koanf:"candidates"`
+}
+
+// CondenseConfig holds settings for rewriting follow-up chat messages
+// into standalone retrieval queries using the session history.
+type CondenseConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// MaxTurns is the number of most recent turns given to the model.
+	MaxTurns int `koanf:"max_turns"`
+}
+
+// HyDEConfig holds settings for hypothetical document embeddings: the
+// model drafts an answer passage and that passage is embedded for search.
+type HyDEConfig struct {
+	// Enabled makes HyDE the default; requests can override it.
+	Enabled bool `koanf:"enabled"`
+	// IncludeQuery averages the passage vector with the raw query vector.
+	IncludeQuery bool `koanf:"include_query"`
+}
+
+// ExpansionConfig holds settings for multi-query expansion (RAG-Fusion).
+type ExpansionConfig struct {
+	// Enabled makes expansion the default; requests can override it.
+	Enabled bool `koanf:"enabled"`
+	// Queries is the number of alternative queries generated.
+	Queries int `koanf:"queries"`
+	// RRFK is the reciprocal rank fusion constant.
+	RRFK int `koanf:"rrf_k"`
+}
+
+// RerankConfig holds settings for the optional rerank stage between
+// retrieval and prompt building.
+type RerankConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Provider is lexical (local BM25), http (rerank service) or llm.
+	Provider string `koanf:"provider"`
+	// Candidates is the number of hits fetched before reranking to top_k.
+	Candidates  int    `koanf:"candidates"`
+	URL         string `koanf:"url"`         // http: rerank endpoint
+	APIKey      string `koanf:"api_key"`     // http: bearer token
+	Model       string `koanf:"model"`       // http: model name passed to the service
+	Timeout     int    `koanf:"timeout"`     // http: request timeout in seconds
+	Concurrency int    `koanf:"concurrency"` // llm: parallel scoring calls
+}
+
+// WebSearchConfig holds the policy for the chat agent's web search.
+type WebSearchConfig struct {
+	// Mode is disabled, fallback (only when retrieval is below min_score)
+	// or always.
+	Mode string `koanf:"mode"`
+	// MinScore is compared against the highest cosine similarity between
+	// the query and a retrieved chunk, whatever fusion or reranking ran.
+	MinScore float64 `koanf:"min_score"`
+	// AllowedDomains limits web sources to these domains and subdomains.
+	AllowedDomains []string `koanf:"allowed_domains"`
+	BlockedDomains []string `koanf:"blocked_domains"`
+	// TenantModes overrides mode per tenant, e.g. disabled for tenants
+	// holding confidential collections.
+	TenantModes map[string]string `koanf:"tenant_modes"`
+	// OverrideRoles may relax the mode per request; admins always can and
+	// anyone may tighten it.
+	OverrideRoles []string `koanf:"override_roles"`
+	// Provider is gemini (the model's native google_search), searxng,
+	// http (a generic JSON search endpoint) or stub (offline, no results).
+	Provider string `koanf:"provider"`
+	// URL is the SearxNG base URL or the http search endpoint.
+	URL    string              `koanf:"url"`
+	APIKey string              `koanf:"api_key"`
+	HTTP   WebSearchHTTPConfig `koanf:"http"`
+	// Results is the number of search results considered per query.
+	Results int `koanf:"results"`
+	// FetchPages is the number of top results fetched and chunked.
+	FetchPages int `koanf:"fetch_pages"`
+	// Passages is the number of page chunks handed to the agent.
+	Passages int `koanf:"passages"`
+	Timeout  int `koanf:"timeout"` // seconds, per search and page fetch
+	// InternalNetworks lists CIDR ranges result pages may be fetched
+	// from although they are private, e.g. an intranet indexed by an
+	// internal search backend. Other private addresses are refused.
+	InternalNetworks []string `koanf:"internal_networks"`
+}
+
+// WebSearchHTTPConfig maps a generic JSON search endpoint. Dotted field
+// paths reach into nested objects; unset fields use SearxNG's layout.
+type WebSearchHTTPConfig struct {
+	QueryParam   string            `koanf:"query_param"`
+	Params       map[string]string `koanf:"params"`
+	ResultsField string            `koanf:"results_field"`
+	TitleField   string            `koanf:"title_field"`
+	URLField     string            `koanf:"url_field"`
+	SnippetField string            `koanf:"snippet_field"`
 }
 
 // ServerConfig holds server settings.
@@ -63,6 +258,116 @@ type ServerConfig struct {
 	APIKey     string `koanf:"api_key"`
 	RateLimit  int    `koanf:"rate_limit"`
 	RateWindow int    `koanf:"rate_window"`
+	// RateLimits overrides rate_limit per route class (search, ingest,
+	// chat, admin, feedback, usage). The auth class limits failed authentications per
+	// client address.
+	RateLimits map[string]RateLimitConfig `koanf:"rate_limits"`
+	// TrustedProxies lists addresses or CIDRs allowed to set
+	// X-Forwarded-For for the client address. Set it behind a reverse
+	// proxy or load balancer, otherwise all clients share the proxy's
+	// address for rate limiting.
+	TrustedProxies []string `koanf:"trusted_proxies"`
+	// DefaultTenant is assigned to callers authenticated with api_key
+	// (or to everyone when authentication is disabled).
+	DefaultTenant string `koanf:"default_tenant"`
+	// TenantKeys maps additional API keys to the tenant they act for.
+	TenantKeys map[string]string `koanf:"tenant_keys"`
+	// KeysFile persists API keys minted through the admin endpoints.
+	// Setting it enables authentication even when no static key is set.
+	KeysFile string     `koanf:"keys_file"`
+	JWT      JWTConfig  `koanf:"jwt"`
+	CORS     CORSConfig `koanf:"cors"`
+}
+
+// CORSConfig holds the cross-origin resource sharing policy.
+type CORSConfig struct {
+	// AllowedOrigins are exact origins, "*" for any, or patterns with one
+	// wildcard such as "https://*.example.com".
+	AllowedOrigins   []string `koanf:"allowed_origins"`
+	AllowedMethods   []string `koanf:"allowed_methods"`
+	AllowedHeaders   []string `koanf:"allowed_headers"` // "*" echoes the requested headers
+	ExposedHeaders   []string `koanf:"exposed_headers"`
+	AllowCredentials bool     `koanf:"allow_credentials"`
+	MaxAge           int      `koanf:"max_age"` // Preflight cache in seconds
+}
+
+// RateLimitConfig holds the rate limit of a route class.
+type RateLimitConfig struct {
+	Limit  int `koanf:"limit"`  // Requests per window (0 = unlimited)
+	Window int `koanf:"window"` // Window in seconds (0 = rate_window)
+}
+
+// JWTConfig holds bearer token (OIDC) authentication settings.
+// Bearer tokens are accepted when jwks_url or jwks_file is set.
+type JWTConfig struct {
+	JWKSURL  string `koanf:"jwks_url"`
+	JWKSFile string `koanf:"jwks_file"`
+	CacheTTL int    `koanf:"cache_ttl"` // Seconds to cache the key set
+	Issuer   string `koanf:"issuer"`
+	Audience string `koanf:"audience"`
+	Leeway   int    `koanf:"leeway"` // Allowed clock skew in seconds
+	// Claims mapped onto the caller; dotted paths reach nested claims
+	UserClaim   string `koanf:"user_claim"`
+	TenantClaim string `koanf:"tenant_claim"`
+	RolesClaim  string `koanf:"roles_claim"`
+	// DefaultScopes are granted to every valid token, RoleScopes per role
+	DefaultScopes []string            `koanf:"default_scopes"`
+	RoleScopes    map[string][]string `koanf:"role_scopes"`
+}
+
+// UsageConfig holds token usage tracking and quota settings.
+// Quotas count LLM and embedding tokens per API key or user.
+type UsageConfig struct {
+	DailyTokens   int64 `koanf:"daily_tokens"`   // 0 = unlimited
+	MonthlyTokens int64 `koanf:"monthly_tokens"` // 0 = unlimited
+	// Tenants overrides the quotas for the callers of a tenant.
+	Tenants map[string]QuotaConfig `koanf:"tenants"`
+	// File persists usage counters so quotas survive restarts.
+	File string `koanf:"file"`
+}
+
+// QuotaConfig holds per-tenant token quotas.
+type QuotaConfig struct {
+	DailyTokens   int64 `koanf:"daily_tokens"`
+	MonthlyTokens int64 `koanf:"monthly_tokens"`
+}
+
+// FeedbackConfig holds settings for user feedback on chat answers.
+type FeedbackConfig struct {
+	// File persists feedback records as JSON; empty keeps them in memory.
+	File string `koanf:"file"`
+	// MaxPending is the number of recent answers kept so feedback can be
+	// given on them.
+	MaxPending int `koanf:"max_pending"`
+}
+
+// AnswerCacheConfig holds settings for the semantic answer cache: chat
+// questions similar enough to one answered before get the earlier answer
+// without retrieval or a model call. Entries are scoped by collection,
+// tenant and retrieval options, and dropped when the tenant uploads.
+type AnswerCacheConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Threshold is the minimum cosine similarity between question
+	// embeddings for a hit.
+	Threshold float64 `koanf:"threshold"`
+	// TTL is how long an answer is reused, in seconds (0 = until
+	// invalidated or evicted).
+	TTL int `koanf:"ttl"`
+	// MaxEntries caps the cached answers; the oldest are dropped first.
+	MaxEntries int `koanf:"max_entries"`
+}
+
+// EvalConfig holds settings for the evaluation command.
+type EvalConfig struct {
+	// JudgeModel grades answers in answer evaluation; empty uses
+	// model.name.
+	JudgeModel string `koanf:"judge_model"`
+}
+
+// LoggingConfig holds structured logging settings.
+type LoggingConfig struct {
+	Level  string `koanf:"level"`  // debug, info, warn, error
+	Format string `koanf:"format"` // json, text
 }
 
 // TracingConfig holds OpenTelemetry tracing settings.
@@ -90,6 +395,11 @@ func Load(configPath string) (*Config, error) {
 			Name:        "rag_agent",
 			Description: "An intelligent RAG agent.",
 			Instruction: "You are a helpful RAG assistant.",
+			Tools: AgentToolsConfig{
+				KnowledgeBase: false,
+				MaxSearches:   5,
+				TopK:          5,
+			},
 		},
 		VectorStore: VectorStoreConfig{
 			Provider:   "qdrant",
@@ -103,6 +413,49 @@ func Load(configPath string) (*Config, error) {
 			MinScore:     0.7,
 			ChunkSize:    512,
 			ChunkOverlap: 50,
+			Chunking: ChunkingConfig{
+				Strategy: "recursive",
+				Semantic: SemanticChunkingConfig{
+					Percentile: 10,
+					MinSize:    100,
+					MaxSize:    1000,
+				},
+			},
+			Rerank: RerankConfig{
+				Provider:    "lexical",
+				Candidates:  30,
+				Timeout:     10,
+				Concurrency: 4,
+			},
+			Expansion: ExpansionConfig{
+				Queries: 3,
+				RRFK:    60,
+			},
+			HyDE: HyDEConfig{
+				IncludeQuery: true,
+			},
+			Condense: CondenseConfig{
+				Enabled:  true,
+				MaxTurns: 6,
+			},
+			MMR: MMRConfig{
+				Lambda:     0.7,
+				Candidates: 20,
+			},
+			ContextWindow: ContextWindowConfig{
+				Chunks: 1,
+			},
+			ParentDocument: ParentDocumentConfig{
+				ParentSize:    2000,
+				ParentOverlap: 200,
+				ChildSize:     400,
+				ChildOverlap:  50,
+				Candidates:    30,
+			},
+			Enrichment: EnrichmentConfig{
+				Mode:     "none",
+				MaxChars: 20000,
+			},
 		},
 		Server: ServerConfig{
 			Host:       "0.0.0.0",
@@ -110,6 +463,51 @@ func Load(configPath string) (*Config, error) {
 			APIKey:     "",
 			RateLimit:  100,
 			RateWindow: 60,
+			RateLimits: map[string]RateLimitConfig{
+				"chat": {Limit: 20, Window: 60},
+				"auth": {Limit: 10, Window: 60},
+			},
+			DefaultTenant: "default",
+			JWT: JWTConfig{
+				CacheTTL:      600,
+				Leeway:        60,
+				UserClaim:     "sub",
+				TenantClaim:   "tenant",
+				RolesClaim:    "roles",
+				DefaultScopes: []string{"search", "chat"},
+			},
+			CORS: CORSConfig{
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{"GET", "POST", "DELETE", "OPTIONS"},
+				AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID"},
+				ExposedHeaders: []string{
+					"X-Request-ID", "Retry-After",
+					"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+				},
+				MaxAge: 600,
+			},
+		},
+		WebSearch: WebSearchConfig{
+			Mode:       "always",
+			MinScore:   0.6,
+			Provider:   "gemini",
+			Results:    5,
+			FetchPages: 3,
+			Passages:   6,
+			Timeout:    10,
+		},
+		Feedback: FeedbackConfig{
+			MaxPending: 10000,
+		},
+		AnswerCache: AnswerCacheConfig{
+			Enabled:    false,
+			Threshold:  0.95,
+			TTL:        3600,
+			MaxEntries: 1000,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
 		},
 		Tracing: TracingConfig{
 			Enabled:     false,
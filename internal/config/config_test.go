@@ -26,6 +26,9 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, "rag_agent", cfg.Agent.Name)
 	assert.Equal(t, "An intelligent RAG agent.", cfg.Agent.Description)
 	assert.Equal(t, "You are a helpful RAG assistant.", cfg.Agent.Instruction)
+	assert.False(t, cfg.Agent.Tools.KnowledgeBase)
+	assert.Equal(t, 5, cfg.Agent.Tools.MaxSearches)
+	assert.Equal(t, 5, cfg.Agent.Tools.TopK)
 
 	assert.Equal(t, "qdrant", cfg.VectorStore.Provider)
 	assert.Equal(t, "localhost", cfg.VectorStore.URL)
@@ -37,9 +40,67 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, 0.7, cfg.Retriever.MinScore)
 	assert.Equal(t, 512, cfg.Retriever.ChunkSize)
 	assert.Equal(t, 50, cfg.Retriever.ChunkOverlap)
+	assert.Equal(t, "recursive", cfg.Retriever.Chunking.Strategy)
+	assert.Empty(t, cfg.Retriever.Chunking.Language)
+	assert.Equal(t, 10.0, cfg.Retriever.Chunking.Semantic.Percentile)
+	assert.Equal(t, 100, cfg.Retriever.Chunking.Semantic.MinSize)
+	assert.Equal(t, 1000, cfg.Retriever.Chunking.Semantic.MaxSize)
 
 	assert.Equal(t, "0.0.0.0", cfg.Server.Host)
 	assert.Equal(t, 8001, cfg.Server.Port)
+	assert.Equal(t, "default", cfg.Server.DefaultTenant)
+	assert.Equal(t, RateLimitConfig{Limit: 20, Window: 60}, cfg.Server.RateLimits["chat"])
+	assert.Equal(t, RateLimitConfig{Limit: 10, Window: 60}, cfg.Server.RateLimits["auth"])
+	assert.Empty(t, cfg.Server.TrustedProxies)
+	assert.Zero(t, cfg.Usage.DailyTokens)
+	assert.Zero(t, cfg.Usage.MonthlyTokens)
+	assert.Equal(t, "info", cfg.Logging.Level)
+	assert.False(t, cfg.Retriever.Rerank.Enabled)
+	assert.Equal(t, "lexical", cfg.Retriever.Rerank.Provider)
+	assert.Equal(t, 30, cfg.Retriever.Rerank.Candidates)
+	assert.False(t, cfg.Retriever.Expansion.Enabled)
+	assert.Equal(t, 3, cfg.Retriever.Expansion.Queries)
+	assert.Equal(t, 60, cfg.Retriever.Expansion.RRFK)
+	assert.False(t, cfg.Retriever.HyDE.Enabled)
+	assert.True(t, cfg.Retriever.HyDE.IncludeQuery)
+	assert.True(t, cfg.Retriever.Condense.Enabled)
+	assert.Equal(t, 6, cfg.Retriever.Condense.MaxTurns)
+	assert.False(t, cfg.Retriever.MMR.Enabled)
+	assert.Equal(t, 0.7, cfg.Retriever.MMR.Lambda)
+	assert.Equal(t, 20, cfg.Retriever.MMR.Candidates)
+	assert.False(t, cfg.Retriever.ContextWindow.Enabled)
+	assert.Equal(t, 1, cfg.Retriever.ContextWindow.Chunks)
+	assert.False(t, cfg.Retriever.ParentDocument.Enabled)
+	assert.Equal(t, 2000, cfg.Retriever.ParentDocument.ParentSize)
+	assert.Equal(t, 200, cfg.Retriever.ParentDocument.ParentOverlap)
+	assert.Equal(t, 400, cfg.Retriever.ParentDocument.ChildSize)
+	assert.Equal(t, 50, cfg.Retriever.ParentDocument.ChildOverlap)
+	assert.Equal(t, 30, cfg.Retriever.ParentDocument.Candidates)
+	assert.Equal(t, "none", cfg.Retriever.Enrichment.Mode)
+	assert.Empty(t, cfg.Retriever.Enrichment.Template)
+	assert.Equal(t, 20000, cfg.Retriever.Enrichment.MaxChars)
+	assert.Empty(t, cfg.Feedback.File)
+	assert.Equal(t, 10000, cfg.Feedback.MaxPending)
+	assert.False(t, cfg.AnswerCache.Enabled)
+	assert.Equal(t, 0.95, cfg.AnswerCache.Threshold)
+	assert.Equal(t, 3600, cfg.AnswerCache.TTL)
+	assert.Equal(t, 1000, cfg.AnswerCache.MaxEntries)
+	assert.Empty(t, cfg.Eval.JudgeModel)
+	assert.Equal(t, "always", cfg.WebSearch.Mode)
+	assert.Equal(t, 0.6, cfg.WebSearch.MinScore)
+	assert.Equal(t, "gemini", cfg.WebSearch.Provider)
+	assert.Equal(t, 5, cfg.WebSearch.Results)
+	assert.Equal(t, 3, cfg.WebSearch.FetchPages)
+	assert.Equal(t, 6, cfg.WebSearch.Passages)
+	assert.Empty(t, cfg.WebSearch.InternalNetworks)
+	assert.Equal(t, []string{"*"}, cfg.Server.CORS.AllowedOrigins)
+	assert.Contains(t, cfg.Server.CORS.AllowedHeaders, "X-API-Key")
+	assert.False(t, cfg.Server.CORS.AllowCredentials)
+	assert.Equal(t, "json", cfg.Logging.Format)
+	assert.Empty(t, cfg.Server.JWT.JWKSURL)
+	assert.Equal(t, 600, cfg.Server.JWT.CacheTTL)
+	assert.Equal(t, "sub", cfg.Server.JWT.UserClaim)
+	assert.Equal(t, []string{"search", "chat"}, cfg.Server.JWT.DefaultScopes)
 
 	assert.False(t, cfg.Tracing.Enabled)
 	assert.Equal(t, "http://localhost:4317", cfg.Tracing.Endpoint)
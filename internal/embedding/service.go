@@ -4,10 +4,24 @@ package embedding
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
 
 	"google.golang.org/genai"
 )
 
+// Embedding task types. Queries and the passages that answer them are
+// embedded differently so they land close to each other.
+const (
+	// TaskDocument embeds text to be retrieved, such as chunks or HyDE
+	// passages.
+	TaskDocument = "RETRIEVAL_DOCUMENT"
+	// TaskQuery embeds search queries.
+	TaskQuery = "RETRIEVAL_QUERY"
+)
+
 // Service handles text embedding operations.
 type Service struct {
 	client    *genai.Client
@@ -40,16 +54,20 @@ func NewService(ctx context.Context, cfg Config) (*Service, error) {
 	}, nil
 }
 
-// EmbedQuery generates an embedding for a query string.
+// EmbedQuery generates an embedding for a query string, with the query
+// task type.
 func (s *Service) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
 	contents := []*genai.Content{
 		genai.NewContentFromText(query, genai.RoleUser),
 	}
 
-	result, err := s.client.Models.EmbedContent(ctx, s.modelName, contents, nil)
+	start := time.Now()
+	result, err := s.client.Models.EmbedContent(ctx, s.modelName, contents, &genai.EmbedContentConfig{TaskType: TaskQuery})
 	if err != nil {
 		return nil, fmt.Errorf("embed content failed: %w", err)
 	}
+	slog.DebugContext(ctx, "Embedded query", "model", s.modelName, "duration", time.Since(start))
+	usage.Charge(ctx, usage.Usage{EmbeddingTokens: usage.EstimateTokens(query)})
 
 	if result.Embeddings == nil || len(result.Embeddings) == 0 {
 		return nil, fmt.Errorf("no embeddings returned")
@@ -58,17 +76,25 @@ func (s *Service) EmbedQuery(ctx context.Context, query string) ([]float32, erro
 	return result.Embeddings[0].Values, nil
 }
 
-// EmbedDocuments generates embeddings for multiple documents.
+// EmbedDocuments generates embeddings for multiple documents, with the
+// document task type.
 func (s *Service) EmbedDocuments(ctx context.Context, documents []string) ([][]float32, error) {
 	contents := make([]*genai.Content, len(documents))
 	for i, doc := range documents {
 		contents[i] = genai.NewContentFromText(doc, genai.RoleUser)
 	}
 
-	result, err := s.client.Models.EmbedContent(ctx, s.modelName, contents, nil)
+	start := time.Now()
+	result, err := s.client.Models.EmbedContent(ctx, s.modelName, contents, &genai.EmbedContentConfig{TaskType: TaskDocument})
 	if err != nil {
 		return nil, fmt.Errorf("embed content failed: %w", err)
 	}
+	slog.DebugContext(ctx, "Embedded documents",
+		"model", s.modelName,
+		"documents", len(documents),
+		"duration", time.Since(start),
+	)
+	usage.Charge(ctx, usage.Usage{EmbeddingTokens: usage.EstimateTokens(documents...)})
 
 	if result.Embeddings == nil || len(result.Embeddings) != len(documents) {
 		return nil, fmt.Errorf("unexpected number of embeddings: got %d, expected %d",
@@ -2,10 +2,15 @@ package embedding
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"google.golang.org/genai"
 )
 
 func TestNewService_Success(t *testing.T) {
@@ -381,3 +386,47 @@ func TestService_CloseOnEmptyService(t *testing.T) {
 	err := service.Close()
 	assert.NoError(t, err)
 }
+
+// newTaskTypeTestService returns a service backed by a fake Gemini API
+// that records the task type of each embedded text.
+func newTaskTypeTestService(t *testing.T) (*Service, *[]string) {
+	t.Helper()
+	var taskTypes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Requests []struct {
+				TaskType string `json:"taskType"`
+			} `json:"requests"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		embeddings := make([]map[string]any, len(body.Requests))
+		for i, req := range body.Requests {
+			taskTypes = append(taskTypes, req.TaskType)
+			embeddings[i] = map[string]any{"values": []float32{1, 0}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-api-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	require.NoError(t, err)
+	return &Service{client: client, modelName: "gemini-embedding-001"}, &taskTypes
+}
+
+func TestService_TaskTypes(t *testing.T) {
+	ctx := context.Background()
+	service, taskTypes := newTaskTypeTestService(t)
+
+	_, err := service.EmbedQuery(ctx, "How do I restart?")
+	require.NoError(t, err)
+	assert.Equal(t, []string{TaskQuery}, *taskTypes)
+
+	*taskTypes = nil
+	_, err = service.EmbedDocuments(ctx, []string{"Run make restart.", "Upgrades need no restart."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{TaskDocument, TaskDocument}, *taskTypes)
+}
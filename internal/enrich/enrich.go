@@ -0,0 +1,174 @@
+// Package enrich builds context headers that are prepended to chunks
+// before embedding, so a chunk read in isolation still says which document
+// and section it came from.
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/mfmezger/agentic_rag_go/internal/llm"
+
+	"google.golang.org/adk/model"
+)
+
+// Mode selects how chunk headers are produced.
+type Mode string
+
+// Enrichment modes.
+const (
+	// ModeNone embeds chunks as they are.
+	ModeNone Mode = "none"
+	// ModeTemplate renders the header from the document title, section
+	// path and summary supplied with the upload.
+	ModeTemplate Mode = "template"
+	// ModeLLM has the model fill in a missing title and a one-line
+	// summary of the document, then renders the template.
+	ModeLLM Mode = "llm"
+)
+
+// ContextField is the payload key holding the header a chunk was embedded
+// with.
+const ContextField = "chunk_context"
+
+// ErrUnknownMode is returned for an unsupported mode name.
+var ErrUnknownMode = errors.New("unknown enrichment mode")
+
+// DefaultTemplate is the header template used when none is configured.
+// Lines for empty fields are left out.
+const DefaultTemplate = `{{if .Title}}Document: {{.Title}}
+{{end}}{{if .Section}}Section: {{.Section}}
+{{end}}{{if .Summary}}Summary: {{.Summary}}
+{{end}}`
+
+// describeInstruction asks for a document title and summary as JSON.
+const describeInstruction = `You describe documents for a search index. Given a document, write its title and a one-sentence summary of what it covers. Keep the summary under 30 words. Respond with JSON: {"title": "...", "summary": "..."}.`
+
+// ParseMode parses a mode name. An empty name means ModeNone.
+func ParseMode(name string) (Mode, error) {
+	switch m := Mode(name); m {
+	case "":
+		return ModeNone, nil
+	case ModeNone, ModeTemplate, ModeLLM:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownMode, name)
+	}
+}
+
+// Document describes the document chunks are taken from.
+type Document struct {
+	Title   string
+	Summary string
+	// Text is the full document, read in ModeLLM.
+	Text string
+}
+
+// Config holds enricher settings.
+type Config struct {
+	// Template is a text/template over Title, Section and Summary;
+	// DefaultTemplate when empty.
+	Template string
+	// MaxChars caps how much of the document the model reads in ModeLLM.
+	MaxChars int
+}
+
+// Enricher renders chunk headers.
+type Enricher struct {
+	model    model.LLM
+	tmpl     *template.Template
+	maxChars int
+}
+
+// New creates an enricher. The model is only used in ModeLLM and may be
+// nil otherwise.
+func New(cfg Config, m model.LLM) (*Enricher, error) {
+	text := cfg.Template
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("header").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse enrichment template: %w", err)
+	}
+
+	maxChars := cfg.MaxChars
+	if maxChars <= 0 {
+		maxChars = 20000
+	}
+	return &Enricher{model: m, tmpl: tmpl, maxChars: maxChars}, nil
+}
+
+// Describe fills in the title and summary of doc from the model in
+// ModeLLM. Values already set are kept; other modes leave doc unchanged.
+func (e *Enricher) Describe(ctx context.Context, mode Mode, doc *Document) error {
+	if mode != ModeLLM || (doc.Title != "" && doc.Summary != "") {
+		return nil
+	}
+	if e.model == nil {
+		return errors.New("llm enrichment needs a model")
+	}
+
+	text, err := llm.Generate(ctx, e.model, llm.Request{
+		Instruction: describeInstruction,
+		Prompt:      "Document:\n" + llm.Truncate(doc.Text, e.maxChars),
+		Temperature: llm.Float32(0),
+		JSON:        true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var out struct {
+		Title   string `json:"title"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		return fmt.Errorf("failed to parse document description: %w", err)
+	}
+	if doc.Title == "" {
+		doc.Title = oneLine(out.Title)
+	}
+	if doc.Summary == "" {
+		doc.Summary = oneLine(out.Summary)
+	}
+	return nil
+}
+
+// Header renders the context header of a chunk in section of doc, or ""
+// in ModeNone or when there is nothing to say.
+func (e *Enricher) Header(mode Mode, doc Document, section string) (string, error) {
+	if mode == ModeNone {
+		return "", nil
+	}
+
+	var b strings.Builder
+	err := e.tmpl.Execute(&b, struct {
+		Title, Section, Summary string
+	}{
+		Title:   oneLine(doc.Title),
+		Section: oneLine(section),
+		Summary: oneLine(doc.Summary),
+	})
+	if err != nil {
+		return "", fmt.Errorf("render enrichment template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Prepend joins a header and chunk text into the text to embed.
+func Prepend(header, text string) string {
+	if header == "" {
+		return text
+	}
+	return header + "\n\n" + text
+}
+
+// oneLine collapses whitespace so a value cannot break the header layout.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
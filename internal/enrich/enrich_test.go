@@ -0,0 +1,108 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeNone, mode)
+
+	mode, err = ParseMode("llm")
+	require.NoError(t, err)
+	assert.Equal(t, ModeLLM, mode)
+
+	_, err = ParseMode("summary")
+	assert.ErrorIs(t, err, ErrUnknownMode)
+}
+
+func TestHeader(t *testing.T) {
+	e, err := New(Config{}, nil)
+	require.NoError(t, err)
+
+	doc := Document{Title: "Upgrade Guide", Summary: "How to upgrade\nthe server."}
+	header, err := e.Header(ModeTemplate, doc, "Install > Restart")
+	require.NoError(t, err)
+	assert.Equal(t, "Document: Upgrade Guide\nSection: Install > Restart\nSummary: How to upgrade the server.", header)
+
+	// Empty fields are left out
+	header, err = e.Header(ModeTemplate, Document{Title: "Upgrade Guide"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Document: Upgrade Guide", header)
+
+	header, err = e.Header(ModeNone, doc, "Install")
+	require.NoError(t, err)
+	assert.Empty(t, header)
+}
+
+func TestHeader_CustomTemplate(t *testing.T) {
+	e, err := New(Config{Template: "[{{.Title}} / {{.Section}}]"}, nil)
+	require.NoError(t, err)
+
+	header, err := e.Header(ModeTemplate, Document{Title: "Guide"}, "Setup")
+	require.NoError(t, err)
+	assert.Equal(t, "[Guide / Setup]", header)
+
+	_, err = New(Config{Template: "{{.Title"}, nil)
+	assert.Error(t, err)
+
+	e, err = New(Config{Template: "{{.Author}}"}, nil)
+	require.NoError(t, err)
+	_, err = e.Header(ModeTemplate, Document{}, "")
+	assert.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	var cfg *model.LLMRequest
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		cfg = req
+		return `{"title": "Upgrade Guide", "summary": " Steps to upgrade\n the server. "}`, nil
+	})
+	e, err := New(Config{MaxChars: 10}, m)
+	require.NoError(t, err)
+
+	doc := Document{Text: "It must be restarted after the upgrade."}
+	require.NoError(t, e.Describe(context.Background(), ModeLLM, &doc))
+	assert.Equal(t, "Upgrade Guide", doc.Title)
+	assert.Equal(t, "Steps to upgrade the server.", doc.Summary)
+	assert.NotContains(t, mocks.PromptText(cfg), "after the upgrade")
+	assert.Equal(t, "application/json", cfg.Config.ResponseMIMEType)
+
+	// A supplied title wins
+	doc = Document{Title: "Release Notes", Text: "text"}
+	require.NoError(t, e.Describe(context.Background(), ModeLLM, &doc))
+	assert.Equal(t, "Release Notes", doc.Title)
+	assert.Equal(t, "Steps to upgrade the server.", doc.Summary)
+}
+
+func TestDescribe_Skipped(t *testing.T) {
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		return "", errors.New("unexpected call")
+	})
+	e, err := New(Config{}, m)
+	require.NoError(t, err)
+
+	doc := Document{Text: "text"}
+	require.NoError(t, e.Describe(context.Background(), ModeTemplate, &doc))
+	assert.Empty(t, doc.Title)
+
+	doc = Document{Title: "Guide", Summary: "Known.", Text: "text"}
+	require.NoError(t, e.Describe(context.Background(), ModeLLM, &doc))
+
+	doc = Document{Text: "text"}
+	assert.Error(t, e.Describe(context.Background(), ModeLLM, &doc))
+}
+
+func TestPrepend(t *testing.T) {
+	assert.Equal(t, "chunk", Prepend("", "chunk"))
+	assert.Equal(t, "Document: Guide\n\nchunk", Prepend("Document: Guide", "chunk"))
+}
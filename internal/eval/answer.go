@@ -0,0 +1,196 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	ragagent "github.com/mfmezger/agentic_rag_go/internal/agent"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/mfmezger/agentic_rag_go/internal/websearch"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// evalAppName scopes the sessions created while replaying questions.
+const evalAppName = "agentic_rag_eval"
+
+// Answer is a chat answer with the documents retrieved for it.
+type Answer struct {
+	Text      string
+	Documents []qdrant.SearchResult
+}
+
+// Answerer answers a question.
+type Answerer interface {
+	Answer(ctx context.Context, c Case) (*Answer, error)
+}
+
+// Chat replays questions through retrieval and an agent run with the
+// retrieved context in a fresh session. The judge only sees the retrieved
+// documents, so the agent runs without its knowledge base and web search
+// tools: any context they fetched would make grounded answers look
+// unfaithful.
+type Chat struct {
+	Factory *ragagent.Factory
+	Tenant  string
+	// Retrieve is used for every question; a case's filter is merged into
+	// Filter.
+	Retrieve ragagent.RetrieveOptions
+}
+
+// Answer implements Answerer.
+func (c *Chat) Answer(ctx context.Context, cs Case) (*Answer, error) {
+	opts := c.Retrieve
+	opts.Filter = mergeFilters(opts.Filter, cs.Filter)
+	retrieved, err := c.Factory.Retrieve(ctx, c.Tenant, cs.Query, opts)
+	if err != nil {
+		// Like chat, answer without context rather than fail
+		slog.WarnContext(ctx, "Retrieval failed, continuing without context", "case", cs.ID, "error", err)
+	}
+
+	sess, err := c.Factory.SessionService().Create(ctx, &session.CreateRequest{
+		AppName: evalAppName,
+		UserID:  c.Tenant,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	noTools := false
+	runner, err := c.Factory.NewRunner(ctx, evalAppName, retrieved, ragagent.RunnerOptions{
+		Tenant:        c.Tenant,
+		WebSearch:     websearch.ModeDisabled,
+		KnowledgeBase: &noTools,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	msg := genai.NewContentFromText(cs.Query, genai.RoleUser)
+	for event, err := range runner.Run(ctx, c.Tenant, sess.Session.ID(), msg, agent.RunConfig{}) {
+		if err != nil {
+			return nil, fmt.Errorf("agent error: %w", err)
+		}
+		if event.LLMResponse.Content == nil {
+			continue
+		}
+		for _, p := range event.LLMResponse.Content.Parts {
+			if p.Text != "" {
+				text.WriteString(p.Text)
+			}
+		}
+	}
+
+	answer := &Answer{Text: text.String()}
+	if retrieved != nil {
+		answer.Documents = retrieved.Documents
+	}
+	return answer, nil
+}
+
+// Grades are answer quality scores, averaged over questions in reports.
+type Grades struct {
+	Faithfulness     float64 `json:"faithfulness"`
+	AnswerRelevance  float64 `json:"answer_relevance"`
+	ContextRelevance float64 `json:"context_relevance"`
+}
+
+// AnswerResult holds the evaluation of one question.
+type AnswerResult struct {
+	ID        string           `json:"id"`
+	Query     string           `json:"query"`
+	Answer    string           `json:"answer,omitempty"`
+	Retrieved []RetrievedChunk `json:"retrieved,omitempty"`
+	Scores    *Scores          `json:"scores,omitempty"`
+	// Error is set when the question could not be answered or judged; the
+	// question is then left out of the aggregates.
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// AnswerReport holds grades averaged over the judged questions and the
+// per-question results.
+type AnswerReport struct {
+	Questions int `json:"questions"`
+	// Judged counts the questions included in Grades.
+	Judged        int            `json:"judged"`
+	Grades        Grades         `json:"grades"`
+	MeanLatencyMS float64        `json:"mean_latency_ms"`
+	Results       []AnswerResult `json:"results"`
+}
+
+// EvaluateAnswers answers every case with a and grades the answers with j.
+// A failing question is reported and skipped rather than ending the run.
+// Latency covers answering only, not judging.
+func EvaluateAnswers(ctx context.Context, a Answerer, j *Judge, cases []Case) (*AnswerReport, error) {
+	report := &AnswerReport{Questions: len(cases)}
+	var latency time.Duration
+	var answered int
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := AnswerResult{ID: c.ID, Query: c.Query}
+
+		start := time.Now()
+		answer, err := a.Answer(ctx, c)
+		elapsed := time.Since(start)
+		result.LatencyMS = elapsed.Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+			report.Results = append(report.Results, result)
+			slog.WarnContext(ctx, "Answering failed", "case", c.ID, "error", err)
+			continue
+		}
+		latency += elapsed
+		answered++
+
+		result.Answer = answer.Text
+		marks := hits(c, answer.Documents)
+		documents := make([]string, len(answer.Documents))
+		for i, doc := range answer.Documents {
+			result.Retrieved = append(result.Retrieved, RetrievedChunk{
+				ID:         doc.ID,
+				DocumentID: doc.Payload[qdrant.DocumentField],
+				Source:     doc.Payload[qdrant.SourceField],
+				Score:      doc.Score,
+				Relevant:   marks[i],
+			})
+			documents[i] = doc.Content
+			if doc.Passage != "" {
+				documents[i] = doc.Passage
+			}
+		}
+
+		scores, err := j.Score(ctx, c.Query, answer.Text, documents)
+		if err != nil {
+			result.Error = "judge: " + err.Error()
+			report.Results = append(report.Results, result)
+			slog.WarnContext(ctx, "Judging failed", "case", c.ID, "error", err)
+			continue
+		}
+		result.Scores = &scores
+		report.Results = append(report.Results, result)
+
+		report.Judged++
+		report.Grades.Faithfulness += scores.Faithfulness
+		report.Grades.AnswerRelevance += scores.AnswerRelevance
+		report.Grades.ContextRelevance += scores.ContextRelevance
+	}
+
+	if n := float64(report.Judged); n > 0 {
+		report.Grades.Faithfulness /= n
+		report.Grades.AnswerRelevance /= n
+		report.Grades.ContextRelevance /= n
+	}
+	if answered > 0 {
+		report.MeanLatencyMS = float64(latency.Milliseconds()) / float64(answered)
+	}
+	return report, nil
+}
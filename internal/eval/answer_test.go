@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+// answererFunc adapts a function to Answerer.
+type answererFunc func(ctx context.Context, c Case) (*Answer, error)
+
+func (f answererFunc) Answer(ctx context.Context, c Case) (*Answer, error) {
+	return f(ctx, c)
+}
+
+func TestEvaluateAnswers(t *testing.T) {
+	a := answererFunc(func(ctx context.Context, c Case) (*Answer, error) {
+		if c.ID == "broken" {
+			return nil, errors.New("agent error")
+		}
+		return &Answer{
+			Text: "Answer to " + c.Query,
+			Documents: []qdrant.SearchResult{
+				{ID: "c1", Content: "chunk", Passage: "stitched passage", Payload: map[string]string{qdrant.SourceField: "a.md"}},
+			},
+		}, nil
+	})
+	var prompts []string
+	judge := NewJudge(mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		prompt := mocks.PromptText(req)
+		prompts = append(prompts, prompt)
+		if strings.Contains(prompt, "bad judge") {
+			return "not json", nil
+		}
+		if strings.Contains(prompt, "Answer to first") {
+			return `{"faithfulness": 5, "answer_relevance": 5, "context_relevance": 3}`, nil
+		}
+		return `{"faithfulness": 3, "answer_relevance": 1, "context_relevance": 1}`, nil
+	}))
+	cases := []Case{
+		{ID: "first", Query: "first", Sources: []string{"a.md"}},
+		{ID: "second", Query: "second"},
+		{ID: "broken", Query: "third"},
+		{ID: "unjudged", Query: "bad judge"},
+	}
+
+	report, err := EvaluateAnswers(context.Background(), a, judge, cases)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Questions)
+	assert.Equal(t, 2, report.Judged)
+	assert.InDelta(t, 0.75, report.Grades.Faithfulness, 1e-9)
+	assert.InDelta(t, 0.5, report.Grades.AnswerRelevance, 1e-9)
+	assert.InDelta(t, 0.25, report.Grades.ContextRelevance, 1e-9)
+
+	require.Len(t, report.Results, 4)
+	first := report.Results[0]
+	assert.Equal(t, "Answer to first", first.Answer)
+	require.NotNil(t, first.Scores)
+	require.Len(t, first.Retrieved, 1)
+	assert.True(t, first.Retrieved[0].Relevant)
+	assert.False(t, report.Results[1].Retrieved[0].Relevant, "no relevant documents listed")
+	// The judge reads the stitched passage
+	assert.Contains(t, prompts[0], "stitched passage")
+
+	assert.Equal(t, "agent error", report.Results[2].Error)
+	assert.Nil(t, report.Results[2].Scores)
+	assert.Contains(t, report.Results[3].Error, "judge:")
+	assert.Equal(t, "Answer to bad judge", report.Results[3].Answer)
+}
+
+func TestEvaluateAnswers_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a := answererFunc(func(ctx context.Context, c Case) (*Answer, error) {
+		return &Answer{}, nil
+	})
+
+	_, err := EvaluateAnswers(ctx, a, NewJudge(nil), []Case{{ID: "q", Query: "q"}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,72 @@
+// Package eval measures retrieval quality against a golden dataset.
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Case is a golden query with the documents that answer it. A retrieved
+// chunk is relevant when its document ID or its source is listed; list
+// each relevant document once, by either. Answer evaluation only needs
+// the query.
+type Case struct {
+	// ID names the case in reports; it defaults to the line number.
+	ID    string `json:"id,omitempty"`
+	Query string `json:"query"`
+	// Documents lists relevant document IDs, as returned by upload_text.
+	Documents []string `json:"documents,omitempty"`
+	// Sources lists relevant document sources.
+	Sources []string `json:"sources,omitempty"`
+	// Filter restricts retrieval like the search endpoint's filter.
+	Filter map[string]string `json:"filter,omitempty"`
+}
+
+// LoadDataset reads a golden dataset from a JSON Lines file, one Case per
+// line. Blank lines and lines starting with '#' are skipped.
+func LoadDataset(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open dataset: %w", err)
+	}
+	defer f.Close()
+	return ReadDataset(f)
+}
+
+// ReadDataset reads a golden dataset in JSON Lines format.
+func ReadDataset(r io.Reader) ([]Case, error) {
+	var cases []Case
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("dataset line %d: %w", line, err)
+		}
+		if strings.TrimSpace(c.Query) == "" {
+			return nil, fmt.Errorf("dataset line %d: query is required", line)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("dataset is empty")
+	}
+	return cases, nil
+}
@@ -0,0 +1,39 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDataset(t *testing.T) {
+	cases, err := LoadDataset("testdata/golden.jsonl")
+	require.NoError(t, err)
+	require.Len(t, cases, 3)
+
+	assert.Equal(t, "restart", cases[0].ID)
+	assert.Equal(t, []string{"upgrade.md"}, cases[0].Sources)
+	// Unnamed cases are named after their line
+	assert.Equal(t, "line-3", cases[1].ID)
+	assert.Equal(t, map[string]string{"team": "ops"}, cases[1].Filter)
+	assert.Equal(t, []string{"7c9e6679-7425-40de-944b-e07fc1f90ae7"}, cases[2].Documents)
+}
+
+func TestReadDataset_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: "\n# only comments\n"},
+		{name: "malformed", data: `{"query": `},
+		{name: "missing query", data: `{"sources": ["a.md"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadDataset(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
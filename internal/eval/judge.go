@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mfmezger/agentic_rag_go/internal/llm"
+
+	"google.golang.org/adk/model"
+)
+
+// judgeDocumentChars caps each retrieved document shown to the judge.
+const judgeDocumentChars = 4000
+
+// judgeInstruction defines the rubric the judge grades answers by.
+const judgeInstruction = `You grade answers of a retrieval-augmented assistant. Given a question, the retrieved context documents and the answer, rate on an integer scale from 1 (worst) to 5 (best):
+- faithfulness: every claim in the answer is supported by the context. An answer that says the context does not contain the information is faithful.
+- answer_relevance: the answer addresses the question directly and completely, without unrelated content.
+- context_relevance: the retrieved context contains the information needed to answer the question.
+Respond with JSON: {"faithfulness": n, "answer_relevance": n, "context_relevance": n, "explanation": "one or two sentences"}.`
+
+// Scores are the judge's grades of one answer, normalized to 0-1.
+type Scores struct {
+	Faithfulness     float64 `json:"faithfulness"`
+	AnswerRelevance  float64 `json:"answer_relevance"`
+	ContextRelevance float64 `json:"context_relevance"`
+	// Explanation is the judge's reasoning, for reviewing low scores.
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// Judge grades answers with an LLM.
+type Judge struct {
+	model model.LLM
+}
+
+// NewJudge creates a judge backed by m.
+func NewJudge(m model.LLM) *Judge {
+	return &Judge{model: m}
+}
+
+// Score grades answer to question against the retrieved documents.
+func (j *Judge) Score(ctx context.Context, question, answer string, documents []string) (Scores, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question:\n%s\n\nContext:\n", question)
+	if len(documents) == 0 {
+		b.WriteString("(no documents retrieved)\n")
+	}
+	for i, doc := range documents {
+		fmt.Fprintf(&b, "[%d] %s\n\n", i+1, llm.Truncate(doc, judgeDocumentChars))
+	}
+	fmt.Fprintf(&b, "\nAnswer:\n%s", answer)
+
+	text, err := llm.Generate(ctx, j.model, llm.Request{
+		Instruction: judgeInstruction,
+		Prompt:      b.String(),
+		Temperature: llm.Float32(0),
+		JSON:        true,
+	})
+	if err != nil {
+		return Scores{}, err
+	}
+
+	var out struct {
+		Faithfulness     float64 `json:"faithfulness"`
+		AnswerRelevance  float64 `json:"answer_relevance"`
+		ContextRelevance float64 `json:"context_relevance"`
+		Explanation      string  `json:"explanation"`
+	}
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		return Scores{}, fmt.Errorf("failed to parse judge response: %w", err)
+	}
+	for _, g := range []float64{out.Faithfulness, out.AnswerRelevance, out.ContextRelevance} {
+		if g < 1 || g > 5 {
+			return Scores{}, fmt.Errorf("judge grade %v is outside 1-5", g)
+		}
+	}
+
+	return Scores{
+		Faithfulness:     normalizeGrade(out.Faithfulness),
+		AnswerRelevance:  normalizeGrade(out.AnswerRelevance),
+		ContextRelevance: normalizeGrade(out.ContextRelevance),
+		Explanation:      strings.TrimSpace(out.Explanation),
+	}, nil
+}
+
+// normalizeGrade maps a 1-5 grade to 0-1.
+func normalizeGrade(g float64) float64 {
+	return (g - 1) / 4
+}
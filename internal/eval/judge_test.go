@@ -0,0 +1,56 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestJudgeScore(t *testing.T) {
+	var cfg *model.LLMRequest
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		cfg = req
+		return `{"faithfulness": 5, "answer_relevance": 3, "context_relevance": 1, "explanation": " Supported. "}`, nil
+	})
+
+	scores, err := NewJudge(m).Score(context.Background(), "Do I restart?", "Yes, restart it.", []string{"It must be restarted after the upgrade."})
+	require.NoError(t, err)
+	assert.Equal(t, Scores{Faithfulness: 1, AnswerRelevance: 0.5, ContextRelevance: 0, Explanation: "Supported."}, scores)
+
+	prompt := mocks.PromptText(cfg)
+	assert.Contains(t, prompt, "Do I restart?")
+	assert.Contains(t, prompt, "[1] It must be restarted after the upgrade.")
+	assert.Contains(t, prompt, "Yes, restart it.")
+	assert.Equal(t, "application/json", cfg.Config.ResponseMIMEType)
+}
+
+func TestJudgeScore_NoDocuments(t *testing.T) {
+	var prompt string
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		prompt = mocks.PromptText(req)
+		return `{"faithfulness": 4, "answer_relevance": 4, "context_relevance": 1}`, nil
+	})
+
+	_, err := NewJudge(m).Score(context.Background(), "q", "I don't know.", nil)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "(no documents retrieved)")
+}
+
+func TestJudgeScore_Invalid(t *testing.T) {
+	for _, resp := range []string{
+		"looks good",
+		`{"faithfulness": 6, "answer_relevance": 3, "context_relevance": 3}`,
+		`{"faithfulness": 3}`,
+	} {
+		m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+			return resp, nil
+		})
+		_, err := NewJudge(m).Score(context.Background(), "q", "a", nil)
+		assert.Error(t, err, resp)
+	}
+}
@@ -0,0 +1,117 @@
+package eval
+
+import (
+	"math"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+)
+
+// Cutoff holds the metrics of a ranking truncated at K results.
+type Cutoff struct {
+	K         int     `json:"k"`
+	Recall    float64 `json:"recall"`
+	Precision float64 `json:"precision"`
+	NDCG      float64 `json:"ndcg"`
+}
+
+// Metrics holds ranking metrics at several cutoffs.
+type Metrics struct {
+	// MRR is the reciprocal rank of the first relevant result, or its
+	// mean over queries.
+	MRR     float64  `json:"mrr"`
+	Cutoffs []Cutoff `json:"cutoffs"`
+}
+
+// hits marks the results that find a relevant document for the first time.
+// Further chunks of a document already found are not hits, so a document
+// split into many chunks cannot inflate the metrics.
+func hits(c Case, results []qdrant.SearchResult) []bool {
+	documents := make(map[string]bool, len(c.Documents))
+	for _, d := range c.Documents {
+		documents[d] = true
+	}
+	sources := make(map[string]bool, len(c.Sources))
+	for _, s := range c.Sources {
+		sources[s] = true
+	}
+
+	found := make(map[string]bool)
+	marks := make([]bool, len(results))
+	for i, r := range results {
+		var key string
+		if d := r.Payload[qdrant.DocumentField]; documents[d] {
+			key = "document:" + d
+		} else if s := r.Payload[qdrant.SourceField]; sources[s] {
+			key = "source:" + s
+		}
+		if key != "" && !found[key] {
+			found[key] = true
+			marks[i] = true
+		}
+	}
+	return marks
+}
+
+// score computes the metrics of a ranking given its hits and the number of
+// relevant documents.
+func score(marks []bool, relevant int, ks []int) Metrics {
+	var m Metrics
+	for i, hit := range marks {
+		if hit {
+			m.MRR = 1 / float64(i+1)
+			break
+		}
+	}
+
+	for _, k := range ks {
+		var found int
+		var dcg float64
+		for i := 0; i < k && i < len(marks); i++ {
+			if marks[i] {
+				found++
+				dcg += 1 / math.Log2(float64(i+2))
+			}
+		}
+		var idcg float64
+		for i := 0; i < k && i < relevant; i++ {
+			idcg += 1 / math.Log2(float64(i+2))
+		}
+
+		c := Cutoff{K: k, Precision: float64(found) / float64(k)}
+		if relevant > 0 {
+			c.Recall = float64(found) / float64(relevant)
+			c.NDCG = dcg / idcg
+		}
+		m.Cutoffs = append(m.Cutoffs, c)
+	}
+	return m
+}
+
+// mean averages metrics over queries. All entries share the same cutoffs.
+func mean(all []Metrics) Metrics {
+	if len(all) == 0 {
+		return Metrics{}
+	}
+	var m Metrics
+	m.Cutoffs = make([]Cutoff, len(all[0].Cutoffs))
+	for i, c := range all[0].Cutoffs {
+		m.Cutoffs[i].K = c.K
+	}
+	for _, q := range all {
+		m.MRR += q.MRR
+		for i, c := range q.Cutoffs {
+			m.Cutoffs[i].Recall += c.Recall
+			m.Cutoffs[i].Precision += c.Precision
+			m.Cutoffs[i].NDCG += c.NDCG
+		}
+	}
+
+	n := float64(len(all))
+	m.MRR /= n
+	for i := range m.Cutoffs {
+		m.Cutoffs[i].Recall /= n
+		m.Cutoffs[i].Precision /= n
+		m.Cutoffs[i].NDCG /= n
+	}
+	return m
+}
@@ -0,0 +1,78 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func result(document, source string) qdrant.SearchResult {
+	return qdrant.SearchResult{Payload: map[string]string{
+		qdrant.DocumentField: document,
+		qdrant.SourceField:   source,
+	}}
+}
+
+func TestHits(t *testing.T) {
+	c := Case{Documents: []string{"d1"}, Sources: []string{"b.md"}}
+	results := []qdrant.SearchResult{
+		result("d9", "z.md"),
+		result("d1", "a.md"),
+		result("d1", "a.md"), // another chunk of a document already found
+		result("d2", "b.md"),
+	}
+
+	assert.Equal(t, []bool{false, true, false, true}, hits(c, results))
+}
+
+func TestScore(t *testing.T) {
+	marks := []bool{false, true, false, true}
+
+	m := score(marks, 3, []int{1, 2, 4})
+	assert.InDelta(t, 0.5, m.MRR, 1e-9)
+	require.Len(t, m.Cutoffs, 3)
+
+	at1 := m.Cutoffs[0]
+	assert.Equal(t, 1, at1.K)
+	assert.Zero(t, at1.Recall)
+	assert.Zero(t, at1.Precision)
+	assert.Zero(t, at1.NDCG)
+
+	at2 := m.Cutoffs[1]
+	assert.InDelta(t, 1.0/3, at2.Recall, 1e-9)
+	assert.InDelta(t, 0.5, at2.Precision, 1e-9)
+	assert.InDelta(t, (1/math.Log2(3))/(1+1/math.Log2(3)), at2.NDCG, 1e-9)
+
+	at4 := m.Cutoffs[2]
+	assert.InDelta(t, 2.0/3, at4.Recall, 1e-9)
+	assert.InDelta(t, 0.5, at4.Precision, 1e-9)
+	dcg := 1/math.Log2(3) + 1/math.Log2(5)
+	idcg := 1 + 1/math.Log2(3) + 1/math.Log2(4)
+	assert.InDelta(t, dcg/idcg, at4.NDCG, 1e-9)
+}
+
+func TestScore_Perfect(t *testing.T) {
+	m := score([]bool{true, true}, 2, []int{2, 5})
+	assert.Equal(t, 1.0, m.MRR)
+	assert.Equal(t, 1.0, m.Cutoffs[0].Recall)
+	assert.Equal(t, 1.0, m.Cutoffs[0].Precision)
+	assert.InDelta(t, 1.0, m.Cutoffs[0].NDCG, 1e-9)
+	// Fewer results than k count as misses for precision only
+	assert.Equal(t, 1.0, m.Cutoffs[1].Recall)
+	assert.InDelta(t, 0.4, m.Cutoffs[1].Precision, 1e-9)
+	assert.InDelta(t, 1.0, m.Cutoffs[1].NDCG, 1e-9)
+}
+
+func TestMean(t *testing.T) {
+	m := mean([]Metrics{
+		{MRR: 1, Cutoffs: []Cutoff{{K: 1, Recall: 1, Precision: 1, NDCG: 1}}},
+		{MRR: 0, Cutoffs: []Cutoff{{K: 1}}},
+	})
+	assert.Equal(t, 0.5, m.MRR)
+	assert.Equal(t, []Cutoff{{K: 1, Recall: 0.5, Precision: 0.5, NDCG: 0.5}}, m.Cutoffs)
+
+	assert.Equal(t, Metrics{}, mean(nil))
+}
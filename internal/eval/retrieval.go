@@ -0,0 +1,166 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	ragagent "github.com/mfmezger/agentic_rag_go/internal/agent"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+)
+
+// DefaultCutoffs are the ranks metrics are reported at by default.
+var DefaultCutoffs = []int{1, 3, 5, 10}
+
+// Retriever runs a retrieval. *agent.Factory implements it, so the
+// evaluation exercises the same path as the chat and search endpoints.
+type Retriever interface {
+	Retrieve(ctx context.Context, tenant, query string, opts ragagent.RetrieveOptions) (*ragagent.RetrievedContext, error)
+}
+
+// RetrievalOptions configures a retrieval evaluation.
+type RetrievalOptions struct {
+	Tenant string
+	// Cutoffs lists the ranks metrics are reported at; DefaultCutoffs
+	// when empty.
+	Cutoffs []int
+	// Retrieve is used for every query. TopK defaults to the largest
+	// cutoff; a case's filter is merged into Filter.
+	Retrieve ragagent.RetrieveOptions
+}
+
+// RetrievedChunk is a retrieved chunk as listed in a report.
+type RetrievedChunk struct {
+	ID         string  `json:"id"`
+	DocumentID string  `json:"document_id,omitempty"`
+	Source     string  `json:"source,omitempty"`
+	Score      float32 `json:"score"`
+	// Relevant reports whether the chunk is the first hit of a relevant
+	// document.
+	Relevant bool `json:"relevant"`
+}
+
+// QueryResult holds the evaluation of one case.
+type QueryResult struct {
+	ID    string `json:"id"`
+	Query string `json:"query"`
+	// Relevant is the number of relevant documents in the case.
+	Relevant  int              `json:"relevant"`
+	Retrieved []RetrievedChunk `json:"retrieved"`
+	Metrics   Metrics          `json:"metrics"`
+	LatencyMS int64            `json:"latency_ms"`
+}
+
+// RetrievalReport holds the metrics averaged over all cases and the
+// per-case results.
+type RetrievalReport struct {
+	Queries       int           `json:"queries"`
+	Metrics       Metrics       `json:"metrics"`
+	MeanLatencyMS float64       `json:"mean_latency_ms"`
+	Results       []QueryResult `json:"results"`
+}
+
+// EvaluateRetrieval runs every case through r and scores the rankings.
+// Cases run one after another so latencies are comparable.
+func EvaluateRetrieval(ctx context.Context, r Retriever, cases []Case, opts RetrievalOptions) (*RetrievalReport, error) {
+	cutoffs, err := normalizeCutoffs(opts.Cutoffs)
+	if err != nil {
+		return nil, err
+	}
+	base := opts.Retrieve
+	if base.TopK <= 0 {
+		base.TopK = cutoffs[len(cutoffs)-1]
+	}
+
+	report := &RetrievalReport{Queries: len(cases)}
+	metrics := make([]Metrics, 0, len(cases))
+	var latency time.Duration
+	for _, c := range cases {
+		if relevantCount(c) == 0 {
+			return nil, fmt.Errorf("case %s: documents or sources are required", c.ID)
+		}
+		retrieveOpts := base
+		retrieveOpts.Filter = mergeFilters(base.Filter, c.Filter)
+
+		start := time.Now()
+		retrieved, err := r.Retrieve(ctx, opts.Tenant, c.Query, retrieveOpts)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: %w", c.ID, err)
+		}
+		elapsed := time.Since(start)
+		latency += elapsed
+
+		marks := hits(c, retrieved.Documents)
+		result := QueryResult{
+			ID:        c.ID,
+			Query:     c.Query,
+			Relevant:  relevantCount(c),
+			Retrieved: make([]RetrievedChunk, len(retrieved.Documents)),
+			LatencyMS: elapsed.Milliseconds(),
+		}
+		for i, doc := range retrieved.Documents {
+			result.Retrieved[i] = RetrievedChunk{
+				ID:         doc.ID,
+				DocumentID: doc.Payload[qdrant.DocumentField],
+				Source:     doc.Payload[qdrant.SourceField],
+				Score:      doc.Score,
+				Relevant:   marks[i],
+			}
+		}
+		result.Metrics = score(marks, result.Relevant, cutoffs)
+
+		metrics = append(metrics, result.Metrics)
+		report.Results = append(report.Results, result)
+	}
+
+	report.Metrics = mean(metrics)
+	if len(cases) > 0 {
+		report.MeanLatencyMS = float64(latency.Milliseconds()) / float64(len(cases))
+	}
+	return report, nil
+}
+
+// normalizeCutoffs sorts and deduplicates cutoffs, defaulting to
+// DefaultCutoffs.
+func normalizeCutoffs(cutoffs []int) ([]int, error) {
+	if len(cutoffs) == 0 {
+		return DefaultCutoffs, nil
+	}
+	out := slices.Clone(cutoffs)
+	for _, k := range out {
+		if k <= 0 {
+			return nil, fmt.Errorf("cutoff %d must be positive", k)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// relevantCount returns the number of distinct relevant documents of c.
+func relevantCount(c Case) int {
+	seen := make(map[string]bool)
+	for _, d := range c.Documents {
+		seen["document:"+d] = true
+	}
+	for _, s := range c.Sources {
+		seen["source:"+s] = true
+	}
+	return len(seen)
+}
+
+// mergeFilters returns base overlaid with extra, or base itself when extra
+// is empty.
+func mergeFilters(base, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ragagent "github.com/mfmezger/agentic_rag_go/internal/agent"
+	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// retrieverFunc adapts a function to Retriever.
+type retrieverFunc func(ctx context.Context, tenant, query string, opts ragagent.RetrieveOptions) (*ragagent.RetrievedContext, error)
+
+func (f retrieverFunc) Retrieve(ctx context.Context, tenant, query string, opts ragagent.RetrieveOptions) (*ragagent.RetrievedContext, error) {
+	return f(ctx, tenant, query, opts)
+}
+
+func TestEvaluateRetrieval(t *testing.T) {
+	var calls []ragagent.RetrieveOptions
+	r := retrieverFunc(func(ctx context.Context, tenant, query string, opts ragagent.RetrieveOptions) (*ragagent.RetrievedContext, error) {
+		assert.Equal(t, "acme", tenant)
+		calls = append(calls, opts)
+		docs := []qdrant.SearchResult{
+			{ID: "c1", Score: 0.9, Payload: map[string]string{qdrant.SourceField: "a.md"}},
+			{ID: "c2", Score: 0.8, Payload: map[string]string{qdrant.SourceField: "b.md", qdrant.DocumentField: "d2"}},
+		}
+		return &ragagent.RetrievedContext{Documents: docs}, nil
+	})
+	mmr := true
+	cases := []Case{
+		{ID: "first", Query: "q1", Sources: []string{"a.md"}},
+		{ID: "second", Query: "q2", Documents: []string{"d2"}, Filter: map[string]string{"team": "ops"}},
+	}
+
+	report, err := EvaluateRetrieval(context.Background(), r, cases, RetrievalOptions{
+		Tenant:   "acme",
+		Cutoffs:  []int{2, 1, 2},
+		Retrieve: ragagent.RetrieveOptions{MMR: &mmr, Filter: map[string]string{"lang": "en"}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, calls, 2)
+	assert.Equal(t, 2, calls[0].TopK, "top_k defaults to the largest cutoff")
+	assert.Equal(t, &mmr, calls[0].MMR)
+	assert.Equal(t, map[string]string{"lang": "en"}, calls[0].Filter)
+	assert.Equal(t, map[string]string{"lang": "en", "team": "ops"}, calls[1].Filter)
+
+	assert.Equal(t, 2, report.Queries)
+	require.Len(t, report.Results, 2)
+	first := report.Results[0]
+	assert.Equal(t, "first", first.ID)
+	assert.Equal(t, 1, first.Relevant)
+	assert.True(t, first.Retrieved[0].Relevant)
+	assert.Equal(t, "a.md", first.Retrieved[0].Source)
+	assert.Equal(t, "d2", report.Results[1].Retrieved[1].DocumentID)
+
+	// Relevant at rank 1 and rank 2
+	assert.InDelta(t, 0.75, report.Metrics.MRR, 1e-9)
+	require.Len(t, report.Metrics.Cutoffs, 2)
+	assert.Equal(t, 1, report.Metrics.Cutoffs[0].K)
+	assert.InDelta(t, 0.5, report.Metrics.Cutoffs[0].Recall, 1e-9)
+	assert.Equal(t, 2, report.Metrics.Cutoffs[1].K)
+	assert.InDelta(t, 1.0, report.Metrics.Cutoffs[1].Recall, 1e-9)
+	assert.InDelta(t, 0.5, report.Metrics.Cutoffs[1].Precision, 1e-9)
+}
+
+func TestEvaluateRetrieval_Errors(t *testing.T) {
+	failing := retrieverFunc(func(ctx context.Context, tenant, query string, opts ragagent.RetrieveOptions) (*ragagent.RetrievedContext, error) {
+		return nil, errors.New("qdrant unavailable")
+	})
+	cases := []Case{{ID: "q", Query: "q", Sources: []string{"a.md"}}}
+
+	_, err := EvaluateRetrieval(context.Background(), failing, cases, RetrievalOptions{})
+	assert.ErrorContains(t, err, "case q")
+
+	_, err = EvaluateRetrieval(context.Background(), failing, cases, RetrievalOptions{Cutoffs: []int{0}})
+	assert.Error(t, err)
+
+	_, err = EvaluateRetrieval(context.Background(), failing, []Case{{ID: "q", Query: "q"}}, RetrievalOptions{})
+	assert.ErrorContains(t, err, "documents or sources are required")
+}
@@ -0,0 +1,263 @@
+// Package feedback records chat answers and the feedback users give on
+// them, for finding retrieval gaps and building evaluation sets.
+package feedback
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rating is a user's verdict on an answer.
+type Rating string
+
+// Ratings.
+const (
+	RatingUp   Rating = "up"
+	RatingDown Rating = "down"
+)
+
+var (
+	// ErrUnknownRating is returned for an unsupported rating.
+	ErrUnknownRating = errors.New("unknown rating")
+	// ErrNotFound is returned when feedback refers to an answer that is
+	// unknown or belongs to another tenant or session.
+	ErrNotFound = errors.New("answer not found")
+)
+
+// ParseRating parses a rating name.
+func ParseRating(s string) (Rating, error) {
+	switch r := Rating(s); r {
+	case RatingUp, RatingDown:
+		return r, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownRating, s)
+	}
+}
+
+// Interaction is a chat answer as given to the user.
+type Interaction struct {
+	// ID identifies the answer; chat returns it as the message ID.
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Tenant    string `json:"tenant"`
+	UserID    string `json:"user_id,omitempty"`
+	Query     string `json:"query"`
+	// RetrievalQuery is the standalone rewrite of a follow-up question.
+	RetrievalQuery string `json:"retrieval_query,omitempty"`
+	// ChunkIDs lists the retrieved chunks in rank order,
+	// RetrievedSources their distinct sources. Neither says whether the
+	// chunks were relevant.
+	ChunkIDs         []string  `json:"chunk_ids"`
+	RetrievedSources []string  `json:"retrieved_sources,omitempty"`
+	Answer           string    `json:"answer"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Feedback is a user's verdict on an answer.
+type Feedback struct {
+	Rating  Rating `json:"rating"`
+	Comment string `json:"comment,omitempty"`
+	// CorrectedAnswer is the answer the user expected.
+	CorrectedAnswer string `json:"corrected_answer,omitempty"`
+	// Account is the API key or user that submitted the feedback.
+	Account     string    `json:"account,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// Record is an answer with the feedback given on it.
+type Record struct {
+	Interaction
+	Feedback
+}
+
+// Filter selects records to export. Zero fields match everything.
+type Filter struct {
+	Tenant string
+	Rating Rating
+}
+
+// Config configures a Store.
+type Config struct {
+	// Path persists feedback records as JSON; empty keeps them in memory.
+	Path string
+	// MaxPending caps the answers kept for feedback that has not arrived
+	// yet; the oldest are dropped first.
+	MaxPending int
+}
+
+// Store keeps recent answers until feedback arrives and the records of
+// answers that received feedback. Only records are persisted: answers
+// live as long as their in-memory chat sessions.
+type Store struct {
+	mu      sync.Mutex
+	pending map[string]Interaction
+	order   []string
+	records map[string]*Record
+	cfg     Config
+	now     func() time.Time
+}
+
+// NewStore creates a store, loading previously recorded feedback from
+// cfg.Path.
+func NewStore(cfg Config) (*Store, error) {
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 10000
+	}
+	s := &Store{
+		pending: make(map[string]Interaction),
+		records: make(map[string]*Record),
+		cfg:     cfg,
+		now:     time.Now,
+	}
+
+	if cfg.Path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback file: %w", err)
+	}
+	var records []*Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse feedback file: %w", err)
+	}
+	for _, r := range records {
+		s.records[r.ID] = r
+	}
+
+	return s, nil
+}
+
+// Add keeps an answer so feedback can be given on it.
+func (s *Store) Add(i Interaction) {
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = s.now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[i.ID]; !ok {
+		s.order = append(s.order, i.ID)
+	}
+	s.pending[i.ID] = i
+	for len(s.order) > s.cfg.MaxPending {
+		delete(s.pending, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Submit records feedback on the answer id given in session sessionID of
+// tenant. Submitting again replaces the earlier feedback.
+func (s *Store) Submit(tenant, sessionID, id string, f Feedback) (Record, error) {
+	if _, err := ParseRating(string(f.Rating)); err != nil {
+		return Record{}, err
+	}
+	f.SubmittedAt = s.now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var i Interaction
+	if r, ok := s.records[id]; ok {
+		i = r.Interaction
+	} else if p, ok := s.pending[id]; ok {
+		i = p
+	} else {
+		return Record{}, ErrNotFound
+	}
+	if i.Tenant != tenant || i.SessionID != sessionID {
+		return Record{}, ErrNotFound
+	}
+
+	prev := s.records[id]
+	record := &Record{Interaction: i, Feedback: f}
+	s.records[id] = record
+	if err := s.saveLocked(); err != nil {
+		// Keep memory and disk in step
+		if prev != nil {
+			s.records[id] = prev
+		} else {
+			delete(s.records, id)
+		}
+		return Record{}, err
+	}
+	return *record, nil
+}
+
+// Export returns the records matching filter, oldest feedback first.
+func (s *Store) Export(filter Filter) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Record
+	for _, r := range s.records {
+		if filter.Tenant != "" && r.Tenant != filter.Tenant {
+			continue
+		}
+		if filter.Rating != "" && r.Rating != filter.Rating {
+			continue
+		}
+		out = append(out, *r)
+	}
+	sortRecords(out)
+	return out
+}
+
+// sortRecords orders records by submission time, then ID.
+func sortRecords(records []Record) {
+	slices.SortFunc(records, func(a, b Record) int {
+		if c := a.SubmittedAt.Compare(b.SubmittedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// saveLocked writes the records to disk. Callers must hold s.mu.
+func (s *Store) saveLocked() error {
+	if s.cfg.Path == "" {
+		return nil
+	}
+
+	records := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, *r)
+	}
+	sortRecords(records)
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feedback: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated feedback file
+	tmp, err := os.CreateTemp(filepath.Dir(s.cfg.Path), ".feedback-*")
+	if err != nil {
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.cfg.Path); err != nil {
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package feedback
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, cfg Config) *Store {
+	t.Helper()
+	s, err := NewStore(cfg)
+	require.NoError(t, err)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return s
+}
+
+func interaction(id, tenant string) Interaction {
+	return Interaction{
+		ID:        id,
+		SessionID: "session-" + id,
+		Tenant:    tenant,
+		Query:     "question " + id,
+		ChunkIDs:  []string{"c1", "c2"},
+		Answer:    "answer " + id,
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	r, err := ParseRating("down")
+	require.NoError(t, err)
+	assert.Equal(t, RatingDown, r)
+
+	_, err = ParseRating("meh")
+	assert.ErrorIs(t, err, ErrUnknownRating)
+}
+
+func TestStore_Submit(t *testing.T) {
+	s := newTestStore(t, Config{})
+	s.Add(interaction("m1", "team-a"))
+
+	record, err := s.Submit("team-a", "session-m1", "m1", Feedback{
+		Rating:          RatingDown,
+		Comment:         "Wrong version",
+		CorrectedAnswer: "Restart after upgrading.",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "question m1", record.Query)
+	assert.Equal(t, []string{"c1", "c2"}, record.ChunkIDs)
+	assert.Equal(t, "answer m1", record.Answer)
+	assert.Equal(t, "Restart after upgrading.", record.CorrectedAnswer)
+	assert.False(t, record.CreatedAt.IsZero())
+	assert.True(t, record.SubmittedAt.After(record.CreatedAt))
+
+	// Resubmitting replaces the feedback
+	_, err = s.Submit("team-a", "session-m1", "m1", Feedback{Rating: RatingUp})
+	require.NoError(t, err)
+	records := s.Export(Filter{})
+	require.Len(t, records, 1)
+	assert.Equal(t, RatingUp, records[0].Rating)
+	assert.Empty(t, records[0].Comment)
+}
+
+func TestStore_SubmitNotFound(t *testing.T) {
+	s := newTestStore(t, Config{})
+	s.Add(interaction("m1", "team-a"))
+
+	_, err := s.Submit("team-a", "session-m1", "unknown", Feedback{Rating: RatingUp})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Submit("team-b", "session-m1", "m1", Feedback{Rating: RatingUp})
+	assert.ErrorIs(t, err, ErrNotFound, "other tenants cannot rate the answer")
+	_, err = s.Submit("team-a", "other-session", "m1", Feedback{Rating: RatingUp})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Submit("team-a", "session-m1", "m1", Feedback{Rating: "meh"})
+	assert.ErrorIs(t, err, ErrUnknownRating)
+}
+
+func TestStore_MaxPending(t *testing.T) {
+	s := newTestStore(t, Config{MaxPending: 2})
+	s.Add(interaction("m1", "t"))
+	s.Add(interaction("m2", "t"))
+	s.Add(interaction("m3", "t"))
+
+	_, err := s.Submit("t", "session-m1", "m1", Feedback{Rating: RatingUp})
+	assert.ErrorIs(t, err, ErrNotFound, "oldest answer dropped")
+	_, err = s.Submit("t", "session-m3", "m3", Feedback{Rating: RatingUp})
+	assert.NoError(t, err)
+}
+
+func TestStore_Export(t *testing.T) {
+	s := newTestStore(t, Config{})
+	for _, id := range []string{"m1", "m2", "m3"} {
+		tenant := "team-a"
+		if id == "m2" {
+			tenant = "team-b"
+		}
+		s.Add(interaction(id, tenant))
+	}
+	s.Add(interaction("m4", "team-a")) // never rated
+
+	_, err := s.Submit("team-a", "session-m3", "m3", Feedback{Rating: RatingDown})
+	require.NoError(t, err)
+	_, err = s.Submit("team-b", "session-m2", "m2", Feedback{Rating: RatingDown})
+	require.NoError(t, err)
+	_, err = s.Submit("team-a", "session-m1", "m1", Feedback{Rating: RatingUp})
+	require.NoError(t, err)
+
+	ids := func(records []Record) []string {
+		var out []string
+		for _, r := range records {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"m3", "m2", "m1"}, ids(s.Export(Filter{})))
+	assert.Equal(t, []string{"m3", "m1"}, ids(s.Export(Filter{Tenant: "team-a"})))
+	assert.Equal(t, []string{"m3", "m2"}, ids(s.Export(Filter{Rating: RatingDown})))
+	assert.Empty(t, s.Export(Filter{Tenant: "team-c"}))
+}
+
+func TestStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.json")
+
+	s := newTestStore(t, Config{Path: path})
+	s.Add(interaction("m1", "team-a"))
+	s.Add(interaction("m2", "team-a"))
+	_, err := s.Submit("team-a", "session-m1", "m1", Feedback{Rating: RatingDown, Comment: "outdated"})
+	require.NoError(t, err)
+
+	reloaded, err := NewStore(Config{Path: path})
+	require.NoError(t, err)
+	records := reloaded.Export(Filter{})
+	require.Len(t, records, 1)
+	assert.Equal(t, "m1", records[0].ID)
+	assert.Equal(t, "outdated", records[0].Comment)
+	assert.Equal(t, "answer m1", records[0].Answer)
+
+	// Rated answers can be re-rated after a restart; unrated ones are gone
+	_, err = reloaded.Submit("team-a", "session-m1", "m1", Feedback{Rating: RatingUp})
+	assert.NoError(t, err)
+	_, err = reloaded.Submit("team-a", "session-m2", "m2", Feedback{Rating: RatingUp})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := NewStore(Config{Path: path})
+	assert.Error(t, err)
+}
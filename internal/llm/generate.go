@@ -0,0 +1,84 @@
+// Package llm provides one-shot text generation on top of an ADK model,
+// for pipeline steps that need the LLM outside of an agent run.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// ErrEmptyResponse is returned when the model produced no text.
+var ErrEmptyResponse = errors.New("model returned no text")
+
+// Request is a single-turn generation request.
+type Request struct {
+	// Instruction is the system instruction; optional.
+	Instruction string
+	Prompt      string
+	// Temperature overrides the model default when set.
+	Temperature *float32
+	// JSON asks the model for a JSON response.
+	JSON bool
+}
+
+// Generate sends req to m and returns the concatenated response text.
+// Token usage is charged to the usage meter in ctx.
+func Generate(ctx context.Context, m model.LLM, req Request) (string, error) {
+	cfg := &genai.GenerateContentConfig{Temperature: req.Temperature}
+	if req.Instruction != "" {
+		cfg.SystemInstruction = genai.NewContentFromText(req.Instruction, genai.RoleUser)
+	}
+	if req.JSON {
+		cfg.ResponseMIMEType = "application/json"
+	}
+
+	llmReq := &model.LLMRequest{
+		Model:    m.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(req.Prompt, genai.RoleUser)},
+		Config:   cfg,
+	}
+
+	var text strings.Builder
+	for resp, err := range m.GenerateContent(ctx, llmReq, false) {
+		if err != nil {
+			return "", fmt.Errorf("generate content failed: %w", err)
+		}
+		usage.Charge(ctx, usage.FromMetadata(resp.UsageMetadata))
+		if resp.Content == nil {
+			continue
+		}
+		for _, p := range resp.Content.Parts {
+			if p.Text != "" && !p.Thought {
+				text.WriteString(p.Text)
+			}
+		}
+	}
+
+	out := strings.TrimSpace(text.String())
+	if out == "" {
+		return "", ErrEmptyResponse
+	}
+	return out, nil
+}
+
+// Truncate shortens s to at most n runes, marking the cut with an ellipsis.
+// Use it to bound prompt size when embedding retrieved or historical text.
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// Float32 returns a pointer to v, for Request.Temperature.
+func Float32(v float32) *float32 {
+	return &v
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/mfmezger/agentic_rag_go/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestGenerate(t *testing.T) {
+	var got *model.LLMRequest
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		got = req
+		return "  answer\n", nil
+	})
+
+	meter := &usage.Meter{}
+	ctx := usage.WithMeter(context.Background(), meter)
+
+	out, err := Generate(ctx, m, Request{
+		Instruction: "be brief",
+		Prompt:      "question",
+		Temperature: Float32(0.2),
+		JSON:        true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "answer", out)
+
+	assert.Equal(t, "question", mocks.PromptText(got))
+	assert.Equal(t, "be brief", got.Config.SystemInstruction.Parts[0].Text)
+	assert.Equal(t, float32(0.2), *got.Config.Temperature)
+	assert.Equal(t, "application/json", got.Config.ResponseMIMEType)
+	assert.Equal(t, int64(15), meter.Usage().Total())
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	failing := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		return "", errors.New("boom")
+	})
+	_, err := Generate(context.Background(), failing, Request{Prompt: "q"})
+	assert.ErrorContains(t, err, "boom")
+
+	empty := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		return " ", nil
+	})
+	_, err = Generate(context.Background(), empty, Request{Prompt: "q"})
+	assert.ErrorIs(t, err, ErrEmptyResponse)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "hél…", Truncate("héllo", 3))
+}
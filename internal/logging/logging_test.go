@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_JSONWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Level: "info", Format: "json"}, &buf)
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	logger.With("component", "test").InfoContext(ctx, "hello", "n", 1)
+	logger.DebugContext(ctx, "filtered")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "req-123", record["request_id"])
+	assert.Equal(t, "test", record["component"])
+	assert.NotContains(t, buf.String(), "filtered")
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Level: "debug"}, &buf)
+	require.NoError(t, err)
+
+	logger.Debug("details", "key", "value")
+	assert.Contains(t, buf.String(), "level=DEBUG")
+	assert.Contains(t, buf.String(), "key=value")
+	assert.NotContains(t, buf.String(), "request_id")
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(Config{Level: "verbose"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = New(Config{Format: "xml"}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	}
+
+	for in, want := range tests {
+		got, err := ParseLevel(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+	assert.Equal(t, "abc", RequestID(WithRequestID(context.Background(), "abc")))
+}
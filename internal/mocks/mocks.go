@@ -3,10 +3,14 @@ package mocks
 
 import (
 	"context"
+	"iter"
 
 	"github.com/mfmezger/agentic_rag_go/internal/embedding"
 	"github.com/mfmezger/agentic_rag_go/internal/vectorstore/qdrant"
 	"github.com/stretchr/testify/mock"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
 )
 
 // MockEmbeddingService is a mock implementation of embedding.Service.
@@ -50,14 +54,14 @@ func (m *MockQdrantClient) EnsureCollection(ctx context.Context, name string, ve
 }
 
 // Upsert mocks the Upsert method.
-func (m *MockQdrantClient) Upsert(ctx context.Context, collection string, docs []qdrant.Document) error {
-	args := m.Called(ctx, collection, docs)
+func (m *MockQdrantClient) Upsert(ctx context.Context, collection, tenant string, docs []qdrant.Document) error {
+	args := m.Called(ctx, collection, tenant, docs)
 	return args.Error(0)
 }
 
 // HybridSearch mocks the HybridSearch method.
-func (m *MockQdrantClient) HybridSearch(ctx context.Context, collection string, denseVector []float32, sparseVector *qdrant.SparseVector, topK uint64) ([]qdrant.SearchResult, error) {
-	args := m.Called(ctx, collection, denseVector, sparseVector, topK)
+func (m *MockQdrantClient) HybridSearch(ctx context.Context, collection, tenant string, denseVector []float32, sparseVector *qdrant.SparseVector, topK uint64) ([]qdrant.SearchResult, error) {
+	args := m.Called(ctx, collection, tenant, denseVector, sparseVector, topK)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -97,3 +101,44 @@ func (m *MockAgentFactory) EmbeddingService() *embedding.Service {
 	}
 	return args.Get(0).(*embedding.Service)
 }
+
+// LLMFunc adapts a function to model.LLM. The function sees the request and
+// returns the response text, so tests can answer based on the prompt.
+type LLMFunc func(ctx context.Context, req *model.LLMRequest) (string, error)
+
+// Name implements model.LLM.
+func (f LLMFunc) Name() string {
+	return "mock-llm"
+}
+
+// GenerateContent implements model.LLM with a single, final response.
+func (f LLMFunc) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		text, err := f(ctx, req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		yield(&model.LLMResponse{
+			Content: genai.NewContentFromText(text, genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     10,
+				CandidatesTokenCount: 5,
+				TotalTokenCount:      15,
+			},
+			TurnComplete: true,
+		}, nil)
+	}
+}
+
+// PromptText returns the text of the last user content in req.
+func PromptText(req *model.LLMRequest) string {
+	if len(req.Contents) == 0 {
+		return ""
+	}
+	var text string
+	for _, p := range req.Contents[len(req.Contents)-1].Parts {
+		text += p.Text
+	}
+	return text
+}
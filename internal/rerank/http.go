@@ -0,0 +1,143 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPConfig configures an HTTP reranker.
+type HTTPConfig struct {
+	// URL of the rerank endpoint, e.g. https://api.cohere.com/v2/rerank or
+	// a self-hosted cross-encoder behind the same API.
+	URL string
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+	// Model is passed through to the service.
+	Model   string
+	Timeout time.Duration
+}
+
+// HTTP calls a rerank service speaking the Cohere/Jina rerank API:
+// {"query", "documents"} in, {"results": [{"index", "relevance_score"}]} out.
+// A bare array of {"index", "score"} (text-embeddings-inference) is also
+// accepted.
+type HTTP struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+// NewHTTP creates an HTTP reranker.
+func NewHTTP(cfg HTTPConfig) (*HTTP, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("rerank url is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &HTTP{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+type httpRerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+type httpRerankResult struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"`
+}
+
+// Score implements Reranker.
+func (h *HTTP) Score(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(httpRerankRequest{
+		Model:     h.cfg.Model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rerank request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.cfg.APIKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rerank response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank service returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	results, err := parseHTTPResults(data)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]float64, len(documents))
+	seen := make([]bool, len(documents))
+	for _, r := range results {
+		if r.Index < 0 || r.Index >= len(documents) || seen[r.Index] {
+			return nil, fmt.Errorf("rerank service returned invalid index %d", r.Index)
+		}
+		seen[r.Index] = true
+		switch {
+		case r.RelevanceScore != nil:
+			scores[r.Index] = *r.RelevanceScore
+		case r.Score != nil:
+			scores[r.Index] = *r.Score
+		}
+	}
+	if len(results) != len(documents) {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrScoreCount, len(results), len(documents))
+	}
+
+	return scores, nil
+}
+
+func parseHTTPResults(data []byte) ([]httpRerankResult, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var results []httpRerankResult
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+		}
+		return results, nil
+	}
+
+	var wrapped struct {
+		Results []httpRerankResult `json:"results"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+	return wrapped.Results, nil
+}
@@ -0,0 +1,82 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTP_RequiresURL(t *testing.T) {
+	_, err := NewHTTP(HTTPConfig{})
+	assert.Error(t, err)
+}
+
+func TestHTTP_Score(t *testing.T) {
+	var got httpRerankRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		// Results come back sorted by relevance, not input order
+		w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.1}]}`))
+	}))
+	defer srv.Close()
+
+	h, err := NewHTTP(HTTPConfig{URL: srv.URL, APIKey: "secret", Model: "rerank-test"})
+	require.NoError(t, err)
+
+	scores, err := h.Score(context.Background(), "query", []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.1, 0.9}, scores)
+	assert.Equal(t, "rerank-test", got.Model)
+	assert.Equal(t, []string{"a", "b"}, got.Documents)
+	assert.Equal(t, 2, got.TopN)
+}
+
+func TestHTTP_ScoreBareArray(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"index":0,"score":0.3},{"index":1,"score":0.7}]`))
+	}))
+	defer srv.Close()
+
+	h, err := NewHTTP(HTTPConfig{URL: srv.URL})
+	require.NoError(t, err)
+
+	scores, err := h.Score(context.Background(), "query", []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.3, 0.7}, scores)
+}
+
+func TestHTTP_ScoreErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "server error", status: http.StatusInternalServerError, body: `{"error":"down"}`},
+		{name: "invalid json", status: http.StatusOK, body: `not json`},
+		{name: "index out of range", status: http.StatusOK, body: `{"results":[{"index":5,"relevance_score":1}]}`},
+		{name: "missing results", status: http.StatusOK, body: `{"results":[{"index":0,"relevance_score":1}]}`},
+		{name: "duplicate index", status: http.StatusOK, body: `{"results":[{"index":0,"relevance_score":1},{"index":0,"relevance_score":1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			h, err := NewHTTP(HTTPConfig{URL: srv.URL})
+			require.NoError(t, err)
+
+			_, err = h.Score(context.Background(), "query", []string{"a", "b"})
+			assert.Error(t, err)
+		})
+	}
+}
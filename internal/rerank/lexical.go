@@ -0,0 +1,95 @@
+package rerank
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Lexical scores candidates with BM25 computed over the candidate set.
+// It needs no model or network access and complements dense retrieval,
+// which tends to miss exact terms such as identifiers and error codes.
+type Lexical struct{}
+
+// NewLexical creates a lexical reranker.
+func NewLexical() *Lexical {
+	return &Lexical{}
+}
+
+// Score implements Reranker.
+func (l *Lexical) Score(ctx context.Context, query string, documents []string) ([]float64, error) {
+	terms := uniqueTerms(Tokenize(query))
+	scores := make([]float64, len(documents))
+	if len(terms) == 0 || len(documents) == 0 {
+		return scores, nil
+	}
+
+	docs := make([]map[string]int, len(documents))
+	lengths := make([]int, len(documents))
+	var totalLength int
+	df := make(map[string]int, len(terms))
+
+	for i, doc := range documents {
+		tokens := Tokenize(doc)
+		lengths[i] = len(tokens)
+		totalLength += len(tokens)
+
+		tf := make(map[string]int)
+		for _, tok := range tokens {
+			tf[tok]++
+		}
+		docs[i] = tf
+
+		for _, term := range terms {
+			if tf[term] > 0 {
+				df[term]++
+			}
+		}
+	}
+
+	avgLength := float64(totalLength) / float64(len(documents))
+	if avgLength == 0 {
+		return scores, nil
+	}
+	n := float64(len(documents))
+
+	for i, tf := range docs {
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(lengths[i])/avgLength)
+		for _, term := range terms {
+			freq := float64(tf[term])
+			if freq == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			scores[i] += idf * freq * (bm25K1 + 1) / (freq + norm)
+		}
+	}
+
+	return scores, nil
+}
+
+// Tokenize lowercases text and splits it into letter and digit runs.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTerms(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	terms := tokens[:0:0]
+	for _, tok := range tokens {
+		if !seen[tok] {
+			seen[tok] = true
+			terms = append(terms, tok)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,44 @@
+package rerank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"error", "e1234", "in", "go", "1", "25"}, Tokenize("Error E1234 in Go-1.25!"))
+	assert.Empty(t, Tokenize(" ,.; "))
+}
+
+func TestLexical_Score(t *testing.T) {
+	docs := []string{
+		"The weather today is sunny and warm.",
+		"Qdrant supports hybrid search with sparse vectors.",
+		"Hybrid search combines dense and sparse retrieval; hybrid ranking uses RRF.",
+		"",
+	}
+
+	scores, err := NewLexical().Score(context.Background(), "hybrid search sparse", docs)
+	require.NoError(t, err)
+	require.Len(t, scores, len(docs))
+
+	assert.Zero(t, scores[0])
+	assert.Zero(t, scores[3])
+	assert.Greater(t, scores[1], scores[0])
+	assert.Greater(t, scores[2], scores[0])
+	// Both matching docs outrank the unrelated ones
+	assert.ElementsMatch(t, []int{1, 2}, Order(scores)[:2])
+}
+
+func TestLexical_EmptyInput(t *testing.T) {
+	scores, err := NewLexical().Score(context.Background(), "?!", []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0}, scores)
+
+	scores, err = NewLexical().Score(context.Background(), "query", nil)
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+}
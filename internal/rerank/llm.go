@@ -0,0 +1,83 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/mfmezger/agentic_rag_go/internal/llm"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/adk/model"
+)
+
+// llmInstruction asks for a single pointwise relevance grade.
+const llmInstruction = `You are a search relevance judge. Rate how well the passage answers the query on a scale from 0 (irrelevant) to 10 (fully answers it). Reply with the number only.`
+
+// maxPassageRunes truncates long passages to bound prompt cost.
+const maxPassageRunes = 4000
+
+var scorePattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// LLM scores each candidate independently by asking the model for a
+// relevance grade. It is slower and costlier than a cross-encoder service
+// but needs no extra infrastructure.
+type LLM struct {
+	model       model.LLM
+	concurrency int
+}
+
+// NewLLM creates an LLM reranker running up to concurrency scoring calls
+// at once.
+func NewLLM(m model.LLM, concurrency int) *LLM {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	return &LLM{model: m, concurrency: concurrency}
+}
+
+// Score implements Reranker. Failed or unparsable grades score zero so one
+// bad call doesn't discard the whole ranking.
+func (l *LLM) Score(ctx context.Context, query string, documents []string) ([]float64, error) {
+	scores := make([]float64, len(documents))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(l.concurrency)
+
+	for i, doc := range documents {
+		g.Go(func() error {
+			text, err := llm.Generate(gctx, l.model, llm.Request{
+				Instruction: llmInstruction,
+				Prompt:      fmt.Sprintf("Query: %s\n\nPassage:\n%s\n\nRelevance (0-10):", query, llm.Truncate(doc, maxPassageRunes)),
+				Temperature: llm.Float32(0),
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return nil
+			}
+			scores[i] = parseGrade(text)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// parseGrade extracts the first number in text, clamped to [0, 10].
+func parseGrade(text string) float64 {
+	match := scorePattern.FindString(text)
+	if match == "" {
+		return 0
+	}
+	grade, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0
+	}
+	return min(max(grade, 0), 10)
+}
@@ -0,0 +1,56 @@
+package rerank
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mfmezger/agentic_rag_go/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/adk/model"
+)
+
+func TestLLM_Score(t *testing.T) {
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		prompt := mocks.PromptText(req)
+		switch {
+		case strings.Contains(prompt, "relevant passage"):
+			return "9", nil
+		case strings.Contains(prompt, "chatty passage"):
+			return "I'd say 6.5 out of 10.", nil
+		case strings.Contains(prompt, "failing passage"):
+			return "", errors.New("model unavailable")
+		}
+		return "nothing useful", nil
+	})
+
+	scores, err := NewLLM(m, 2).Score(context.Background(), "query", []string{
+		"relevant passage",
+		"chatty passage",
+		"failing passage",
+		"other passage",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{9, 6.5, 0, 0}, scores)
+}
+
+func TestLLM_ScoreCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := mocks.LLMFunc(func(ctx context.Context, req *model.LLMRequest) (string, error) {
+		return "", ctx.Err()
+	})
+
+	_, err := NewLLM(m, 1).Score(ctx, "query", []string{"a"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseGrade(t *testing.T) {
+	assert.Equal(t, 7.0, parseGrade("7"))
+	assert.Equal(t, 10.0, parseGrade("42"))
+	assert.Equal(t, 0.0, parseGrade("none"))
+}
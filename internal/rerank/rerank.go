@@ -0,0 +1,35 @@
+// Package rerank rescores retrieved documents against the query.
+//
+// Hybrid search with rank fusion favours recall; a reranker looks at the
+// query and each candidate together to improve precision at the top of the
+// list, which is what ends up in the prompt.
+package rerank
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// Reranker scores documents by relevance to a query.
+type Reranker interface {
+	// Score returns one score per document, in input order; higher is more
+	// relevant. Scores are only comparable within a single call.
+	Score(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// ErrScoreCount is returned when a reranker returns the wrong number of scores.
+var ErrScoreCount = errors.New("reranker returned wrong number of scores")
+
+// Order returns document indices sorted by descending score. Ties keep
+// their input order, so the retrieval ranking breaks them.
+func Order(scores []float64) []int {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	return order
+}
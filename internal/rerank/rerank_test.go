@@ -0,0 +1,14 @@
+package rerank
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 3, 0, 2}, Order([]float64{0.2, 0.9, 0.1, 0.5}))
+	// Ties keep input order
+	assert.Equal(t, []int{1, 0, 2}, Order([]float64{1, 2, 1}))
+	assert.Empty(t, Order(nil))
+}
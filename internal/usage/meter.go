@@ -0,0 +1,42 @@
+package usage
+
+import (
+	"context"
+	"sync"
+)
+
+// Meter accumulates the usage of a single request. It is carried in the
+// context so components deep in the call chain, such as the embedding
+// service or reranker, can charge the tokens they consume.
+type Meter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Add charges u to the meter.
+func (m *Meter) Add(u Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Add(u)
+}
+
+// Usage returns the usage charged so far.
+func (m *Meter) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+type meterKey struct{}
+
+// WithMeter returns a copy of ctx carrying m.
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// Charge adds u to the meter in ctx, if any.
+func Charge(ctx context.Context, u Usage) {
+	if m, ok := ctx.Value(meterKey{}).(*Meter); ok && m != nil {
+		m.Add(u)
+	}
+}
@@ -0,0 +1,29 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter_Charge(t *testing.T) {
+	// Charging without a meter is a no-op
+	Charge(context.Background(), Usage{PromptTokens: 1})
+
+	m := &Meter{}
+	ctx := WithMeter(context.Background(), m)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Charge(ctx, Usage{PromptTokens: 2, EmbeddingTokens: 1})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, Usage{PromptTokens: 20, EmbeddingTokens: 10}, m.Usage())
+}
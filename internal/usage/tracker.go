@@ -0,0 +1,303 @@
+// Package usage tracks token consumption per account and enforces quotas.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+)
+
+// Usage counts consumed tokens.
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	EmbeddingTokens  int64 `json:"embedding_tokens"`
+}
+
+// Total returns the number of tokens counted against quotas.
+func (u Usage) Total() int64 {
+	return u.PromptTokens + u.CompletionTokens + u.EmbeddingTokens
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.EmbeddingTokens += other.EmbeddingTokens
+}
+
+// FromMetadata converts Gemini usage metadata into LLM token usage.
+// Tokens spent on thinking and tool calls count as completion tokens.
+func FromMetadata(md *genai.GenerateContentResponseUsageMetadata) Usage {
+	if md == nil {
+		return Usage{}
+	}
+
+	prompt := int64(md.PromptTokenCount) + int64(md.ToolUsePromptTokenCount)
+	completion := int64(md.CandidatesTokenCount) + int64(md.ThoughtsTokenCount)
+	if total := int64(md.TotalTokenCount); total > prompt+completion {
+		completion = total - prompt
+	}
+
+	return Usage{PromptTokens: prompt, CompletionTokens: completion}
+}
+
+// EstimateTokens approximates the token count of texts at four characters
+// per token. The embedding API reports no usage, so embeddings are
+// accounted with this estimate.
+func EstimateTokens(texts ...string) int64 {
+	var tokens int64
+	for _, text := range texts {
+		tokens += int64(utf8.RuneCountInString(text)+3) / 4
+	}
+	return tokens
+}
+
+// Quota limits the total tokens an account may use per period.
+// Zero means unlimited.
+type Quota struct {
+	Daily   int64
+	Monthly int64
+}
+
+// ErrQuotaExceeded is returned when an account has used up a quota.
+var ErrQuotaExceeded = errors.New("token quota exceeded")
+
+// QuotaError describes the exceeded quota.
+type QuotaError struct {
+	Period  string // "daily" or "monthly"
+	Limit   int64
+	Used    int64
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s token quota exceeded: used %d of %d", e.Period, e.Used, e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Period is the usage of an account in the current day or month.
+type Period struct {
+	Start time.Time
+	Reset time.Time
+	Usage Usage
+	// Limit is zero when the period is unlimited.
+	Limit int64
+}
+
+// Remaining returns the tokens left in the period, or -1 when unlimited.
+func (p Period) Remaining() int64 {
+	if p.Limit <= 0 {
+		return -1
+	}
+	return max(p.Limit-p.Usage.Total(), 0)
+}
+
+// Report is the current usage of an account.
+type Report struct {
+	Account string
+	Tenant  string
+	Daily   Period
+	Monthly Period
+}
+
+// Config configures a Tracker.
+type Config struct {
+	// Quota applies to every account without a tenant override.
+	Quota Quota
+	// TenantQuotas overrides Quota for the accounts of a tenant.
+	TenantQuotas map[string]Quota
+	// Path persists usage counters as JSON; empty keeps them in memory.
+	Path string
+}
+
+// account holds the counters of one account. Periods are UTC and reset
+// lazily when the account is next touched.
+type account struct {
+	Tenant  string `json:"tenant"`
+	Day     string `json:"day"`
+	Daily   Usage  `json:"daily"`
+	Month   string `json:"month"`
+	Monthly Usage  `json:"monthly"`
+}
+
+const (
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+// Tracker records token usage per account and checks it against quotas.
+// Checks happen before work starts and usage is recorded afterwards, so
+// concurrent requests may overshoot a quota by up to one request each.
+type Tracker struct {
+	mu       sync.Mutex
+	accounts map[string]*account
+	cfg      Config
+	now      func() time.Time
+}
+
+// NewTracker creates a tracker, loading previously recorded usage from
+// cfg.Path.
+func NewTracker(cfg Config) (*Tracker, error) {
+	t := &Tracker{
+		accounts: make(map[string]*account),
+		cfg:      cfg,
+		now:      time.Now,
+	}
+
+	if cfg.Path == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+
+	return t, nil
+}
+
+// Check returns a *QuotaError when the account has used up its daily or
+// monthly quota.
+func (t *Tracker) Check(accountID, tenant string) error {
+	report := t.Report(accountID, tenant)
+
+	for _, p := range []struct {
+		name   string
+		period Period
+	}{{"daily", report.Daily}, {"monthly", report.Monthly}} {
+		if p.period.Limit > 0 && p.period.Usage.Total() >= p.period.Limit {
+			return &QuotaError{
+				Period:  p.name,
+				Limit:   p.period.Limit,
+				Used:    p.period.Usage.Total(),
+				ResetAt: p.period.Reset,
+			}
+		}
+	}
+
+	return nil
+}
+
+// Record adds usage to the account's daily and monthly counters.
+func (t *Tracker) Record(accountID, tenant string, u Usage) error {
+	if u.Total() == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a := t.accountLocked(accountID, tenant)
+	a.Daily.Add(u)
+	a.Monthly.Add(u)
+
+	return t.saveLocked()
+}
+
+// Report returns the account's usage in the current day and month.
+func (t *Tracker) Report(accountID, tenant string) Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	quota := t.quotaFor(tenant)
+
+	report := Report{
+		Account: accountID,
+		Tenant:  tenant,
+		Daily:   Period{Start: day, Reset: day.AddDate(0, 0, 1), Limit: quota.Daily},
+		Monthly: Period{Start: month, Reset: month.AddDate(0, 1, 0), Limit: quota.Monthly},
+	}
+
+	if a, ok := t.accounts[accountID]; ok {
+		if a.Day == day.Format(dayLayout) {
+			report.Daily.Usage = a.Daily
+		}
+		if a.Month == month.Format(monthLayout) {
+			report.Monthly.Usage = a.Monthly
+		}
+	}
+
+	return report
+}
+
+func (t *Tracker) quotaFor(tenant string) Quota {
+	if q, ok := t.cfg.TenantQuotas[tenant]; ok {
+		return q
+	}
+	return t.cfg.Quota
+}
+
+// accountLocked returns the account, resetting counters of past periods.
+// Callers must hold t.mu.
+func (t *Tracker) accountLocked(accountID, tenant string) *account {
+	a, ok := t.accounts[accountID]
+	if !ok {
+		a = &account{}
+		t.accounts[accountID] = a
+	}
+	a.Tenant = tenant
+
+	now := t.now().UTC()
+	if day := now.Format(dayLayout); a.Day != day {
+		a.Day = day
+		a.Daily = Usage{}
+	}
+	if month := now.Format(monthLayout); a.Month != month {
+		a.Month = month
+		a.Monthly = Usage{}
+	}
+
+	return a
+}
+
+// saveLocked writes the counters to disk. Callers must hold t.mu.
+func (t *Tracker) saveLocked() error {
+	if t.cfg.Path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(t.accounts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated usage file
+	tmp, err := os.CreateTemp(filepath.Dir(t.cfg.Path), ".usage-*")
+	if err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.cfg.Path); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+
+	return nil
+}
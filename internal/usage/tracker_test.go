@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+var testNow = time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)
+
+func newTestTracker(t *testing.T, cfg Config) (*Tracker, *time.Time) {
+	t.Helper()
+	tr, err := NewTracker(cfg)
+	require.NoError(t, err)
+	clock := testNow
+	tr.now = func() time.Time { return clock }
+	return tr, &clock
+}
+
+func TestFromMetadata(t *testing.T) {
+	assert.Equal(t, Usage{}, FromMetadata(nil))
+
+	u := FromMetadata(&genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        100,
+		ToolUsePromptTokenCount: 20,
+		CandidatesTokenCount:    30,
+		ThoughtsTokenCount:      10,
+		TotalTokenCount:         160,
+	})
+	assert.Equal(t, Usage{PromptTokens: 120, CompletionTokens: 40}, u)
+	assert.Equal(t, int64(160), u.Total())
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, int64(0), EstimateTokens())
+	assert.Equal(t, int64(1), EstimateTokens("abc"))
+	assert.Equal(t, int64(2), EstimateTokens("abcdefgh"))
+	assert.Equal(t, int64(3), EstimateTokens("abcd", "äöüßé"))
+}
+
+func TestTracker_RecordAndReport(t *testing.T) {
+	tr, _ := newTestTracker(t, Config{Quota: Quota{Daily: 1000}})
+
+	require.NoError(t, tr.Record("key:k1", "team-a", Usage{PromptTokens: 100, CompletionTokens: 50}))
+	require.NoError(t, tr.Record("key:k1", "team-a", Usage{EmbeddingTokens: 10}))
+
+	report := tr.Report("key:k1", "team-a")
+	assert.Equal(t, Usage{PromptTokens: 100, CompletionTokens: 50, EmbeddingTokens: 10}, report.Daily.Usage)
+	assert.Equal(t, report.Daily.Usage, report.Monthly.Usage)
+	assert.Equal(t, int64(840), report.Daily.Remaining())
+	assert.Equal(t, int64(-1), report.Monthly.Remaining())
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), report.Daily.Reset)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), report.Monthly.Start)
+
+	// Other accounts are unaffected
+	assert.Equal(t, Usage{}, tr.Report("key:k2", "team-a").Daily.Usage)
+}
+
+func TestTracker_Check(t *testing.T) {
+	tr, clock := newTestTracker(t, Config{
+		Quota:        Quota{Daily: 100, Monthly: 150},
+		TenantQuotas: map[string]Quota{"big": {}},
+	})
+
+	require.NoError(t, tr.Check("key:k1", "team-a"))
+	require.NoError(t, tr.Record("key:k1", "team-a", Usage{PromptTokens: 100}))
+
+	err := tr.Check("key:k1", "team-a")
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	var quotaErr *QuotaError
+	require.True(t, errors.As(err, &quotaErr))
+	assert.Equal(t, "daily", quotaErr.Period)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), quotaErr.ResetAt)
+
+	// A tenant override without limits is unlimited
+	require.NoError(t, tr.Record("key:k2", "big", Usage{PromptTokens: 1000}))
+	assert.NoError(t, tr.Check("key:k2", "big"))
+
+	// The daily quota resets the next day; the monthly one the next month
+	*clock = time.Date(2025, 6, 29, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, tr.Record("key:k3", "team-a", Usage{PromptTokens: 90}))
+	*clock = time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, tr.Check("key:k3", "team-a"))
+	require.NoError(t, tr.Record("key:k3", "team-a", Usage{PromptTokens: 60}))
+
+	err = tr.Check("key:k3", "team-a")
+	require.True(t, errors.As(err, &quotaErr))
+	assert.Equal(t, "monthly", quotaErr.Period)
+
+	*clock = time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, tr.Check("key:k3", "team-a"))
+}
+
+func TestTracker_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	tr, _ := newTestTracker(t, Config{Path: path})
+	require.NoError(t, tr.Record("user:team-a/alice", "team-a", Usage{CompletionTokens: 42}))
+
+	reloaded, _ := newTestTracker(t, Config{Path: path})
+	assert.Equal(t, int64(42), reloaded.Report("user:team-a/alice", "team-a").Monthly.Usage.Total())
+}
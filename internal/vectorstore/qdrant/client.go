@@ -3,7 +3,11 @@ package qdrant
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 	pb "github.com/qdrant/go-client/qdrant"
@@ -11,6 +15,30 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// TenantField is the payload key holding the tenant that owns a point.
+const TenantField = "tenant_id"
+
+// Payload keys describing where a chunk came from.
+const (
+	// DocumentField holds the ID shared by all chunks of one upload.
+	DocumentField = "document_id"
+	// SourceField holds the caller-supplied source of the document.
+	SourceField = "source"
+	// ChunkIndexField holds the chunk's position within its document.
+	ChunkIndexField = "chunk_index"
+	// ParentField holds the ID of the parent section a child chunk
+	// belongs to, for small-to-big retrieval.
+	ParentField = "parent_id"
+	// ParentChunkField holds a child chunk's position within its parent.
+	ParentChunkField = "parent_chunk"
+	// ParentContentField holds the parent section's text. It is stored
+	// once per parent, on its first child.
+	ParentContentField = "parent_content"
+)
+
+// ErrTenantRequired is returned when a read or write is not scoped to a tenant.
+var ErrTenantRequired = errors.New("tenant is required")
+
 // Client is a Qdrant vector store client.
 type Client struct {
 	conn        *grpc.ClientConn
@@ -52,7 +80,8 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// EnsureCollection creates the collection if it doesn't exist.
+// EnsureCollection creates the collection if it doesn't exist and indexes
+// the tenant field, also on collections created before tenants existed.
 // Sets up for hybrid search with dense and sparse vectors.
 func (c *Client) EnsureCollection(ctx context.Context, name string, vectorSize uint64) error {
 	// Check if collection exists
@@ -64,9 +93,11 @@ func (c *Client) EnsureCollection(ctx context.Context, name string, vectorSize u
 	}
 
 	if exists.GetResult().GetExists() {
-		return nil
+		return c.ensureTenantIndex(ctx, name)
 	}
 
+	slog.InfoContext(ctx, "Creating qdrant collection", "collection", name, "vector_size", vectorSize)
+
 	// Create collection with named vectors for hybrid search
 	_, err = c.collections.Create(ctx, &pb.CreateCollection{
 		CollectionName: name,
@@ -92,9 +123,88 @@ func (c *Client) EnsureCollection(ctx context.Context, name string, vectorSize u
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
+	return c.createTenantIndex(ctx, name)
+}
+
+// ensureTenantIndex indexes the tenant field of an existing collection
+// unless it already is.
+func (c *Client) ensureTenantIndex(ctx context.Context, name string) error {
+	info, err := c.collections.Get(ctx, &pb.GetCollectionInfoRequest{
+		CollectionName: name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get collection info: %w", err)
+	}
+	if _, ok := info.GetResult().GetPayloadSchema()[TenantField]; ok {
+		return nil
+	}
+
+	slog.InfoContext(ctx, "Indexing tenant field of existing collection", "collection", name)
+	return c.createTenantIndex(ctx, name)
+}
+
+// createTenantIndex indexes the tenant field so filtered searches stay
+// fast per tenant.
+func (c *Client) createTenantIndex(ctx context.Context, name string) error {
+	isTenant := true
+	fieldType := pb.FieldType_FieldTypeKeyword
+	_, err := c.points.CreateFieldIndex(ctx, &pb.CreateFieldIndexCollection{
+		CollectionName: name,
+		FieldName:      TenantField,
+		FieldType:      &fieldType,
+		FieldIndexParams: pb.NewPayloadIndexParamsKeyword(&pb.KeywordIndexParams{
+			IsTenant: &isTenant,
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tenant index: %w", err)
+	}
+
 	return nil
 }
 
+// AssignTenant assigns the points stored without a tenant, before
+// multi-tenancy, to tenant so tenant-scoped reads find them again. It
+// returns the number of points assigned.
+func (c *Client) AssignTenant(ctx context.Context, collection, tenant string) (uint64, error) {
+	if tenant == "" {
+		return 0, ErrTenantRequired
+	}
+
+	filter := unassignedFilter()
+	exact := true
+	count, err := c.points.Count(ctx, &pb.CountPoints{
+		CollectionName: collection,
+		Filter:         filter,
+		Exact:          &exact,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count points without tenant: %w", err)
+	}
+	n := count.GetResult().GetCount()
+	if n == 0 {
+		return 0, nil
+	}
+
+	wait := true
+	_, err = c.points.SetPayload(ctx, &pb.SetPayloadPoints{
+		CollectionName: collection,
+		Wait:           &wait,
+		Payload:        map[string]*pb.Value{TenantField: pb.NewValueString(tenant)},
+		PointsSelector: pb.NewPointsSelectorFilter(filter),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign tenant: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Assigned points without tenant",
+		"collection", collection,
+		"tenant", tenant,
+		"points", n,
+	)
+	return n, nil
+}
+
 // Document represents a document to be stored.
 type Document struct {
 	ID       string
@@ -111,7 +221,13 @@ type SparseVector struct {
 }
 
 // Upsert inserts or updates documents in the collection.
-func (c *Client) Upsert(ctx context.Context, collection string, docs []Document) error {
+// Every point is stamped with the given tenant, overriding any tenant
+// supplied through document metadata.
+func (c *Client) Upsert(ctx context.Context, collection, tenant string, docs []Document) error {
+	if tenant == "" {
+		return ErrTenantRequired
+	}
+
 	points := make([]*pb.PointStruct, len(docs))
 
 	for i, doc := range docs {
@@ -131,6 +247,9 @@ func (c *Client) Upsert(ctx context.Context, collection string, docs []Document)
 				Kind: &pb.Value_StringValue{StringValue: v},
 			}
 		}
+		payload[TenantField] = &pb.Value{
+			Kind: &pb.Value_StringValue{StringValue: tenant},
+		}
 
 		// Build vectors
 		vectors := &pb.Vectors{
@@ -173,6 +292,7 @@ func (c *Client) Upsert(ctx context.Context, collection string, docs []Document)
 		points[i] = point
 	}
 
+	start := time.Now()
 	_, err := c.points.Upsert(ctx, &pb.UpsertPoints{
 		CollectionName: collection,
 		Points:         points,
@@ -181,6 +301,13 @@ func (c *Client) Upsert(ctx context.Context, collection string, docs []Document)
 		return fmt.Errorf("failed to upsert points: %w", err)
 	}
 
+	slog.DebugContext(ctx, "Upserted points",
+		"collection", collection,
+		"tenant", tenant,
+		"points", len(points),
+		"duration", time.Since(start),
+	)
+
 	return nil
 }
 
@@ -190,10 +317,32 @@ type SearchResult struct {
 	Score   float32
 	Content string
 	Payload map[string]string
+	// Vector is the dense vector, only set when requested.
+	Vector []float32
+	// Parent is the text of the parent section of a child chunk.
+	Parent string
+	// Passage is the chunk stitched together with its neighbouring chunks,
+	// set by callers that expand the context around a hit.
+	Passage string
+}
+
+// SearchOptions narrows a hybrid search.
+type SearchOptions struct {
+	// Match restricts results to points whose metadata fields equal the
+	// given values.
+	Match map[string]string
+	// WithVectors returns each result's dense vector.
+	WithVectors bool
 }
 
 // HybridSearch performs hybrid search with dense and sparse vectors.
-func (c *Client) HybridSearch(ctx context.Context, collection string, denseVector []float32, sparseVector *SparseVector, topK uint64) ([]SearchResult, error) {
+// Results are always restricted to points owned by the given tenant.
+func (c *Client) HybridSearch(ctx context.Context, collection, tenant string, denseVector []float32, sparseVector *SparseVector, topK uint64, opts SearchOptions) ([]SearchResult, error) {
+	if tenant == "" {
+		return nil, ErrTenantRequired
+	}
+	filter := tenantFilter(tenant, opts.Match)
+
 	// Build prefetch queries
 	prefetch := []*pb.PrefetchQuery{
 		{
@@ -206,8 +355,9 @@ func (c *Client) HybridSearch(ctx context.Context, collection string, denseVecto
 					},
 				},
 			},
-			Using: strPtr("dense"),
-			Limit: &topK,
+			Using:  strPtr("dense"),
+			Filter: filter,
+			Limit:  &topK,
 		},
 	}
 
@@ -226,14 +376,16 @@ func (c *Client) HybridSearch(ctx context.Context, collection string, denseVecto
 					},
 				},
 			},
-			Using: strPtr("sparse"),
-			Limit: &topK,
+			Using:  strPtr("sparse"),
+			Filter: filter,
+			Limit:  &topK,
 		})
 	}
 
 	// Fusion query using RRF (Reciprocal Rank Fusion)
+	start := time.Now()
 	limit := topK
-	resp, err := c.points.Query(ctx, &pb.QueryPoints{
+	query := &pb.QueryPoints{
 		CollectionName: collection,
 		Prefetch:       prefetch,
 		Query: &pb.Query{
@@ -241,40 +393,161 @@ func (c *Client) HybridSearch(ctx context.Context, collection string, denseVecto
 				Fusion: pb.Fusion_RRF,
 			},
 		},
+		Filter:      filter,
 		Limit:       &limit,
 		WithPayload: &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
-	})
+	}
+	if opts.WithVectors {
+		query.WithVectors = &pb.WithVectorsSelector{
+			SelectorOptions: &pb.WithVectorsSelector_Include{
+				Include: &pb.VectorsSelector{Names: []string{"dense"}},
+			},
+		}
+	}
+	resp, err := c.points.Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
 
 	results := make([]SearchResult, len(resp.Result))
 	for i, point := range resp.Result {
-		result := SearchResult{
-			Score:   point.Score,
-			Payload: make(map[string]string),
-		}
+		results[i] = newSearchResult(point.Id, point.Score, point.Payload, point.GetVectors())
+	}
 
-		// Extract ID
-		if uuid := point.Id.GetUuid(); uuid != "" {
-			result.ID = uuid
-		}
+	slog.DebugContext(ctx, "Hybrid search",
+		"collection", collection,
+		"tenant", tenant,
+		"top_k", topK,
+		"results", len(results),
+		"duration", time.Since(start),
+	)
+
+	return results, nil
+}
+
+// ScrollOptions selects points for Scroll.
+type ScrollOptions struct {
+	// Any restricts results to points matching at least one of the given
+	// conditions. A condition requires each of its payload fields to equal
+	// one of the listed values.
+	Any []map[string][]string
+	// Limit caps the number of points returned.
+	Limit uint32
+}
+
+// Scroll returns points owned by tenant that match opts, in no particular
+// order and without scores.
+func (c *Client) Scroll(ctx context.Context, collection, tenant string, opts ScrollOptions) ([]SearchResult, error) {
+	if tenant == "" {
+		return nil, ErrTenantRequired
+	}
+	filter := tenantFilter(tenant, nil)
+	filter.Should = anyFilter(opts.Any)
+
+	start := time.Now()
+	limit := opts.Limit
+	resp, err := c.points.Scroll(ctx, &pb.ScrollPoints{
+		CollectionName: collection,
+		Filter:         filter,
+		Limit:          &limit,
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scroll: %w", err)
+	}
+
+	results := make([]SearchResult, len(resp.Result))
+	for i, point := range resp.Result {
+		results[i] = newSearchResult(point.Id, 0, point.Payload, point.GetVectors())
+	}
+
+	slog.DebugContext(ctx, "Scrolled points",
+		"collection", collection,
+		"tenant", tenant,
+		"results", len(results),
+		"duration", time.Since(start),
+	)
 
-		// Extract payload
-		for k, v := range point.Payload {
-			if sv := v.GetStringValue(); sv != "" {
-				if k == "content" {
-					result.Content = sv
-				} else {
-					result.Payload[k] = sv
-				}
+	return results, nil
+}
+
+// newSearchResult converts a Qdrant point into a SearchResult.
+func newSearchResult(id *pb.PointId, score float32, payload map[string]*pb.Value, vectors *pb.VectorsOutput) SearchResult {
+	result := SearchResult{
+		ID:      id.GetUuid(),
+		Score:   score,
+		Payload: make(map[string]string),
+		Vector:  pointVector(vectors),
+	}
+
+	for k, v := range payload {
+		if sv := v.GetStringValue(); sv != "" {
+			switch k {
+			case "content":
+				result.Content = sv
+			case ParentContentField:
+				result.Parent = sv
+			case TenantField:
+				// Internal scoping field, not user metadata
+			default:
+				result.Payload[k] = sv
 			}
 		}
+	}
 
-		results[i] = result
+	return result
+}
+
+// pointVector extracts the "dense" named vector from a point, if present.
+func pointVector(vectors *pb.VectorsOutput) []float32 {
+	v := vectors.GetVectors().GetVectors()["dense"]
+	if dense := v.GetDense().GetData(); len(dense) > 0 {
+		return dense
 	}
+	// Older servers only fill the deprecated flat field
+	return v.GetData()
+}
 
-	return results, nil
+// tenantFilter restricts a query to points owned by tenant and, when
+// match is set, to points whose payload fields equal the given values.
+func tenantFilter(tenant string, match map[string]string) *pb.Filter {
+	must := []*pb.Condition{pb.NewMatchKeyword(TenantField, tenant)}
+
+	keys := make([]string, 0, len(match))
+	for k := range match {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		must = append(must, pb.NewMatchKeyword(k, match[k]))
+	}
+
+	return &pb.Filter{Must: must}
+}
+
+// unassignedFilter matches points stored without a tenant.
+func unassignedFilter() *pb.Filter {
+	return &pb.Filter{Must: []*pb.Condition{pb.NewIsEmpty(TenantField)}}
+}
+
+// anyFilter turns Scroll conditions into filter clauses, at least one of
+// which must match. Fields are sorted so the filter is deterministic.
+func anyFilter(conditions []map[string][]string) []*pb.Condition {
+	should := make([]*pb.Condition, 0, len(conditions))
+	for _, cond := range conditions {
+		keys := make([]string, 0, len(cond))
+		for k := range cond {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		must := make([]*pb.Condition, len(keys))
+		for i, k := range keys {
+			must[i] = pb.NewMatchKeywords(k, cond[k]...)
+		}
+		should = append(should, pb.NewFilterAsCondition(&pb.Filter{Must: must}))
+	}
+	return should
 }
 
 func strPtr(s string) *string {
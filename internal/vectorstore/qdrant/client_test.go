@@ -1,9 +1,12 @@
 package qdrant
 
 import (
+	"context"
 	"testing"
 
+	pb "github.com/qdrant/go-client/qdrant"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDocument_StructCreation(t *testing.T) {
@@ -417,3 +420,98 @@ func TestDocument_EmptyString(t *testing.T) {
 	assert.Nil(t, doc.Dense)
 	assert.Nil(t, doc.Sparse)
 }
+
+func TestTenantFilter(t *testing.T) {
+	filter := tenantFilter("team-a", nil)
+
+	require.Len(t, filter.Must, 1)
+	field := filter.Must[0].GetField()
+	require.NotNil(t, field)
+	assert.Equal(t, TenantField, field.Key)
+	assert.Equal(t, "team-a", field.Match.GetKeyword())
+}
+
+func TestTenantFilter_Match(t *testing.T) {
+	filter := tenantFilter("team-a", map[string]string{"source": "handbook.pdf", "lang": "en"})
+
+	require.Len(t, filter.Must, 3)
+	assert.Equal(t, TenantField, filter.Must[0].GetField().Key)
+	assert.Equal(t, "lang", filter.Must[1].GetField().Key)
+	assert.Equal(t, "en", filter.Must[1].GetField().Match.GetKeyword())
+	assert.Equal(t, "source", filter.Must[2].GetField().Key)
+	assert.Equal(t, "handbook.pdf", filter.Must[2].GetField().Match.GetKeyword())
+}
+
+func TestUnassignedFilter(t *testing.T) {
+	filter := unassignedFilter()
+
+	require.Len(t, filter.Must, 1)
+	assert.Equal(t, TenantField, filter.Must[0].GetIsEmpty().GetKey())
+}
+
+func TestAssignTenant_RequiresTenant(t *testing.T) {
+	c := &Client{}
+
+	n, err := c.AssignTenant(context.Background(), "collection", "")
+	assert.ErrorIs(t, err, ErrTenantRequired)
+	assert.Zero(t, n)
+}
+
+func TestUpsert_RequiresTenant(t *testing.T) {
+	c := &Client{}
+
+	err := c.Upsert(context.Background(), "collection", "", []Document{{Content: "x"}})
+	assert.ErrorIs(t, err, ErrTenantRequired)
+}
+
+func TestHybridSearch_RequiresTenant(t *testing.T) {
+	c := &Client{}
+
+	results, err := c.HybridSearch(context.Background(), "collection", "", []float32{0.1}, nil, 5, SearchOptions{})
+	assert.ErrorIs(t, err, ErrTenantRequired)
+	assert.Nil(t, results)
+}
+
+func TestScroll_RequiresTenant(t *testing.T) {
+	c := &Client{}
+
+	results, err := c.Scroll(context.Background(), "collection", "", ScrollOptions{Limit: 5})
+	assert.ErrorIs(t, err, ErrTenantRequired)
+	assert.Nil(t, results)
+}
+
+func TestAnyFilter(t *testing.T) {
+	should := anyFilter([]map[string][]string{
+		{DocumentField: {"doc-1"}, ChunkIndexField: {"2", "4"}},
+		{SourceField: {"notes.md"}},
+	})
+
+	require.Len(t, should, 2)
+	first := should[0].GetFilter().Must
+	require.Len(t, first, 2)
+	assert.Equal(t, ChunkIndexField, first[0].GetField().Key)
+	assert.Equal(t, []string{"2", "4"}, first[0].GetField().Match.GetKeywords().Strings)
+	assert.Equal(t, DocumentField, first[1].GetField().Key)
+	assert.Equal(t, SourceField, should[1].GetFilter().Must[0].GetField().Key)
+}
+
+func TestNewSearchResult(t *testing.T) {
+	result := newSearchResult(
+		&pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: "id-1"}},
+		0.5,
+		map[string]*pb.Value{
+			"content":          pb.NewValueString("chunk text"),
+			TenantField:        pb.NewValueString("team-a"),
+			ChunkIndexField:    pb.NewValueString("3"),
+			ParentContentField: pb.NewValueString("parent text"),
+		},
+		nil,
+	)
+
+	assert.Equal(t, "id-1", result.ID)
+	assert.Equal(t, float32(0.5), result.Score)
+	assert.Equal(t, "chunk text", result.Content)
+	assert.Equal(t, "parent text", result.Parent)
+	assert.Equal(t, map[string]string{ChunkIndexField: "3"}, result.Payload)
+	assert.Nil(t, result.Vector)
+}
@@ -0,0 +1,250 @@
+package websearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxPageBytes caps how much of a page is read.
+const maxPageBytes = 2 << 20
+
+var (
+	// errPageBlocked is returned when a page redirects outside the policy.
+	errPageBlocked = errors.New("page blocked by web search policy")
+	// errAddressBlocked is returned when a page resolves to a loopback,
+	// private or link-local address outside the internal networks.
+	errAddressBlocked = errors.New("page address is not public")
+)
+
+// reservedPrefixes are special-purpose ranges that netip does not count
+// as private or local but that must not be fetched either.
+var reservedPrefixes = []netip.Prefix{
+	// "This network", which Linux routes to the local host
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// Carrier-grade NAT (RFC 6598)
+	netip.MustParsePrefix("100.64.0.0/10"),
+	// IETF protocol assignments
+	netip.MustParsePrefix("192.0.0.0/24"),
+	// Benchmarking (RFC 2544)
+	netip.MustParsePrefix("198.18.0.0/15"),
+	// Local-use NAT64 (RFC 8215), which translates to private networks
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// IPv6 ranges that embed an IPv4 address.
+var (
+	nat64Prefix     = netip.MustParsePrefix("64:ff9b::/96")
+	ipv4Compatible  = netip.MustParsePrefix("::/96")
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// Fetcher downloads result pages and extracts their readable text.
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher creates a Fetcher. allow, when set, is checked against every
+// redirect target so a permitted result can't lead to a blocked site.
+//
+// Result URLs come from the web, so pages are only fetched from public
+// addresses, checked after DNS resolution for every connection including
+// redirects. internal lists the private networks that may still be
+// fetched from, such as an intranet indexed by an internal search
+// backend. Proxies from the environment are not used, as they would hide
+// the address of the page.
+func NewFetcher(timeout time.Duration, allow func(url string) bool, internal []netip.Prefix) *Fetcher {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: dialControl(internal),
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Fetcher{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			if allow != nil && !allow(req.URL.String()) {
+				return errPageBlocked
+			}
+			return nil
+		},
+	}}
+}
+
+// ParseNetworks parses CIDR ranges for NewFetcher.
+func ParseNetworks(cidrs []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, len(cidrs))
+	for i, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", cidr, err)
+		}
+		networks[i] = prefix.Masked()
+	}
+	return networks, nil
+}
+
+// dialControl rejects connections to non-public addresses outside
+// internal. It runs after DNS resolution, so host names pointing at
+// internal addresses are caught too.
+func dialControl(internal []netip.Prefix) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return err
+		}
+		addr = addr.Unmap()
+		if publicAddr(addr) {
+			return nil
+		}
+		for _, prefix := range internal {
+			if prefix.Contains(addr) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", errAddressBlocked, addr)
+	}
+}
+
+// publicAddr reports whether addr is globally routable. IPv6 addresses
+// that embed an IPv4 address are judged by the IPv4 address they reach.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if v4, ok := embeddedIPv4(addr); ok {
+		return publicAddr(v4)
+	}
+	return true
+}
+
+// embeddedIPv4 returns the IPv4 address embedded in a NAT64,
+// IPv4-compatible or 6to4 IPv6 address.
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr), ipv4Compatible.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFourPrefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
+
+// Fetch returns the text content of an HTML or plain text page.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create page request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("page request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("page returned %s", resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body := io.LimitReader(resp.Body, maxPageBytes)
+	switch mediaType {
+	case "text/plain":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read page: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case "text/html", "application/xhtml+xml", "":
+		return htmlText(body)
+	default:
+		return "", fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// skippedElements hold no readable content.
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "nav": true, "header": true, "footer": true, "aside": true,
+	"form": true, "iframe": true, "head": true,
+}
+
+// blockElements end a line of text.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "section": true,
+	"article": true, "main": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "pre": true, "blockquote": true,
+	"table": true, "ul": true, "ol": true, "dd": true, "dt": true,
+}
+
+// htmlText extracts visible text from an HTML document, one paragraph per
+// line.
+func htmlText(r io.Reader) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && skippedElements[n.Data] {
+			return
+		}
+		if n.Type == html.TextNode {
+			if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+				if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteByte(' ')
+				}
+				b.WriteString(text)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] && b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	walk(doc)
+
+	return strings.TrimSpace(b.String()), nil
+}
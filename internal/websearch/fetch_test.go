@@ -0,0 +1,158 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopback lets tests fetch from httptest servers.
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+
+func TestHTMLText(t *testing.T) {
+	text, err := htmlText(strings.NewReader(`<html><head><title>T</title><script>var x = 1;</script></head>
+<body><nav>Home | About</nav>
+<h1>Install</h1><p>Run   the
+installer.</p><ul><li>Step one</li><li>Step <b>two</b></li></ul>
+<footer>Copyright</footer></body></html>`))
+	require.NoError(t, err)
+	assert.Equal(t, "Install\nRun the installer.\nStep one\nStep two", text)
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<p>Hello <em>web</em></p>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("  plain text \n"))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/blocked", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := NewFetcher(0, func(url string) bool { return !strings.Contains(url, "blocked") }, loopback)
+	ctx := context.Background()
+
+	text, err := f.Fetch(ctx, srv.URL+"/page")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello web", text)
+
+	text, err = f.Fetch(ctx, srv.URL+"/plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain text", text)
+
+	_, err = f.Fetch(ctx, srv.URL+"/image")
+	assert.ErrorContains(t, err, "unsupported content type")
+
+	_, err = f.Fetch(ctx, srv.URL+"/missing")
+	assert.Error(t, err)
+
+	_, err = f.Fetch(ctx, srv.URL+"/redirect")
+	assert.ErrorIs(t, err, errPageBlocked)
+}
+
+func TestFetcher_PrivateAddresses(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<p>Internal</p>`))
+	})
+	mux.HandleFunc("/metadata", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://10.0.0.1/admin", http.StatusFound)
+	})
+	mux.HandleFunc("/loopback", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://127.0.0.2/", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	// Loopback is blocked unless configured as internal
+	_, err := NewFetcher(0, nil, nil).Fetch(ctx, srv.URL+"/page")
+	assert.ErrorIs(t, err, errAddressBlocked)
+
+	f := NewFetcher(0, nil, loopback)
+	text, err := f.Fetch(ctx, srv.URL+"/page")
+	require.NoError(t, err)
+	assert.Equal(t, "Internal", text)
+
+	// Redirects are checked too
+	for _, path := range []string{"/metadata", "/private", "/loopback"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := f.Fetch(ctx, srv.URL+path)
+			assert.ErrorIs(t, err, errAddressBlocked)
+		})
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "100.64.0.1"},
+		{addr: "100.127.255.254"},
+		{addr: "0.0.0.0"},
+		{addr: "0.1.2.3"},
+		{addr: "192.0.0.8"},
+		{addr: "198.18.0.1"},
+		{addr: "198.19.255.254"},
+		{addr: "198.20.0.1", want: true},
+		{addr: "::1"},
+		{addr: "fd00::1"},
+		{addr: "fe80::1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:10.0.0.1"},
+		{addr: "::ffff:93.184.216.34", want: true},
+		{addr: "64:ff9b::127.0.0.1"},
+		{addr: "64:ff9b::a9fe:a9fe"},
+		{addr: "64:ff9b::93.184.216.34", want: true},
+		{addr: "64:ff9b:1::1"},
+		{addr: "::127.0.0.1"},
+		{addr: "::10.0.0.1"},
+		{addr: "2002:7f00:1::"},
+		{addr: "2002:c0a8:101::1"},
+		{addr: "2002:5db8:d822::1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, publicAddr(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks([]string{"10.0.0.0/8", " 192.168.1.7/24"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.0/24"),
+	}, networks)
+
+	_, err = ParseNetworks([]string{"intranet"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mfmezger/agentic_rag_go/internal/rerank"
+
+	"github.com/tmc/langchaingo/textsplitter"
+	"golang.org/x/sync/errgroup"
+)
+
+// Passage is a piece of a web page handed to the agent, with its source
+// preserved for citation.
+type Passage struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Text  string `json:"text"`
+}
+
+// GatherConfig configures a Gatherer.
+type GatherConfig struct {
+	// Results is the number of search results considered.
+	Results int
+	// FetchPages is the number of top results whose pages are fetched;
+	// the rest contribute their snippets only.
+	FetchPages int
+	// Passages is the number of passages returned.
+	Passages int
+	// ChunkSize and ChunkOverlap control how pages are split, in characters.
+	ChunkSize    int
+	ChunkOverlap int
+}
+
+// Gatherer turns a query into cited passages: it searches, drops results
+// outside the policy, fetches the top pages, splits them into chunks and
+// keeps the chunks that best match the query.
+type Gatherer struct {
+	searcher Searcher
+	fetcher  *Fetcher
+	policy   *Policy
+	splitter textsplitter.TextSplitter
+	scorer   rerank.Reranker
+	cfg      GatherConfig
+}
+
+// NewGatherer creates a Gatherer. fetcher may be nil to use snippets only.
+func NewGatherer(searcher Searcher, fetcher *Fetcher, policy *Policy, cfg GatherConfig) *Gatherer {
+	if cfg.Results <= 0 {
+		cfg.Results = 5
+	}
+	if cfg.Passages <= 0 {
+		cfg.Passages = 6
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 1000
+	}
+	return &Gatherer{
+		searcher: searcher,
+		fetcher:  fetcher,
+		policy:   policy,
+		splitter: textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(cfg.ChunkSize),
+			textsplitter.WithChunkOverlap(cfg.ChunkOverlap),
+		),
+		scorer: rerank.NewLexical(),
+		cfg:    cfg,
+	}
+}
+
+// Gather returns the passages most relevant to query.
+func (g *Gatherer) Gather(ctx context.Context, query string) ([]Passage, error) {
+	found, err := g.searcher.Search(ctx, query, g.cfg.Results*2)
+	if err != nil {
+		return nil, fmt.Errorf("web search failed: %w", err)
+	}
+
+	var results []Result
+	for _, r := range found {
+		if !g.policy.Allowed(r.URL) {
+			slog.DebugContext(ctx, "Skipped web result blocked by policy", "url", r.URL)
+			continue
+		}
+		results = append(results, r)
+		if len(results) == g.cfg.Results {
+			break
+		}
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	pages := make([]string, len(results))
+	if g.fetcher != nil {
+		eg, egctx := errgroup.WithContext(ctx)
+		for i := range min(g.cfg.FetchPages, len(results)) {
+			eg.Go(func() error {
+				text, err := g.fetcher.Fetch(egctx, results[i].URL)
+				if err != nil {
+					// The snippet still gives the agent something to cite
+					slog.DebugContext(ctx, "Failed to fetch web page", "url", results[i].URL, "error", err)
+					return nil
+				}
+				pages[i] = text
+				return nil
+			})
+		}
+		_ = eg.Wait()
+	}
+
+	var candidates []Passage
+	for i, r := range results {
+		if pages[i] == "" {
+			if r.Snippet != "" {
+				candidates = append(candidates, Passage{Title: r.Title, URL: r.URL, Text: r.Snippet})
+			}
+			continue
+		}
+		chunks, err := g.splitter.SplitText(pages[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to split page %s: %w", r.URL, err)
+		}
+		for _, chunk := range chunks {
+			candidates = append(candidates, Passage{Title: r.Title, URL: r.URL, Text: chunk})
+		}
+	}
+
+	texts := make([]string, len(candidates))
+	for i, c := range candidates {
+		texts[i] = c.Text
+	}
+	scores, err := g.scorer.Score(ctx, query, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to score passages: %w", err)
+	}
+
+	order := rerank.Order(scores)
+	passages := make([]Passage, 0, min(len(order), g.cfg.Passages))
+	for _, idx := range order[:min(len(order), g.cfg.Passages)] {
+		passages = append(passages, candidates[idx])
+	}
+	return passages, nil
+}
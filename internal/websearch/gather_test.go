@@ -0,0 +1,78 @@
+package websearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type searcherFunc func(query string, limit int) ([]Result, error)
+
+func (f searcherFunc) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	return f(query, limit)
+}
+
+func TestGatherer_Gather(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/guide":
+			w.Write([]byte(`<p>` + strings.Repeat("Unrelated filler text. ", 20) + `</p>
+<p>To rotate the signing key, run the rotate command and restart the service.</p>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	searcher := searcherFunc(func(query string, limit int) ([]Result, error) {
+		return []Result{
+			{Title: "Blocked", URL: "https://blocked.example/x", Snippet: "rotate signing key"},
+			{Title: "Guide", URL: srv.URL + "/guide", Snippet: "guide snippet"},
+			{Title: "Gone", URL: srv.URL + "/gone", Snippet: "Signing key rotation is covered in the guide."},
+			{Title: "Unfetched", URL: "https://unfetched.example", Snippet: "unrelated"},
+		}, nil
+	})
+	policy, err := NewPolicy(Config{BlockedDomains: []string{"blocked.example"}})
+	require.NoError(t, err)
+
+	g := NewGatherer(searcher, NewFetcher(0, policy.Allowed, loopback), policy, GatherConfig{
+		Results:    3,
+		FetchPages: 2,
+		Passages:   2,
+		ChunkSize:  120,
+	})
+
+	passages, err := g.Gather(context.Background(), "rotate signing key")
+	require.NoError(t, err)
+	require.Len(t, passages, 2)
+
+	assert.Equal(t, srv.URL+"/guide", passages[0].URL)
+	assert.Contains(t, passages[0].Text, "rotate the signing key")
+	// A page that failed to load falls back to its snippet
+	assert.Equal(t, srv.URL+"/gone", passages[1].URL)
+	assert.Equal(t, "Signing key rotation is covered in the guide.", passages[1].Text)
+
+	for _, p := range passages {
+		assert.NotContains(t, p.URL, "blocked.example")
+	}
+}
+
+func TestGatherer_NoResults(t *testing.T) {
+	policy, err := NewPolicy(Config{})
+	require.NoError(t, err)
+
+	g := NewGatherer(&Stub{}, nil, policy, GatherConfig{})
+	passages, err := g.Gather(context.Background(), "anything")
+	require.NoError(t, err)
+	assert.Empty(t, passages)
+
+	failing := searcherFunc(func(string, int) ([]Result, error) { return nil, errors.New("down") })
+	_, err = NewGatherer(failing, nil, policy, GatherConfig{}).Gather(context.Background(), "q")
+	assert.ErrorContains(t, err, "down")
+}
@@ -0,0 +1,165 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPConfig configures an HTTP JSON search endpoint.
+type HTTPConfig struct {
+	// URL of the search endpoint, e.g. http://searxng:8080/search.
+	URL string
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+	// QueryParam is the query string parameter holding the query.
+	QueryParam string
+	// Params are extra query string parameters sent with every search.
+	Params map[string]string
+	// ResultsField, TitleField, URLField and SnippetField locate the
+	// results in the JSON response. Dotted paths reach into nested objects.
+	ResultsField string
+	TitleField   string
+	URLField     string
+	SnippetField string
+	Timeout      time.Duration
+}
+
+// SearxNGConfig returns the settings for a SearxNG instance at baseURL.
+// The instance must have the json output format enabled.
+func SearxNGConfig(baseURL string) HTTPConfig {
+	return HTTPConfig{
+		URL:          strings.TrimSuffix(baseURL, "/") + "/search",
+		QueryParam:   "q",
+		Params:       map[string]string{"format": "json"},
+		ResultsField: "results",
+		TitleField:   "title",
+		URLField:     "url",
+		SnippetField: "content",
+	}
+}
+
+// HTTP searches a JSON HTTP endpoint such as SearxNG or an internal
+// search service.
+type HTTP struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+// NewHTTP creates an HTTP searcher. Unset field names default to the
+// SearxNG response layout.
+func NewHTTP(cfg HTTPConfig) (*HTTP, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("web search url is required")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid web search url: %w", err)
+	}
+
+	defaults := SearxNGConfig("")
+	if cfg.QueryParam == "" {
+		cfg.QueryParam = defaults.QueryParam
+	}
+	if cfg.ResultsField == "" {
+		cfg.ResultsField = defaults.ResultsField
+	}
+	if cfg.TitleField == "" {
+		cfg.TitleField = defaults.TitleField
+	}
+	if cfg.URLField == "" {
+		cfg.URLField = defaults.URLField
+	}
+	if cfg.SnippetField == "" {
+		cfg.SnippetField = defaults.SnippetField
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &HTTP{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Search implements Searcher.
+func (h *HTTP) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	u, err := url.Parse(h.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid web search url: %w", err)
+	}
+	q := u.Query()
+	for k, v := range h.cfg.Params {
+		q.Set(k, v)
+	}
+	q.Set(h.cfg.QueryParam, query)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web search request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if h.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.cfg.APIKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("web search request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read web search response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("web search returned %s", resp.Status)
+	}
+
+	var body any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to parse web search response: %w", err)
+	}
+	items, ok := lookup(body, h.cfg.ResultsField).([]any)
+	if !ok {
+		return nil, fmt.Errorf("web search response has no %q array", h.cfg.ResultsField)
+	}
+
+	var results []Result
+	for _, item := range items {
+		r := Result{
+			Title:   stringField(item, h.cfg.TitleField),
+			URL:     stringField(item, h.cfg.URLField),
+			Snippet: stringField(item, h.cfg.SnippetField),
+		}
+		if r.URL == "" {
+			continue
+		}
+		results = append(results, r)
+		if limit > 0 && len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// lookup follows a dotted path through nested JSON objects.
+func lookup(v any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+func stringField(v any, path string) string {
+	s, _ := lookup(v, path).(string)
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,107 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_SearchSearxNG(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "go generics", r.URL.Query().Get("q"))
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		w.Write([]byte(`{"results": [
+			{"title": "Generics", "url": "https://go.dev/doc/tutorial/generics", "content": "Tutorial"},
+			{"title": "No URL"},
+			{"title": "Spec", "url": "https://go.dev/ref/spec", "content": "Type parameters"},
+			{"title": "Blog", "url": "https://go.dev/blog/intro-generics"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewHTTP(SearxNGConfig(srv.URL + "/"))
+	require.NoError(t, err)
+
+	results, err := s.Search(context.Background(), "go generics", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Result{
+		{Title: "Generics", URL: "https://go.dev/doc/tutorial/generics", Snippet: "Tutorial"},
+		{Title: "Spec", URL: "https://go.dev/ref/spec", Snippet: "Type parameters"},
+	}, results)
+}
+
+func TestHTTP_SearchGenericJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "vpn setup", r.URL.Query().Get("query"))
+		assert.Equal(t, "wiki", r.URL.Query().Get("index"))
+		w.Write([]byte(`{"data": {"hits": [{"doc": {"name": "VPN", "link": "https://wiki.corp/vpn"}, "summary": "How to connect"}]}}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewHTTP(HTTPConfig{
+		URL:          srv.URL,
+		APIKey:       "secret",
+		QueryParam:   "query",
+		Params:       map[string]string{"index": "wiki"},
+		ResultsField: "data.hits",
+		TitleField:   "doc.name",
+		URLField:     "doc.link",
+		SnippetField: "summary",
+	})
+	require.NoError(t, err)
+
+	results, err := s.Search(context.Background(), "vpn setup", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{Title: "VPN", URL: "https://wiki.corp/vpn", Snippet: "How to connect"}}, results)
+}
+
+func TestHTTP_SearchErrors(t *testing.T) {
+	_, err := NewHTTP(HTTPConfig{})
+	assert.Error(t, err)
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "server error", status: http.StatusBadGateway, body: `{}`},
+		{name: "invalid json", status: http.StatusOK, body: `<html>`},
+		{name: "missing results", status: http.StatusOK, body: `{"answers": []}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			s, err := NewHTTP(HTTPConfig{URL: srv.URL})
+			require.NoError(t, err)
+			_, err = s.Search(context.Background(), "q", 5)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStub_Search(t *testing.T) {
+	empty := &Stub{}
+	results, err := empty.Search(context.Background(), "anything", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	s := &Stub{Results: []Result{
+		{Title: "Weather", URL: "https://a.example"},
+		{Title: "Go release notes", URL: "https://b.example"},
+		{Title: "Cooking", URL: "https://c.example", Snippet: "a go-to recipe"},
+	}}
+	results, err = s.Search(context.Background(), "Go", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://b.example", "https://c.example"}, []string{results[0].URL, results[1].URL})
+}
@@ -0,0 +1,209 @@
+// Package websearch provides the chat agent's web search: the policy for
+// when it may search, pluggable search providers that turn result pages
+// into cited passages, and a record of the web sources an answer drew on.
+package websearch
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Mode controls when web search is offered to the agent.
+type Mode string
+
+const (
+	// ModeDisabled never offers web search.
+	ModeDisabled Mode = "disabled"
+	// ModeFallback offers web search only when retrieval found nothing
+	// good enough.
+	ModeFallback Mode = "fallback"
+	// ModeAlways always offers web search.
+	ModeAlways Mode = "always"
+)
+
+// ErrOverrideDenied is returned when a caller asks for a less restrictive
+// mode than policy allows without an override role.
+var ErrOverrideDenied = errors.New("not allowed to relax web search policy")
+
+// ParseMode parses a mode name.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ModeDisabled, ModeFallback, ModeAlways:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown web search mode %q", s)
+	}
+}
+
+// rank orders modes from most to least restrictive.
+func (m Mode) rank() int {
+	switch m {
+	case ModeAlways:
+		return 2
+	case ModeFallback:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Config holds web search policy settings.
+type Config struct {
+	Mode string
+	// MinScore is the retrieval relevance, the best cosine similarity
+	// between query and chunk, below which fallback mode offers web search.
+	MinScore float64
+	// AllowedDomains, when set, limits web sources to these domains and
+	// their subdomains. BlockedDomains always wins.
+	AllowedDomains []string
+	BlockedDomains []string
+	// TenantModes overrides Mode per tenant.
+	TenantModes map[string]string
+	// OverrideRoles may relax the mode per request; anyone may tighten it.
+	OverrideRoles []string
+}
+
+// Policy is a validated web search policy.
+type Policy struct {
+	mode          Mode
+	minScore      float64
+	allowed       []string
+	blocked       []string
+	tenantModes   map[string]Mode
+	overrideRoles []string
+}
+
+// NewPolicy validates cfg. An empty mode means always, matching the
+// agent's behaviour before web search was configurable.
+func NewPolicy(cfg Config) (*Policy, error) {
+	p := &Policy{
+		mode:          ModeAlways,
+		minScore:      cfg.MinScore,
+		allowed:       normalizeDomains(cfg.AllowedDomains),
+		blocked:       normalizeDomains(cfg.BlockedDomains),
+		tenantModes:   make(map[string]Mode, len(cfg.TenantModes)),
+		overrideRoles: cfg.OverrideRoles,
+	}
+
+	if cfg.Mode != "" {
+		mode, err := ParseMode(cfg.Mode)
+		if err != nil {
+			return nil, err
+		}
+		p.mode = mode
+	}
+	for tenant, s := range cfg.TenantModes {
+		mode, err := ParseMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", tenant, err)
+		}
+		p.tenantModes[tenant] = mode
+	}
+
+	return p, nil
+}
+
+// ModeFor resolves the mode for a request. requested may be empty to use
+// the tenant's mode. Relaxing the mode requires one of the override roles
+// or privileged set; tightening it is always allowed.
+func (p *Policy) ModeFor(tenant string, requested Mode, roles []string, privileged bool) (Mode, error) {
+	mode := p.mode
+	if m, ok := p.tenantModes[tenant]; ok {
+		mode = m
+	}
+	if requested == "" || requested == mode {
+		return mode, nil
+	}
+	if requested.rank() < mode.rank() || privileged || p.canOverride(roles) {
+		return requested, nil
+	}
+	return "", ErrOverrideDenied
+}
+
+func (p *Policy) canOverride(roles []string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.overrideRoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Offer reports whether web search is offered in mode, given the
+// relevance of the retrieval: the best cosine similarity between query and
+// chunk (found is false when nothing was retrieved).
+func (p *Policy) Offer(mode Mode, bestScore float64, found bool) bool {
+	switch mode {
+	case ModeAlways:
+		return true
+	case ModeFallback:
+		return !found || bestScore < p.minScore
+	default:
+		return false
+	}
+}
+
+// Allowed reports whether a source is permitted. source may be a URL or a
+// bare host name.
+func (p *Policy) Allowed(source string) bool {
+	host := hostOf(source)
+	if host == "" {
+		return len(p.allowed) == 0
+	}
+	if matchDomain(host, p.blocked) {
+		return false
+	}
+	return len(p.allowed) == 0 || matchDomain(host, p.allowed)
+}
+
+// Instruction describes the domain restrictions for the agent, or returns
+// an empty string when there are none.
+func (p *Policy) Instruction() string {
+	var b strings.Builder
+	if len(p.allowed) > 0 {
+		fmt.Fprintf(&b, "Only use web results from these domains: %s.\n", strings.Join(p.allowed, ", "))
+	}
+	if len(p.blocked) > 0 {
+		fmt.Fprintf(&b, "Never use web results from these domains: %s.\n", strings.Join(p.blocked, ", "))
+	}
+	return b.String()
+}
+
+// hostOf extracts the lowercased host from a URL or bare host name.
+func hostOf(source string) string {
+	source = strings.TrimSpace(strings.ToLower(source))
+	if strings.Contains(source, "://") {
+		u, err := url.Parse(source)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	if strings.ContainsAny(source, " /") || !strings.Contains(source, ".") {
+		return ""
+	}
+	return strings.TrimPrefix(source, "www.")
+}
+
+// matchDomain reports whether host equals or is a subdomain of one of domains.
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomains(domains []string) []string {
+	out := make([]string, 0, len(domains))
+	for _, d := range domains {
+		if h := hostOf(d); h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package websearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode(" Fallback ")
+	require.NoError(t, err)
+	assert.Equal(t, ModeFallback, mode)
+
+	_, err = ParseMode("sometimes")
+	assert.Error(t, err)
+}
+
+func TestNewPolicy(t *testing.T) {
+	p, err := NewPolicy(Config{})
+	require.NoError(t, err)
+	mode, err := p.ModeFor("team-a", "", nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, ModeAlways, mode, "empty mode keeps web search on")
+
+	_, err = NewPolicy(Config{Mode: "never"})
+	assert.Error(t, err)
+
+	_, err = NewPolicy(Config{TenantModes: map[string]string{"legal": "nope"}})
+	assert.ErrorContains(t, err, "legal")
+}
+
+func TestPolicy_ModeFor(t *testing.T) {
+	p, err := NewPolicy(Config{
+		Mode:          "fallback",
+		TenantModes:   map[string]string{"legal": "disabled"},
+		OverrideRoles: []string{"researcher"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		tenant     string
+		requested  Mode
+		roles      []string
+		privileged bool
+		want       Mode
+		wantErr    error
+	}{
+		{name: "default", tenant: "team-a", want: ModeFallback},
+		{name: "tenant mode", tenant: "legal", want: ModeDisabled},
+		{name: "tighten", tenant: "team-a", requested: ModeDisabled, want: ModeDisabled},
+		{name: "relax denied", tenant: "team-a", requested: ModeAlways, roles: []string{"viewer"}, wantErr: ErrOverrideDenied},
+		{name: "relax with role", tenant: "legal", requested: ModeAlways, roles: []string{"researcher"}, want: ModeAlways},
+		{name: "relax as admin", tenant: "legal", requested: ModeFallback, privileged: true, want: ModeFallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := p.ModeFor(tt.tenant, tt.requested, tt.roles, tt.privileged)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mode)
+		})
+	}
+}
+
+func TestPolicy_Offer(t *testing.T) {
+	p, err := NewPolicy(Config{MinScore: 0.5})
+	require.NoError(t, err)
+
+	assert.True(t, p.Offer(ModeAlways, 0.9, true))
+	assert.False(t, p.Offer(ModeDisabled, 0, false))
+	assert.True(t, p.Offer(ModeFallback, 0, false), "nothing retrieved")
+	assert.True(t, p.Offer(ModeFallback, 0.3, true), "weak retrieval")
+	assert.False(t, p.Offer(ModeFallback, 0.7, true), "good retrieval")
+}
+
+func TestPolicy_Allowed(t *testing.T) {
+	p, err := NewPolicy(Config{
+		AllowedDomains: []string{"python.org", "https://www.go.dev/"},
+		BlockedDomains: []string{"wiki.python.org"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, p.Allowed("https://docs.python.org/3/"))
+	assert.True(t, p.Allowed("python.org"))
+	assert.True(t, p.Allowed("www.go.dev"))
+	assert.False(t, p.Allowed("https://wiki.python.org/moin"))
+	assert.False(t, p.Allowed("https://notpython.org"))
+	assert.False(t, p.Allowed("Some page title"), "unknown host fails an allowlist")
+
+	open, err := NewPolicy(Config{BlockedDomains: []string{"example.com"}})
+	require.NoError(t, err)
+	assert.True(t, open.Allowed("Some page title"))
+	assert.True(t, open.Allowed("https://go.dev"))
+	assert.False(t, open.Allowed("https://shop.example.com/x"))
+}
+
+func TestPolicy_Instruction(t *testing.T) {
+	p, err := NewPolicy(Config{})
+	require.NoError(t, err)
+	assert.Empty(t, p.Instruction())
+
+	p, err = NewPolicy(Config{AllowedDomains: []string{"go.dev"}, BlockedDomains: []string{"example.com"}})
+	require.NoError(t, err)
+	assert.Contains(t, p.Instruction(), "Only use web results from these domains: go.dev.")
+	assert.Contains(t, p.Instruction(), "Never use web results from these domains: example.com.")
+}
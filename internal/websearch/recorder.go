@@ -0,0 +1,75 @@
+package websearch
+
+import (
+	"context"
+	"sync"
+)
+
+// Source is a web page an answer drew on.
+type Source struct {
+	Title string
+	URL   string
+}
+
+// Recorder collects the web sources used during one chat run. It is safe
+// for concurrent use; a nil Recorder discards everything.
+type Recorder struct {
+	mu      sync.Mutex
+	used    bool
+	sources []Source
+	seen    map[string]bool
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context that carries r.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecorderFrom returns the recorder in ctx, or nil.
+func RecorderFrom(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Record marks web search as used and adds sources, skipping duplicate URLs.
+func (r *Recorder) Record(sources ...Source) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.used = true
+	if r.seen == nil {
+		r.seen = make(map[string]bool)
+	}
+	for _, s := range sources {
+		if s.URL == "" || r.seen[s.URL] {
+			continue
+		}
+		r.seen[s.URL] = true
+		r.sources = append(r.sources, s)
+	}
+}
+
+// Used reports whether web search contributed to the run.
+func (r *Recorder) Used() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.used
+}
+
+// Sources returns the recorded sources in the order they were first seen.
+func (r *Recorder) Sources() []Source {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Source(nil), r.sources...)
+}
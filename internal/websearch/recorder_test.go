@@ -0,0 +1,37 @@
+package websearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	r := &Recorder{}
+	ctx := WithRecorder(context.Background(), r)
+	assert.False(t, r.Used())
+
+	RecorderFrom(ctx).Record(
+		Source{Title: "a", URL: "https://a.example"},
+		Source{Title: "a again", URL: "https://a.example"},
+		Source{Title: "no url"},
+	)
+	RecorderFrom(ctx).Record(Source{Title: "b", URL: "https://b.example"})
+
+	assert.True(t, r.Used())
+	assert.Equal(t, []Source{
+		{Title: "a", URL: "https://a.example"},
+		{Title: "b", URL: "https://b.example"},
+	}, r.Sources())
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	r := RecorderFrom(context.Background())
+	assert.Nil(t, r)
+
+	// A nil recorder discards everything
+	r.Record(Source{URL: "https://a.example"})
+	assert.False(t, r.Used())
+	assert.Nil(t, r.Sources())
+}
@@ -0,0 +1,54 @@
+package websearch
+
+import (
+	"context"
+	"strings"
+)
+
+// Result is a single web search hit.
+type Result struct {
+	Title   string
+	URL     string
+	Snippet string
+}
+
+// Searcher queries a web search backend.
+type Searcher interface {
+	// Search returns up to limit results for query, best first.
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// Stub is an offline Searcher returning canned results. Results whose
+// title or snippet share a word with the query come first; with no
+// results configured every search comes back empty, which lets the agent
+// run without network access.
+type Stub struct {
+	Results []Result
+}
+
+// Search implements Searcher.
+func (s *Stub) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	var matched, rest []Result
+	for _, r := range s.Results {
+		text := strings.ToLower(r.Title + " " + r.Snippet)
+		hit := false
+		for _, t := range terms {
+			if strings.Contains(text, t) {
+				hit = true
+				break
+			}
+		}
+		if hit {
+			matched = append(matched, r)
+		} else {
+			rest = append(rest, r)
+		}
+	}
+
+	results := append(matched, rest...)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}